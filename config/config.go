@@ -333,6 +333,26 @@ type RPCConfig struct {
 	// Activate unsafe RPC commands like /dial_persistent_peers and /unsafe_flush_mempool
 	Unsafe bool `mapstructure:"unsafe"`
 
+	// A list of RPC methods not to serve. Calls to them fail as if the
+	// methods did not exist. Only honored by the inspect command.
+	DisabledRPCMethods []string `mapstructure:"disabled_rpc_methods"`
+
+	// The query operators allowed in the queries of tx_search and
+	// block_search, out of "=", "<", "<=", ">", ">=", "CONTAINS" and "EXISTS".
+	// Queries using other operators are rejected. All operators are allowed
+	// if empty. Only honored by the inspect command.
+	AllowedQueryOperators []string `mapstructure:"allowed_query_operators"`
+
+	// The response caches of the RPC routes, by route name. Routes without
+	// an enabled cache are not cached. Only honored by the inspect command.
+	RouteCaches map[string]RouteCacheConfig `mapstructure:"route_caches"`
+
+	// The format of the timestamps of the responses: "rfc3339" for RFC 3339
+	// strings, as by default, or "protobuf" for the objects of the
+	// google.protobuf.Timestamp messages, with seconds and nanos. Only
+	// honored by the inspect command.
+	TimestampFormat string `mapstructure:"timestamp_format"`
+
 	// Maximum number of simultaneous connections (including WebSocket).
 	// If you want to accept a larger number than the default, make sure
 	// you increase your OS limits.
@@ -473,9 +493,32 @@ func (cfg *RPCConfig) ValidateBasic() error {
 	if cfg.MaxHeaderBytes < 0 {
 		return cmterrors.ErrNegativeField{Field: "max_header_bytes"}
 	}
+	for route, cache := range cfg.RouteCaches {
+		if cache.Capacity < 0 {
+			return cmterrors.ErrNegativeField{Field: "route_caches." + route + ".capacity"}
+		}
+		if cache.TTL < 0 {
+			return cmterrors.ErrNegativeField{Field: "route_caches." + route + ".ttl"}
+		}
+	}
 	return nil
 }
 
+// RouteCacheConfig defines the response cache of an RPC route.
+type RouteCacheConfig struct {
+	// Whether the responses of the route are cached.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Maximum number of cached responses. The least recently used responses
+	// are evicted first.
+	// 0 - 1000 responses.
+	Capacity int `mapstructure:"capacity"`
+
+	// Maximum age of the cached responses.
+	// 0 - responses do not expire.
+	TTL time.Duration `mapstructure:"ttl"`
+}
+
 // IsCorsEnabled returns true if cross-origin resource sharing is enabled.
 func (cfg *RPCConfig) IsCorsEnabled() bool {
 	return len(cfg.CORSAllowedOrigins) != 0
@@ -83,6 +83,11 @@ func TestRPCConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	cfg.RouteCaches = map[string]config.RouteCacheConfig{"block": {Enabled: true, Capacity: -1}}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.RouteCaches = map[string]config.RouteCacheConfig{"block": {Enabled: true, TTL: -time.Second}}
+	assert.Error(t, cfg.ValidateBasic())
 }
 
 func TestP2PConfigValidateBasic(t *testing.T) {
@@ -171,6 +171,21 @@ cors_allowed_headers = [{{ range .RPC.CORSAllowedHeaders }}{{ printf "%q, " . }}
 # Activate unsafe RPC commands like /dial_seeds and /unsafe_flush_mempool
 unsafe = {{ .RPC.Unsafe }}
 
+# A list of RPC methods not to serve, as if they did not exist.
+# Only honored by the inspect command.
+disabled_rpc_methods = [{{ range .RPC.DisabledRPCMethods }}{{ printf "%q, " . }}{{end}}]
+
+# The query operators allowed in tx_search and block_search queries, out of
+# "=", "<", "<=", ">", ">=", "CONTAINS" and "EXISTS". All operators are
+# allowed if empty. Only honored by the inspect command.
+allowed_query_operators = [{{ range .RPC.AllowedQueryOperators }}{{ printf "%q, " . }}{{end}}]
+
+# The format of the timestamps of the responses: "rfc3339" for RFC 3339
+# strings, or "protobuf" for objects with the seconds and nanos of
+# google.protobuf.Timestamp. Defaults to "rfc3339" if empty. Only honored by
+# the inspect command.
+timestamp_format = "{{ .RPC.TimestampFormat }}"
+
 # Maximum number of simultaneous connections (including WebSocket).
 # If you want to accept a larger number than the default, make sure
 # you increase your OS limits.
@@ -246,6 +261,23 @@ tls_key_file = "{{ .RPC.TLSKeyFile }}"
 # pprof listen address (https://golang.org/pkg/net/http/pprof)
 pprof_laddr = "{{ .RPC.PprofListenAddress }}"
 
+# Response caches of the RPC routes, one table per route, e.g.:
+#
+# [rpc.route_caches.block]
+# enabled = true
+# capacity = 1000
+# ttl = "0s"
+#
+# Routes without an enabled cache are not cached. A capacity of 0 means 1000
+# responses, and a ttl of 0 means the responses do not expire. Only honored by
+# the inspect command.
+{{ range $route, $cache := .RPC.RouteCaches }}
+[rpc.route_caches.{{ $route }}]
+enabled = {{ $cache.Enabled }}
+capacity = {{ $cache.Capacity }}
+ttl = "{{ $cache.TTL }}"
+{{ end }}
+
 #######################################################
 ###           P2P Configuration Options             ###
 #######################################################
@@ -30,6 +30,25 @@ Inspector serves its RPC endpoints on the address configured in the RPC configur
 	ins := inspect.NewFromConfig(rpcConfig)
 	go ins.Run(ctx)
 
+The Inspector serves its routes with the same JSON-RPC server as the node, so
+errors carry the same codes: -32601 for unknown methods, -32602 for parameters
+that cannot be decoded and -32603 for failed requests. In addition, the routes
+taking a height report heights above the latest height with code -32002 and
+heights below the base of the block store, which were pruned, with code
+-32003. The data of both errors includes the latest height or the base.
+Stores which are temporarily unavailable are reported with code -32001,
+searches which time out with code -32004 and expired page cursors with code
+-32005. The codes are the same over HTTP and websocket connections.
+
+Clients of the routes returning lists, such as blockchain, tx_search and txs,
+may send the rpc.CompactMediaType in the Accept header of their requests to
+receive responses without the "jsonrpc" and "id" members of the JSON-RPC
+envelope. This is an extension of JSON-RPC; responses are unchanged for
+clients that do not request it. Similarly, clients of any route may send
+rpc.CBORMediaType to receive the response encoded in CBOR rather than JSON.
+Further encodings may be registered with the rpc.ResponseCodecs handler
+option; the encoding is selected by the Accept header of each request.
+
 The list of available RPC endpoints can then be viewed by navigating to
 http://127.0.0.1:26657/ in the web browser.
 */
@@ -0,0 +1,88 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cometbft/cometbft/config"
+	cmtstrings "github.com/cometbft/cometbft/libs/strings"
+)
+
+const (
+	// databaseFiles is a rough estimate of the number of files kept open by
+	// each of the block store, state store and indexer databases.
+	databaseFiles      = 100
+	inspectedDatabases = 3
+
+	// reservedFiles is the number of file descriptors reserved for the
+	// process besides the connections and the databases.
+	reservedFiles = 32
+)
+
+// errFileLimitUnsupported is returned on the platforms where the limit on the
+// number of open files cannot be read.
+var errFileLimitUnsupported = errors.New("file descriptor limit not supported on this platform")
+
+// ErrFileLimit is returned by Run when the process limit on the number of open
+// files is lower than the number the Inspector may need.
+type ErrFileLimit struct {
+	Limit    uint64
+	Required uint64
+}
+
+func (e ErrFileLimit) Error() string {
+	return fmt.Sprintf("open file limit %d is lower than the %d files the inspector may need; "+
+		"raise the limit or lower rpc.max_open_connections", e.Limit, e.Required)
+}
+
+// requiredFiles estimates the number of files the Inspector may open at
+// once when serving the configuration. It returns 0 if the number of
+// connections is unlimited.
+func requiredFiles(cfg *config.RPCConfig) uint64 {
+	if cfg.MaxOpenConnections <= 0 {
+		return 0
+	}
+	// Each listener accepts up to MaxOpenConnections connections.
+	listeners := len(cmtstrings.SplitAndTrimEmpty(cfg.ListenAddress, ",", " "))
+	return uint64(cfg.MaxOpenConnections*listeners + inspectedDatabases*databaseFiles + reservedFiles)
+}
+
+// checkFileLimit compares the limit on the number of open files of the process
+// with the number of files the Inspector may need, first raising the soft
+// limit toward the hard limit if ins.raiseFileLimit is set. A limit too low is
+// logged, or returned as an ErrFileLimit if ins.refuseLowFileLimit is set.
+func (ins *Inspector) checkFileLimit() error {
+	required := requiredFiles(ins.config)
+	if required == 0 {
+		return nil
+	}
+	soft, hard, err := fileLimit()
+	if errors.Is(err, errFileLimitUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if soft < required && ins.raiseFileLimit {
+		newSoft := required
+		if newSoft > hard {
+			newSoft = hard
+		}
+		if err := setFileLimit(newSoft); err != nil {
+			ins.logger.Error("Failed to raise the open file limit", "limit", newSoft, "err", err)
+		} else {
+			ins.logger.Info("Raised the open file limit", "from", soft, "to", newSoft)
+			soft = newSoft
+		}
+	}
+
+	if soft < required {
+		err := ErrFileLimit{Limit: soft, Required: required}
+		if ins.refuseLowFileLimit {
+			return err
+		}
+		ins.logger.Error("Open file limit too low", "err", err)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package inspect
+
+func fileLimit() (uint64, uint64, error) {
+	return 0, 0, errFileLimitUnsupported
+}
+
+func setFileLimit(uint64) error {
+	return errFileLimitUnsupported
+}
@@ -0,0 +1,25 @@
+//go:build linux || darwin
+
+package inspect
+
+import "syscall"
+
+// fileLimit returns the soft and hard limits on the number of open files of
+// the process.
+func fileLimit() (uint64, uint64, error) {
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		return 0, 0, err
+	}
+	return rlimit.Cur, rlimit.Max, nil
+}
+
+// setFileLimit sets the soft limit on the number of open files of the process.
+func setFileLimit(soft uint64) error {
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		return err
+	}
+	rlimit.Cur = soft
+	return syscall.Setrlimit(syscall.RLIMIT_NOFILE, &rlimit)
+}
@@ -2,9 +2,16 @@ package inspect
 
 import (
 	"context"
+	"crypto/x509"
 	"errors"
 	"net"
+	"net/http"
 	"os"
+	"time"
+
+	dbm "github.com/cometbft/cometbft-db"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/cometbft/cometbft/config"
 	"github.com/cometbft/cometbft/inspect/rpc"
@@ -23,6 +30,14 @@ import (
 
 var logger = log.NewTMLogger(log.NewSyncWriter(os.Stdout))
 
+// defaultRetryAfter is the delay after which clients are asked to retry
+// requests that failed because a data store was temporarily unavailable.
+const defaultRetryAfter = time.Second
+
+// readHeaderTimeout is the timeout for reading the request headers of the
+// Prometheus HTTP server.
+const readHeaderTimeout = 10 * time.Second
+
 // Inspector manages an RPC service that exports methods to debug a failed node.
 // After a node shuts down due to a consensus failure, it will no longer start
 // up its state cannot easily be inspected. An Inspector value provides a similar interface
@@ -40,6 +55,229 @@ type Inspector struct {
 	// the Inspector to safely close them on shutdown.
 	ss state.Store
 	bs state.BlockStore
+
+	routesOptions  []rpc.RoutesOption
+	handlerOptions []rpc.HandlerOption
+	serverOptions  []func(*rpc.Server)
+	maxBlockAge    time.Duration
+	refuseStale    bool
+
+	raiseFileLimit     bool
+	refuseLowFileLimit bool
+
+	instrumentation *config.InstrumentationConfig
+	metricsAdmin    bool
+
+	label            string
+	streamTxSearch   bool
+	streamBlockMetas bool
+
+	requireChainID bool
+	chainID        string
+	genesisChainID string
+}
+
+// Option sets an optional parameter on the Inspector.
+type Option func(*Inspector)
+
+// RoutesOptions sets optional parameters on the routes served by the Inspector.
+func RoutesOptions(options ...rpc.RoutesOption) Option {
+	return func(ins *Inspector) {
+		ins.routesOptions = append(ins.routesOptions, options...)
+	}
+}
+
+// HandlerOptions sets optional parameters on the handler serving the routes of
+// the Inspector, such as an authenticator.
+func HandlerOptions(options ...rpc.HandlerOption) Option {
+	return func(ins *Inspector) {
+		ins.handlerOptions = append(ins.handlerOptions, options...)
+	}
+}
+
+// MaxBlockAge sets the maximum age of the latest stored block. If the latest
+// block is older than maxAge, the Inspector logs an error on startup and the
+// status route reports the store as stale. A value of 0 disables the check.
+func MaxBlockAge(maxAge time.Duration) Option {
+	return func(ins *Inspector) {
+		ins.maxBlockAge = maxAge
+		ins.routesOptions = append(ins.routesOptions, rpc.MaxBlockAge(maxAge))
+	}
+}
+
+// EnableUI serves a minimal explorer UI under /ui/ on every RPC listener.
+func EnableUI() Option {
+	return func(ins *Inspector) {
+		ins.serverOptions = append(ins.serverOptions, func(srv *rpc.Server) {
+			srv.EnableUI = true
+		})
+	}
+}
+
+// EnableH2C serves HTTP/2 over cleartext connections on every RPC listener
+// that does not use TLS.
+func EnableH2C() Option {
+	return func(ins *Inspector) {
+		ins.serverOptions = append(ins.serverOptions, func(srv *rpc.Server) {
+			srv.EnableH2C = true
+		})
+	}
+}
+
+// ListenBacklog sets the length of the queue of pending connections of every
+// RPC listener, to absorb bursts of connections. See rpc.Server.ListenBacklog
+// for the platform limitations.
+func ListenBacklog(backlog int) Option {
+	return func(ins *Inspector) {
+		ins.serverOptions = append(ins.serverOptions, func(srv *rpc.Server) {
+			srv.ListenBacklog = backlog
+		})
+	}
+}
+
+// ReusePort sets SO_REUSEPORT on every TCP RPC listener, so that several
+// Inspector processes on the same host may serve the same address. See
+// rpc.Server.ReusePort for the platform limitations.
+func ReusePort() Option {
+	return func(ins *Inspector) {
+		ins.serverOptions = append(ins.serverOptions, func(srv *rpc.Server) {
+			srv.ReusePort = true
+		})
+	}
+}
+
+// MaxTLSHandshakes sets the maximum number of TLS handshakes run at once by
+// every HTTPS RPC listener. See rpc.Server.MaxTLSHandshakes for the default.
+func MaxTLSHandshakes(n int) Option {
+	return func(ins *Inspector) {
+		ins.serverOptions = append(ins.serverOptions, func(srv *rpc.Server) {
+			srv.MaxTLSHandshakes = n
+		})
+	}
+}
+
+// MutualTLS requires the clients of every HTTPS RPC listener to present a
+// certificate chaining to one of clientCAs and passing the checks of policy.
+// See rpc.ClientCertPolicy.
+func MutualTLS(clientCAs *x509.CertPool, policy rpc.ClientCertPolicy) Option {
+	return func(ins *Inspector) {
+		ins.serverOptions = append(ins.serverOptions, func(srv *rpc.Server) {
+			srv.ClientCAs = clientCAs
+			srv.ClientCertPolicy = policy
+		})
+	}
+}
+
+// RequireChainID rejects the requests which do not carry chainID in their
+// rpc.ChainIDHeader header, see rpc.RequireChainID. If chainID is empty, it
+// defaults to the chain ID of the genesis file with NewFromConfig, or else to
+// the chain ID of the state in the state store.
+func RequireChainID(chainID string) Option {
+	return func(ins *Inspector) {
+		ins.requireChainID = true
+		ins.chainID = chainID
+	}
+}
+
+// RefuseStale makes Run return an error instead of serving when the latest
+// stored block is older than the age set with MaxBlockAge.
+func RefuseStale() Option {
+	return func(ins *Inspector) {
+		ins.refuseStale = true
+	}
+}
+
+// RaiseFileLimit makes Run raise the soft limit on the number of open files of
+// the process, up to the hard limit, if it is lower than the number of files
+// the Inspector may need to serve the maximum number of connections.
+func RaiseFileLimit() Option {
+	return func(ins *Inspector) {
+		ins.raiseFileLimit = true
+	}
+}
+
+// RefuseLowFileLimit makes Run return an ErrFileLimit instead of serving when
+// the limit on the number of open files of the process is lower than the
+// number of files the Inspector may need to serve the maximum number of
+// connections.
+func RefuseLowFileLimit() Option {
+	return func(ins *Inspector) {
+		ins.refuseLowFileLimit = true
+	}
+}
+
+// Prometheus records the metrics of the Inspector under the namespace of cfg
+// and serves them to Prometheus collectors on the listen address of cfg.
+func Prometheus(cfg *config.InstrumentationConfig) Option {
+	return func(ins *Inspector) {
+		ins.instrumentation = cfg
+		MetricsSink(rpc.PrometheusSink{Namespace: cfg.Namespace})(ins)
+	}
+}
+
+// MetricsSink records the metrics of the Inspector, including those of the
+// requests served by its RPC listeners, in the metrics backend of sink. The
+// metrics are not served by the Inspector, unlike with Prometheus, which
+// records them in the PrometheusSink.
+func MetricsSink(sink rpc.MetricsSink) Option {
+	return func(ins *Inspector) {
+		metrics := rpc.SinkMetrics(sink)
+		ins.routesOptions = append(ins.routesOptions, rpc.WithMetrics(metrics))
+		ins.handlerOptions = append(ins.handlerOptions, rpc.HandlerMetrics(metrics))
+		ins.serverOptions = append(ins.serverOptions, func(srv *rpc.Server) {
+			srv.Metrics = metrics
+		})
+	}
+}
+
+// MetricsAdmin serves a snapshot of the metrics recorded with Prometheus on the
+// RPC listeners, under /admin/metrics, which also allows resetting the
+// counters between runs. See rpc.MetricsAdmin. The route is only served if the
+// requests are authenticated, see HandlerOptions and rpc.Authenticate.
+func MetricsAdmin() Option {
+	return func(ins *Inspector) {
+		ins.metricsAdmin = true
+	}
+}
+
+// Label identifies the Inspector when running several of them, for instance
+// with the chain ID of the inspected stores. The label is added to the log
+// lines of the Inspector and sent in the rpc.LabelHeader header of every RPC
+// response.
+func Label(label string) Option {
+	return func(ins *Inspector) {
+		ins.label = label
+		ins.serverOptions = append(ins.serverOptions, func(srv *rpc.Server) {
+			srv.Label = label
+		})
+	}
+}
+
+// StreamTxSearch serves the streaming variant of tx_search, on the transaction
+// indexer of the Inspector, under /tx_search_stream. See rpc.TxSearchStream.
+func StreamTxSearch() Option {
+	return func(ins *Inspector) {
+		ins.streamTxSearch = true
+	}
+}
+
+// SearchTimeout cancels the searches of tx_search, block_search and of the
+// streaming search route which last longer than timeout, independently of
+// the duration of the other routes. See rpc.IndexerTimeout and
+// rpc.StreamSearchTimeout.
+func SearchTimeout(timeout time.Duration) Option {
+	return func(ins *Inspector) {
+		ins.routesOptions = append(ins.routesOptions, rpc.IndexerTimeout(timeout))
+		ins.handlerOptions = append(ins.handlerOptions, rpc.StreamSearchTimeout(timeout))
+	}
+}
+
+// StreamBlockMetas serves the streaming variant of block_metas, on the block
+// store of the Inspector, under /block_metas_stream. See rpc.StreamBlockMetas.
+func StreamBlockMetas() Option {
+	return func(ins *Inspector) {
+		ins.streamBlockMetas = true
+	}
 }
 
 // New returns an Inspector that serves RPC on the specified BlockStore and StateStore.
@@ -54,21 +292,58 @@ func New(
 	ss state.Store,
 	txidx txindex.TxIndexer,
 	blkidx indexer.BlockIndexer,
+	options ...Option,
 ) *Inspector {
-	routes := rpc.Routes(*cfg, ss, bs, txidx, blkidx, logger)
-	eb := types.NewEventBus()
-	eb.SetLogger(logger.With("module", "events"))
-	return &Inspector{
-		routes: routes,
+	ins := &Inspector{
 		config: cfg,
 		logger: logger,
 		ss:     ss,
 		bs:     bs,
 	}
+	for _, option := range options {
+		option(ins)
+	}
+	if ins.label != "" {
+		ins.logger = ins.logger.With("label", ins.label)
+	}
+	if ins.metricsAdmin && ins.instrumentation != nil {
+		ins.handlerOptions = append(ins.handlerOptions, rpc.MetricsAdmin(prometheus.DefaultGatherer,
+			ins.instrumentation.Namespace+"_"+rpc.MetricsSubsystem+"_"))
+	}
+	if ins.streamTxSearch {
+		stream := &rpc.TxSearchStream{}
+		ins.routesOptions = append(ins.routesOptions, rpc.WithTxSearchStream(stream))
+		ins.handlerOptions = append(ins.handlerOptions, rpc.StreamTxSearch(stream))
+	}
+	if ins.streamBlockMetas {
+		ins.handlerOptions = append(ins.handlerOptions, rpc.StreamBlockMetas(bs))
+	}
+	if rawCommits, ok := bs.(rpc.RawCommitStore); ok {
+		ins.handlerOptions = append(ins.handlerOptions, rpc.RawCommits(rawCommits))
+	}
+	if ins.requireChainID {
+		chainID := ins.chainID
+		if chainID == "" {
+			chainID = ins.genesisChainID
+		}
+		if chainID == "" {
+			if st, err := ss.Load(); err == nil {
+				chainID = st.ChainID
+			}
+		}
+		if chainID == "" {
+			ins.logger.Error("Rejecting all requests: the chain ID to require is unknown")
+		}
+		ins.handlerOptions = append(ins.handlerOptions, rpc.RequireChainID(chainID))
+	}
+	ins.routes = rpc.Routes(*cfg, ss, bs, txidx, blkidx, ins.logger, ins.routesOptions...)
+	eb := types.NewEventBus()
+	eb.SetLogger(ins.logger.With("module", "events"))
+	return ins
 }
 
 // NewFromConfig constructs an Inspector using the values defined in the passed in config.
-func NewFromConfig(cfg *config.Config) (*Inspector, error) {
+func NewFromConfig(cfg *config.Config, options ...Option) (*Inspector, error) {
 	bsDB, err := config.DefaultDBProvider(&config.DBContext{ID: "blockstore", Config: cfg})
 	if err != nil {
 		return nil, err
@@ -86,8 +361,21 @@ func NewFromConfig(cfg *config.Config) (*Inspector, error) {
 	if err != nil {
 		return nil, err
 	}
-	ss := state.NewStore(sDB, state.StoreOptions{})
-	return New(cfg.RPC, bs, ss, txidx, blkidx), nil
+	ss := state.NewStore(sDB, state.StoreOptions{
+		DiscardABCIResponses: cfg.Storage.DiscardABCIResponses,
+	})
+	// Report the transient errors of the configured database backend as
+	// temporarily unavailable, unless overridden by the passed in options.
+	defaults := []Option{RoutesOptions(rpc.StoreUnavailable(
+		rpc.StoreUnavailableFuncForBackend(dbm.BackendType(cfg.DBBackend)), defaultRetryAfter))}
+	if cfg.Instrumentation.IsPrometheusEnabled() {
+		defaults = append(defaults, Prometheus(cfg.Instrumentation))
+	}
+	defaults = append(defaults, RoutesOptions(rpc.Genesis(genDoc)), func(ins *Inspector) {
+		ins.genesisChainID = genDoc.ChainID
+	})
+	options = append(defaults, options...)
+	return New(cfg.RPC, bs, ss, txidx, blkidx, options...), nil
 }
 
 // Run starts the Inspector servers and blocks until the servers shut down. The passed
@@ -96,13 +384,74 @@ func (ins *Inspector) Run(ctx context.Context) error {
 	defer ins.bs.Close()
 	defer ins.ss.Close()
 
-	return startRPCServers(ctx, ins.config, ins.logger, ins.routes)
+	if err := rpc.ValidateDisabledMethods(ins.config.DisabledRPCMethods); err != nil {
+		return err
+	}
+	if err := rpc.ValidateQueryOperators(ins.config.AllowedQueryOperators); err != nil {
+		return err
+	}
+	if err := rpc.ValidateRouteCaches(ins.config.RouteCaches); err != nil {
+		return err
+	}
+	if err := rpc.ValidateCORSOrigins(ins.config.CORSAllowedOrigins); err != nil {
+		return err
+	}
+	if err := rpc.ValidateTimestampFormat(ins.config.TimestampFormat); err != nil {
+		return err
+	}
+
+	if err := rpc.CheckBlockAge(ins.bs, ins.maxBlockAge); err != nil {
+		if ins.refuseStale {
+			return err
+		}
+		ins.logger.Error("Inspecting a stale block store", "err", err)
+	}
+
+	if err := ins.checkFileLimit(); err != nil {
+		return err
+	}
+
+	if ins.instrumentation != nil {
+		srv := startPrometheusServer(ins.instrumentation, ins.logger)
+		defer srv.Close()
+	}
+
+	return startRPCServers(ctx, ins.config, ins.logger, ins.routes, ins.handlerOptions, ins.serverOptions...)
 }
 
-func startRPCServers(ctx context.Context, cfg *config.RPCConfig, logger log.Logger, routes rpccore.RoutesMap) error {
+// startPrometheusServer starts a Prometheus HTTP server, listening for metrics
+// collectors on the listen address of cfg.
+func startPrometheusServer(cfg *config.InstrumentationConfig, logger log.Logger) *http.Server {
+	srv := &http.Server{
+		Addr: cfg.PrometheusListenAddr,
+		Handler: promhttp.InstrumentMetricHandler(
+			prometheus.DefaultRegisterer, promhttp.HandlerFor(
+				prometheus.DefaultGatherer,
+				promhttp.HandlerOpts{MaxRequestsInFlight: cfg.MaxOpenConnections},
+			),
+		),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	go func() {
+		logger.Info("Prometheus HTTP server starting", "address", cfg.PrometheusListenAddr)
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			logger.Error("Prometheus HTTP server ListenAndServe", "err", err)
+		}
+	}()
+	return srv
+}
+
+func startRPCServers(
+	ctx context.Context,
+	cfg *config.RPCConfig,
+	logger log.Logger,
+	routes rpccore.RoutesMap,
+	handlerOptions []rpc.HandlerOption,
+	serverOptions ...func(*rpc.Server),
+) error {
 	g, tctx := errgroup.WithContext(ctx)
 	listenAddrs := cmtstrings.SplitAndTrimEmpty(cfg.ListenAddress, ",", " ")
-	rh := rpc.Handler(cfg, routes, logger)
+	rh := rpc.Handler(cfg, routes, logger, handlerOptions...)
 	for _, listenerAddr := range listenAddrs {
 		server := rpc.Server{
 			Logger:  logger,
@@ -110,6 +459,9 @@ func startRPCServers(ctx context.Context, cfg *config.RPCConfig, logger log.Logg
 			Handler: rh,
 			Addr:    listenerAddr,
 		}
+		for _, option := range serverOptions {
+			option(&server)
+		}
 		if cfg.IsTLSEnabled() {
 			keyFile := cfg.KeyFile()
 			certFile := cfg.CertFile()
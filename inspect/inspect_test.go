@@ -3,7 +3,9 @@ package inspect_test
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
 	"os"
 	"strings"
 	"sync"
@@ -17,9 +19,11 @@ import (
 	abcitypes "github.com/cometbft/cometbft/abci/types"
 	"github.com/cometbft/cometbft/config"
 	"github.com/cometbft/cometbft/inspect"
+	inspectrpc "github.com/cometbft/cometbft/inspect/rpc"
 	"github.com/cometbft/cometbft/internal/test"
 	"github.com/cometbft/cometbft/libs/pubsub/query"
 	httpclient "github.com/cometbft/cometbft/rpc/client/http"
+	jsonrpcclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
 	indexermocks "github.com/cometbft/cometbft/state/indexer/mocks"
 	statemocks "github.com/cometbft/cometbft/state/mocks"
 	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
@@ -572,6 +576,149 @@ func TestBlockSearch(t *testing.T) {
 	stateStoreMock.AssertExpectations(t)
 }
 
+func TestStatus(t *testing.T) {
+	testHeight := int64(10)
+	testBlockTime := time.Now().Add(-time.Hour)
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("Close").Return(nil)
+
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Close").Return(nil)
+	blockStoreMock.On("Height").Return(testHeight)
+	blockStoreMock.On("LoadBaseMeta").Return(&types.BlockMeta{
+		Header: types.Header{Height: 1},
+	})
+	blockStoreMock.On("LoadBlockMeta", testHeight).Return(&types.BlockMeta{
+		Header: types.Header{Height: testHeight, Time: testBlockTime},
+	})
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	blkIdxMock := &indexermocks.BlockIndexer{}
+	rpcConfig := config.TestRPCConfig()
+	d := inspect.New(rpcConfig, blockStoreMock, stateStoreMock, txIndexerMock, blkIdxMock,
+		inspect.MaxBlockAge(time.Minute))
+
+	stop := startInspector(t, d, rpcConfig.ListenAddress)
+	cli, err := jsonrpcclient.New(rpcConfig.ListenAddress)
+	require.NoError(t, err)
+	res := new(inspectrpc.ResultStatus)
+	_, err = cli.Call(context.Background(), "status", map[string]interface{}{}, res)
+	require.NoError(t, err)
+	require.Equal(t, testHeight, res.SyncInfo.LatestBlockHeight)
+	require.Equal(t, int64(1), res.SyncInfo.EarliestBlockHeight)
+	require.True(t, res.Stale)
+	stop()
+
+	blockStoreMock.AssertExpectations(t)
+	stateStoreMock.AssertExpectations(t)
+}
+
+func TestInspectRunRefuseStale(t *testing.T) {
+	testHeight := int64(10)
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("Close").Return(nil)
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Close").Return(nil)
+	blockStoreMock.On("Height").Return(testHeight)
+	blockStoreMock.On("LoadBlockMeta", testHeight).Return(&types.BlockMeta{
+		Header: types.Header{Height: testHeight, Time: time.Now().Add(-time.Hour)},
+	})
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	blkIdxMock := &indexermocks.BlockIndexer{}
+	rpcConfig := config.TestRPCConfig()
+	d := inspect.New(rpcConfig, blockStoreMock, stateStoreMock, txIndexerMock, blkIdxMock,
+		inspect.MaxBlockAge(time.Minute), inspect.RefuseStale())
+
+	err := d.Run(context.Background())
+	require.ErrorAs(t, err, &inspectrpc.ErrStaleBlockStore{})
+
+	blockStoreMock.AssertExpectations(t)
+	stateStoreMock.AssertExpectations(t)
+}
+
+func TestInspectRunRefuseLowFileLimit(t *testing.T) {
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("Close").Return(nil)
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Close").Return(nil)
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	blkIdxMock := &indexermocks.BlockIndexer{}
+	rpcConfig := config.TestRPCConfig()
+	rpcConfig.MaxOpenConnections = 1 << 40
+	d := inspect.New(rpcConfig, blockStoreMock, stateStoreMock, txIndexerMock, blkIdxMock,
+		inspect.RefuseLowFileLimit())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := d.Run(ctx)
+	if err == nil {
+		t.Skip("open file limit is unlimited or unsupported on this platform")
+	}
+	require.ErrorAs(t, err, &inspect.ErrFileLimit{})
+}
+
+func TestUI(t *testing.T) {
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("Close").Return(nil)
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Close").Return(nil)
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	blkIdxMock := &indexermocks.BlockIndexer{}
+	rpcConfig := config.TestRPCConfig()
+	d := inspect.New(rpcConfig, blockStoreMock, stateStoreMock, txIndexerMock, blkIdxMock, inspect.EnableUI())
+
+	stop := startInspector(t, d, rpcConfig.ListenAddress)
+	defer stop()
+	url := strings.Replace(rpcConfig.ListenAddress, "tcp://", "http://", 1)
+	res, err := http.Get(url + "/ui/")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Contains(t, string(body), "CometBFT Inspector")
+}
+
+func TestLabel(t *testing.T) {
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("Close").Return(nil)
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Close").Return(nil)
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	blkIdxMock := &indexermocks.BlockIndexer{}
+	rpcConfig := config.TestRPCConfig()
+	d := inspect.New(rpcConfig, blockStoreMock, stateStoreMock, txIndexerMock, blkIdxMock, inspect.Label("test-chain"))
+
+	stop := startInspector(t, d, rpcConfig.ListenAddress)
+	defer stop()
+	url := strings.Replace(rpcConfig.ListenAddress, "tcp://", "http://", 1)
+	// Use a new transport, so as not to reuse connections to the servers of
+	// other tests.
+	client := &http.Client{Transport: &http.Transport{}}
+	res, err := client.Get(url + "/health")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "test-chain", res.Header.Get(inspectrpc.LabelHeader))
+}
+
+// startInspector runs ins in the background and waits until it accepts
+// connections on addr. The returned function stops the Inspector and waits
+// for Run to return.
+func startInspector(t *testing.T, ins *inspect.Inspector, addr string) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		require.NoError(t, ins.Run(ctx))
+	}()
+	requireConnect(t, addr, 20)
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
 func requireConnect(t testing.TB, addr string, retries int) {
 	parts := strings.SplitN(addr, "://", 2)
 	if len(parts) != 2 {
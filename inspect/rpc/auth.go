@@ -0,0 +1,222 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/rpc/jsonrpc/server"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// Principal identifies the client of an authenticated request.
+type Principal struct {
+	Name string
+}
+
+// Authenticator authenticates the requests to the Inspector routes.
+// Authenticate returns the principal making the request, or an error if the
+// request could not be authenticated, in which case it is rejected with a 401
+// status.
+//
+// An Authenticator may also implement a Challenge() string method, returning
+// the value of the WWW-Authenticate header of the rejected responses.
+type Authenticator interface {
+	Authenticate(r *http.Request) (Principal, error)
+}
+
+// Authenticate requires every request to be authenticated by a before being
+// served. The principal of a request can be retrieved from its context with
+// PrincipalFromContext.
+func Authenticate(a Authenticator) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.authenticator = a
+	}
+}
+
+var (
+	errMissingCredentials = errors.New("missing credentials")
+	errInvalidCredentials = errors.New("invalid credentials")
+)
+
+type principalKey struct{}
+
+// PrincipalFromContext returns the principal of the authenticated request
+// served with ctx, if any.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// authHandler rejects the requests that a does not authenticate.
+func authHandler(a Authenticator, h http.Handler, logger log.Logger) http.Handler {
+	challenger, _ := a.(interface{ Challenge() string })
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := a.Authenticate(r)
+		if err != nil {
+			if challenger != nil {
+				w.Header().Set("WWW-Authenticate", challenger.Challenge())
+			}
+			res := rpctypes.RPCInvalidRequestError(nil, err)
+			if wErr := server.WriteRPCResponseHTTPError(w, http.StatusUnauthorized, res); wErr != nil {
+				logger.Error("failed to write response", "err", wErr)
+			}
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+	})
+}
+
+// BasicAuth authenticates requests with HTTP basic authentication.
+type BasicAuth struct {
+	// Realm is reported to clients in the WWW-Authenticate header.
+	Realm string
+	// Users maps the user names to their passwords.
+	Users map[string]string
+}
+
+var _ Authenticator = BasicAuth{}
+
+// Authenticate implements Authenticator. The principal is named after the
+// user.
+func (a BasicAuth) Authenticate(r *http.Request) (Principal, error) {
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return Principal{}, errMissingCredentials
+	}
+	expected, ok := a.Users[user]
+	if !ok || subtle.ConstantTimeCompare([]byte(password), []byte(expected)) != 1 {
+		return Principal{}, errInvalidCredentials
+	}
+	return Principal{Name: user}, nil
+}
+
+// Challenge returns the basic authentication challenge for the realm.
+func (a BasicAuth) Challenge() string {
+	return `Basic realm="` + a.Realm + `"`
+}
+
+// BearerAuth authenticates requests with bearer tokens.
+type BearerAuth struct {
+	// Tokens maps the accepted tokens to the names of their principals.
+	Tokens map[string]string
+}
+
+var _ Authenticator = BearerAuth{}
+
+// Authenticate implements Authenticator.
+func (a BearerAuth) Authenticate(r *http.Request) (Principal, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return Principal{}, errMissingCredentials
+	}
+	for t, name := range a.Tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+			return Principal{Name: name}, nil
+		}
+	}
+	return Principal{}, errInvalidCredentials
+}
+
+// Challenge returns the bearer authentication challenge.
+func (BearerAuth) Challenge() string {
+	return "Bearer"
+}
+
+// SignatureHeader is the request header carrying the signature of the
+// requests authenticated with HMACAuth.
+const SignatureHeader = "X-Inspect-Signature"
+
+const (
+	// defaultMaxSignedBodyBytes is the default maximum size of the bodies of
+	// the requests authenticated with HMACAuth.
+	defaultMaxSignedBodyBytes = 1000000
+	// defaultMaxSignatureSkew is the default maximum difference between the
+	// timestamps of the requests authenticated with HMACAuth and the clock of
+	// the server.
+	defaultMaxSignatureSkew = 5 * time.Minute
+)
+
+var errStaleSignature = errors.New("stale signature")
+
+// HMACAuth authenticates requests signed with a shared secret.
+//
+// The signature of a request, sent in the SignatureHeader header, is the hex
+// encoded HMAC-SHA256 of the method, the path and query, the value of the
+// TimestampHeader header and the body of the request, each followed by a
+// newline. Requests whose timestamp is more than MaxSkew away from the clock
+// of the server are rejected, which bounds the window in which a request may
+// be replayed.
+type HMACAuth struct {
+	// Secrets maps the names of the principals to their secrets. Several
+	// secrets may be accepted for a principal while rotating them.
+	Secrets map[string][][]byte
+	// MaxSkew is the maximum difference between the timestamp of a request
+	// and the clock of the server, 5 minutes if 0.
+	MaxSkew time.Duration
+	// MaxBodyBytes is the maximum size of the signed bodies, 1000000 bytes if
+	// 0. Larger requests are rejected.
+	MaxBodyBytes int64
+}
+
+var _ Authenticator = HMACAuth{}
+
+// Authenticate implements Authenticator. The body of r is restored so that it
+// can be read again by the handler serving r.
+func (a HMACAuth) Authenticate(r *http.Request) (Principal, error) {
+	signature, err := hex.DecodeString(r.Header.Get(SignatureHeader))
+	if err != nil || len(signature) == 0 {
+		return Principal{}, errMissingCredentials
+	}
+	maxSkew := a.MaxSkew
+	if maxSkew == 0 {
+		maxSkew = defaultMaxSignatureSkew
+	}
+	timestamp := r.Header.Get(TimestampHeader)
+	if err := checkTimestamp(timestamp, maxSkew, time.Now()); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", errStaleSignature, err)
+	}
+
+	maxBodyBytes := a.MaxBodyBytes
+	if maxBodyBytes == 0 {
+		maxBodyBytes = defaultMaxSignedBodyBytes
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return Principal{}, err
+	}
+	if int64(len(body)) > maxBodyBytes {
+		return Principal{}, fmt.Errorf("signed body larger than %d bytes", maxBodyBytes)
+	}
+
+	for name, secrets := range a.Secrets {
+		for _, secret := range secrets {
+			if hmac.Equal(signature, SignRequest(secret, r.Method, r.URL.RequestURI(), timestamp, body)) {
+				return Principal{Name: name}, nil
+			}
+		}
+	}
+	return Principal{}, errInvalidCredentials
+}
+
+// SignRequest returns the signature of a request for HMACAuth, to be sent hex
+// encoded in the SignatureHeader header.
+func SignRequest(secret []byte, method, requestURI, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	for _, part := range [][]byte{[]byte(method), []byte(requestURI), []byte(timestamp), body} {
+		mac.Write(part)         //nolint: errcheck
+		mac.Write([]byte{'\n'}) //nolint: errcheck
+	}
+	return mac.Sum(nil)
+}
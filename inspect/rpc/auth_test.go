@@ -0,0 +1,116 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/rpc/core"
+)
+
+func TestAuthenticate(t *testing.T) {
+	var principal Principal
+	h := authHandler(BasicAuth{Realm: "inspect", Users: map[string]string{"alice": "secret"}},
+		http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			principal, _ = PrincipalFromContext(r.Context())
+		}), log.NewNopLogger())
+
+	serve := func(user, password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/status", nil)
+		if user != "" {
+			req.SetBasicAuth(user, password)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("", "")
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Equal(t, `Basic realm="inspect"`, rec.Header().Get("WWW-Authenticate"))
+	require.Equal(t, http.StatusUnauthorized, serve("alice", "wrong").Code)
+	require.Equal(t, http.StatusUnauthorized, serve("bob", "secret").Code)
+
+	require.Equal(t, http.StatusOK, serve("alice", "secret").Code)
+	require.Equal(t, Principal{Name: "alice"}, principal)
+}
+
+func TestBearerAuth(t *testing.T) {
+	a := BearerAuth{Tokens: map[string]string{"t0k3n": "dashboard"}}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	_, err := a.Authenticate(req)
+	require.ErrorIs(t, err, errMissingCredentials)
+
+	req.Header.Set("Authorization", "Bearer other")
+	_, err = a.Authenticate(req)
+	require.ErrorIs(t, err, errInvalidCredentials)
+
+	req.Header.Set("Authorization", "bearer t0k3n")
+	p, err := a.Authenticate(req)
+	require.NoError(t, err)
+	require.Equal(t, Principal{Name: "dashboard"}, p)
+
+	// The handler rejects unauthenticated requests before dispatching them.
+	h := Handler(config.TestRPCConfig(), core.RoutesMap{}, log.NewNopLogger(), Authenticate(a))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+}
+
+func TestHMACAuth(t *testing.T) {
+	a := HMACAuth{
+		Secrets: map[string][][]byte{"relayer": {[]byte("old"), []byte("new")}},
+		MaxSkew: time.Minute,
+	}
+	body := `{"jsonrpc":"2.0","id":1,"method":"status"}`
+	newRequest := func(secret string, timestamp time.Time) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/?x=1", strings.NewReader(body))
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		req.Header.Set(TimestampHeader, ts)
+		req.Header.Set(SignatureHeader,
+			hex.EncodeToString(SignRequest([]byte(secret), http.MethodPost, "/?x=1", ts, []byte(body))))
+		return req
+	}
+
+	// Both secrets are accepted while rotating them.
+	for _, secret := range []string{"old", "new"} {
+		req := newRequest(secret, time.Now())
+		p, err := a.Authenticate(req)
+		require.NoError(t, err)
+		require.Equal(t, Principal{Name: "relayer"}, p)
+		b, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.Equal(t, body, string(b))
+	}
+
+	_, err := a.Authenticate(newRequest("other", time.Now()))
+	require.ErrorIs(t, err, errInvalidCredentials)
+	_, err = a.Authenticate(newRequest("new", time.Now().Add(-2*time.Minute)))
+	require.ErrorIs(t, err, errStaleSignature)
+	_, err = a.Authenticate(httptest.NewRequest(http.MethodPost, "/", nil))
+	require.ErrorIs(t, err, errMissingCredentials)
+
+	// Without a maximum skew, the default of 5 minutes applies.
+	a.MaxSkew = 0
+	_, err = a.Authenticate(newRequest("new", time.Now().Add(-2*time.Minute)))
+	require.NoError(t, err)
+	_, err = a.Authenticate(newRequest("new", time.Now().Add(-10*time.Minute)))
+	require.ErrorIs(t, err, errStaleSignature)
+	a.MaxSkew = time.Minute
+
+	// The body is covered by the signature.
+	req := newRequest("new", time.Now())
+	req.Body = io.NopCloser(strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"state"}`))
+	_, err = a.Authenticate(req)
+	require.ErrorIs(t, err, errInvalidCredentials)
+}
@@ -0,0 +1,145 @@
+package rpc
+
+import (
+	"bytes"
+	"fmt"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+// ResultBlockWithCommit is the result of the block_with_commit route.
+type ResultBlockWithCommit struct {
+	BlockID types.BlockID `json:"block_id"`
+	Block   *types.Block  `json:"block"`
+	// Commit is the commit for the block, which is null if the block is the
+	// latest one and its commit is not stored.
+	Commit *types.Commit `json:"commit"`
+	// Canonical is false if Commit is the commit seen by the node for the
+	// latest block, rather than the one included in the next block.
+	Canonical bool `json:"canonical"`
+}
+
+// BlockWithCommit returns the block at the given height, or the latest block
+// if no height is given, together with its commit, as the block and commit
+// routes would.
+func (env *environment) BlockWithCommit(ctx *rpctypes.Context, heightPtr *int64) (*ResultBlockWithCommit, error) {
+	resultBlock, err := env.Block(ctx, heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	res := &ResultBlockWithCommit{BlockID: resultBlock.BlockID, Block: resultBlock.Block}
+	if res.Block == nil {
+		return res, nil
+	}
+
+	res.Commit, res.Canonical = env.loadCommit(res.Block.Height)
+	return res, nil
+}
+
+// loadCommit loads the commit for the block at height. The commit for the
+// latest block is only included in the next block, so the commit seen by the
+// node is returned instead, if stored, and canonical is false.
+func (env *environment) loadCommit(height int64) (commit *types.Commit, canonical bool) {
+	if height == env.BlockStore.Height() {
+		return env.BlockStore.LoadSeenCommit(height), false
+	}
+	return env.BlockStore.LoadBlockCommit(height), true
+}
+
+// ResultHeaderCommitProof is the result of the header_commit_proof route.
+//
+// A client verifies that Header is part of the chain by checking that
+// Header.Hash() is the hash of Commit.BlockID, that Commit.Hash() is the
+// LastCommitHash of NextHeader and that Commit.BlockID is its LastBlockID, and
+// then the signatures of Commit against the validators at the height of
+// Header.
+type ResultHeaderCommitProof struct {
+	Header *types.Header `json:"header"`
+	// NextHeader and Commit are null if Header is the latest header, whose
+	// commit is only included in the next block.
+	NextHeader *types.Header `json:"next_header"`
+	Commit     *types.Commit `json:"commit"`
+	// Linked reports whether the checks of the header, next header and
+	// commit hashes succeeded on the stored data.
+	Linked bool `json:"linked"`
+}
+
+// HeaderCommitProof returns the header at the given height, or the latest
+// header if no height is given, together with the header of the next block
+// and the commit for the header included in it.
+func (env *environment) HeaderCommitProof(ctx *rpctypes.Context, heightPtr *int64) (*ResultHeaderCommitProof, error) {
+	resultHeader, err := env.Header(ctx, heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	res := &ResultHeaderCommitProof{Header: resultHeader.Header}
+	if res.Header == nil || res.Header.Height >= env.BlockStore.Height() {
+		return res, nil
+	}
+
+	nextMeta := env.BlockStore.LoadBlockMeta(res.Header.Height + 1)
+	if nextMeta == nil {
+		return res, nil
+	}
+	res.NextHeader = &nextMeta.Header
+	res.Commit = env.BlockStore.LoadBlockCommit(res.Header.Height)
+	if res.Commit != nil {
+		res.Linked = bytes.Equal(res.Commit.BlockID.Hash, res.Header.Hash()) &&
+			bytes.Equal(res.Commit.Hash(), res.NextHeader.LastCommitHash) &&
+			res.Commit.BlockID.Equals(res.NextHeader.LastBlockID)
+	}
+	return res, nil
+}
+
+// ResultHeaderVerificationBundle is the result of the
+// header_verification_bundle route, holding what a light client needs to
+// verify a single header: the commit for the header, and the validator set
+// at its height which signed it. The hash of the next validator set is
+// Header.NextValidatorsHash.
+type ResultHeaderVerificationBundle struct {
+	Header     *types.Header       `json:"header"`
+	Validators *types.ValidatorSet `json:"validators"`
+	Commit     *types.Commit       `json:"commit"`
+	// Canonical is false if Commit is the seen commit of the latest block,
+	// which is null if it is not stored.
+	Canonical bool `json:"canonical"`
+}
+
+// HeaderVerificationBundle returns the header at the given height, or the
+// latest header if no height is given, along with the validator set at its
+// height and the commit for it.
+//
+// The validator set and the commit are only usable in full, so they are not
+// truncated to the maximum number of signatures set with MaxCommitSignatures:
+// larger sets are refused, and the client is directed to the paginated
+// validators route instead.
+func (env *environment) HeaderVerificationBundle(
+	_ *rpctypes.Context,
+	heightPtr *int64,
+) (*ResultHeaderVerificationBundle, error) {
+	height, err := env.getHeight(env.BlockStore.Height(), heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	blockMeta := env.BlockStore.LoadBlockMeta(height)
+	if blockMeta == nil {
+		return nil, fmt.Errorf("block at height %d not found", height)
+	}
+	vals, err := env.StateStore.LoadValidators(height)
+	if err != nil {
+		return nil, err
+	}
+	if env.maxCommitSignatures != nil && vals.Size() > *env.maxCommitSignatures {
+		return nil, fmt.Errorf("validator set at height %d has %d validators, more than the maximum of %d; "+
+			"use the validators and commit routes", height, vals.Size(), *env.maxCommitSignatures)
+	}
+
+	commit, canonical := env.loadCommit(height)
+	return &ResultHeaderVerificationBundle{
+		Header:     &blockMeta.Header,
+		Validators: vals,
+		Commit:     commit,
+		Canonical:  canonical,
+	}, nil
+}
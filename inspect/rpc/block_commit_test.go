@@ -0,0 +1,106 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestBlockWithCommit(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(2))
+	for height := int64(1); height <= 2; height++ {
+		blockStoreMock.On("LoadBlock", height).Return(&types.Block{Header: types.Header{Height: height}})
+		blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{
+			BlockID: types.BlockID{Hash: []byte{byte(height)}},
+		})
+	}
+	blockStoreMock.On("LoadBlockCommit", int64(1)).Return(&types.Commit{Height: 1, Round: 1})
+	blockStoreMock.On("LoadSeenCommit", int64(2)).Return(nil)
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{})
+
+	height := int64(1)
+	res, err := env.BlockWithCommit(nil, &height)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), res.Block.Height)
+	require.EqualValues(t, []byte{1}, res.BlockID.Hash)
+	require.Equal(t, int32(1), res.Commit.Round)
+	require.True(t, res.Canonical)
+
+	// The seen commit for the latest block is not stored.
+	res, err = env.BlockWithCommit(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res.Block.Height)
+	require.Nil(t, res.Commit)
+	require.False(t, res.Canonical)
+}
+
+func TestHeaderCommitProof(t *testing.T) {
+	header := types.Header{Height: 1, ChainID: "test"}
+	commit := &types.Commit{Height: 1, BlockID: types.BlockID{Hash: header.Hash()}}
+	nextHeader := types.Header{Height: 2, ChainID: "test", LastBlockID: commit.BlockID, LastCommitHash: commit.Hash()}
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(2))
+	blockStoreMock.On("LoadBlockMeta", int64(1)).Return(&types.BlockMeta{Header: header})
+	blockStoreMock.On("LoadBlockMeta", int64(2)).Return(&types.BlockMeta{Header: nextHeader})
+	blockStoreMock.On("LoadBlockCommit", int64(1)).Return(commit)
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{})
+
+	height := int64(1)
+	res, err := env.HeaderCommitProof(nil, &height)
+	require.NoError(t, err)
+	require.Equal(t, &header, res.Header)
+	require.Equal(t, &nextHeader, res.NextHeader)
+	require.Equal(t, commit, res.Commit)
+	require.True(t, res.Linked)
+
+	// The commit for the latest header is not included in a block yet.
+	res, err = env.HeaderCommitProof(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, &nextHeader, res.Header)
+	require.Nil(t, res.NextHeader)
+	require.Nil(t, res.Commit)
+	require.False(t, res.Linked)
+}
+
+func TestHeaderVerificationBundle(t *testing.T) {
+	vals, _ := types.RandValidatorSet(3, 10)
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(2))
+	for height := int64(1); height <= 2; height++ {
+		blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{
+			Header: types.Header{Height: height, ValidatorsHash: vals.Hash()},
+		})
+	}
+	blockStoreMock.On("LoadBlockCommit", int64(1)).Return(&types.Commit{Height: 1})
+	blockStoreMock.On("LoadSeenCommit", int64(2)).Return(&types.Commit{Height: 2})
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("LoadValidators", mock.Anything).Return(vals, nil)
+	env := newTestEnvironment(blockStoreMock, stateStoreMock)
+
+	height := int64(1)
+	res, err := env.HeaderVerificationBundle(nil, &height)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), res.Header.Height)
+	require.Equal(t, res.Header.ValidatorsHash.Bytes(), res.Validators.Hash())
+	require.Equal(t, int64(1), res.Commit.Height)
+	require.True(t, res.Canonical)
+
+	res, err = env.HeaderVerificationBundle(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res.Header.Height)
+	require.Equal(t, int64(2), res.Commit.Height)
+	require.False(t, res.Canonical)
+
+	// Validator sets above the signature limit are refused, not truncated.
+	env = newTestEnvironment(blockStoreMock, stateStoreMock, MaxCommitSignatures(2))
+	_, err = env.HeaderVerificationBundle(nil, &height)
+	require.ErrorContains(t, err, "has 3 validators, more than the maximum of 2")
+}
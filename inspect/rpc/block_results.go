@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// MaxBlockEvents sets the maximum number of events returned by the
+// block_results route, counting both the events of the block and those of its
+// transactions. The events beyond the maximum are dropped from the response,
+// which is flagged as truncated; they can be retrieved page by page with the
+// events route. By default, all events are returned.
+func MaxBlockEvents(count int) RoutesOption {
+	return func(env *environment) {
+		env.maxBlockEvents = &count
+	}
+}
+
+// ResultBlockResults is the result of the block_results route. It extends the
+// result of the block_results route of the node, whose fields it repeats.
+type ResultBlockResults struct {
+	Height                int64                     `json:"height"`
+	TxsResults            []*abci.ExecTxResult      `json:"txs_results"`
+	FinalizeBlockEvents   []abci.Event              `json:"finalize_block_events"`
+	ValidatorUpdates      []abci.ValidatorUpdate    `json:"validator_updates"`
+	ConsensusParamUpdates *cmtproto.ConsensusParams `json:"consensus_param_updates"`
+	AppHash               []byte                    `json:"app_hash"`
+	// EventsTruncated is true if events were dropped to honor the maximum set
+	// with MaxBlockEvents, in which case EventCount is the number of events
+	// of the block and its transactions. The first events are kept: those of
+	// the block, then those of the transactions, in order.
+	EventsTruncated bool `json:"events_truncated,omitempty"`
+	EventCount      int  `json:"event_count,omitempty"`
+}
+
+// blockResults serves the block_results route: it returns the results of the
+// block at the given height, as returned by the node, with their events
+// truncated to the maximum set with MaxBlockEvents.
+func (env *environment) blockResults(ctx *rpctypes.Context, heightPtr *int64) (*ResultBlockResults, error) {
+	results, err := env.BlockResults(ctx, heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	res := &ResultBlockResults{
+		Height:                results.Height,
+		TxsResults:            results.TxsResults,
+		FinalizeBlockEvents:   results.FinalizeBlockEvents,
+		ValidatorUpdates:      results.ValidatorUpdates,
+		ConsensusParamUpdates: results.ConsensusParamUpdates,
+		AppHash:               results.AppHash,
+	}
+	if env.maxBlockEvents == nil {
+		return res, nil
+	}
+
+	count := len(res.FinalizeBlockEvents)
+	for _, txResult := range res.TxsResults {
+		if txResult != nil {
+			count += len(txResult.Events)
+		}
+	}
+	remaining := *env.maxBlockEvents
+	if count <= remaining {
+		return res, nil
+	}
+
+	res.EventsTruncated = true
+	res.EventCount = count
+	truncate := func(events []abci.Event) []abci.Event {
+		n := min(len(events), remaining)
+		remaining -= n
+		return events[:n:n]
+	}
+	res.FinalizeBlockEvents = truncate(res.FinalizeBlockEvents)
+	// The results are copied rather than truncated in place, since they may
+	// be shared with other callers.
+	res.TxsResults = make([]*abci.ExecTxResult, len(results.TxsResults))
+	for i, txResult := range results.TxsResults {
+		if txResult == nil {
+			continue
+		}
+		truncated := *txResult
+		truncated.Events = truncate(txResult.Events)
+		res.TxsResults[i] = &truncated
+	}
+	return res, nil
+}
@@ -0,0 +1,45 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+)
+
+func TestMaxBlockEvents(t *testing.T) {
+	event := abcitypes.Event{Type: "transfer"}
+	results := &abcitypes.ResponseFinalizeBlock{
+		Events: []abcitypes.Event{event},
+		TxResults: []*abcitypes.ExecTxResult{
+			{Code: 1, Events: []abcitypes.Event{event, event}},
+			nil,
+			{Events: []abcitypes.Event{event}},
+		},
+	}
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(2))
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("LoadFinalizeBlockResponse", int64(2)).Return(results, nil)
+
+	res, err := newTestEnvironment(blockStoreMock, stateStoreMock, MaxBlockEvents(4)).blockResults(nil, nil)
+	require.NoError(t, err)
+	require.False(t, res.EventsTruncated)
+	require.Len(t, res.TxsResults[2].Events, 1)
+
+	res, err = newTestEnvironment(blockStoreMock, stateStoreMock, MaxBlockEvents(2)).blockResults(nil, nil)
+	require.NoError(t, err)
+	require.True(t, res.EventsTruncated)
+	require.Equal(t, 4, res.EventCount)
+	require.Len(t, res.FinalizeBlockEvents, 1)
+	require.Len(t, res.TxsResults, 3)
+	require.Equal(t, uint32(1), res.TxsResults[0].Code)
+	require.Len(t, res.TxsResults[0].Events, 1)
+	require.Nil(t, res.TxsResults[1])
+	require.Empty(t, res.TxsResults[2].Events)
+	// The stored results are left untouched.
+	require.Len(t, results.TxResults[0].Events, 2)
+}
@@ -0,0 +1,95 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+const (
+	// defaultPerPage and maxPerPage are the default and maximum page sizes
+	// of the paginated routes, as in the node's routes.
+	defaultPerPage = 30
+	maxPerPage     = 100
+)
+
+// BlockTx is a transaction of a block, as returned by the block_txs route.
+type BlockTx struct {
+	Index uint32         `json:"index"`
+	Hash  bytes.HexBytes `json:"hash"`
+	Tx    types.Tx       `json:"tx"`
+}
+
+// ResultBlockTxs is the result of the block_txs route.
+type ResultBlockTxs struct {
+	Height     int64     `json:"height"`
+	Txs        []BlockTx `json:"txs"`
+	TotalCount int       `json:"total_count"`
+}
+
+// BlockTxs returns the transactions of the block at the given height, or of
+// the latest block if no height is given, in the order of the block, without
+// the rest of the block or their results.
+//
+// All the transactions are returned unless a page or a number of transactions
+// per page is given, in which case they are paginated as in tx_search.
+func (env *environment) BlockTxs(
+	_ *rpctypes.Context,
+	heightPtr *int64,
+	pagePtr, perPagePtr *int,
+) (*ResultBlockTxs, error) {
+	height, err := env.getHeight(env.BlockStore.Height(), heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	block := env.BlockStore.LoadBlock(height)
+	if block == nil {
+		return nil, fmt.Errorf("block at height %d not found", height)
+	}
+
+	txs := block.Data.Txs
+	start, end := 0, len(txs)
+	if pagePtr != nil || perPagePtr != nil {
+		perPage := validatePerPage(perPagePtr)
+		page, err := validatePage(pagePtr, perPage, len(txs))
+		if err != nil {
+			return nil, err
+		}
+		start = (page - 1) * perPage
+		end = min(start+perPage, len(txs))
+	}
+
+	res := &ResultBlockTxs{
+		Height:     height,
+		Txs:        make([]BlockTx, 0, end-start),
+		TotalCount: len(txs),
+	}
+	for i := start; i < end; i++ {
+		res.Txs = append(res.Txs, BlockTx{Index: uint32(i), Hash: txs[i].Hash(), Tx: txs[i]})
+	}
+	return res, nil
+}
+
+// validatePerPage returns the page size pointed to by perPagePtr, defaulting
+// to defaultPerPage and capped to maxPerPage.
+func validatePerPage(perPagePtr *int) int {
+	if perPagePtr == nil || *perPagePtr < 1 {
+		return defaultPerPage
+	}
+	return min(*perPagePtr, maxPerPage)
+}
+
+// validatePage returns the page pointed to by pagePtr, defaulting to the first
+// one, or an error if it is out of the pages of totalCount items.
+func validatePage(pagePtr *int, perPage, totalCount int) (int, error) {
+	if pagePtr == nil {
+		return 1, nil
+	}
+	pages := max((totalCount+perPage-1)/perPage, 1)
+	if page := *pagePtr; page <= 0 || page > pages {
+		return 0, fmt.Errorf("page should be within [1, %d] range, given %d", pages, page)
+	}
+	return *pagePtr, nil
+}
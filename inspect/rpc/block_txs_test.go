@@ -0,0 +1,46 @@
+package rpc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestBlockTxs(t *testing.T) {
+	txs := make(types.Txs, 45)
+	for i := range txs {
+		txs[i] = types.Tx(fmt.Sprintf("tx%d", i))
+	}
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(2))
+	blockStoreMock.On("LoadBlock", int64(2)).Return(&types.Block{Data: types.Data{Txs: txs}})
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{})
+
+	res, err := env.BlockTxs(nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res.Height)
+	require.Equal(t, 45, res.TotalCount)
+	require.Len(t, res.Txs, 45)
+	require.Equal(t, BlockTx{Index: 44, Hash: txs[44].Hash(), Tx: txs[44]}, res.Txs[44])
+
+	page, perPage := 2, 20
+	res, err = env.BlockTxs(nil, nil, &page, &perPage)
+	require.NoError(t, err)
+	require.Len(t, res.Txs, 20)
+	require.Equal(t, uint32(20), res.Txs[0].Index)
+
+	// The page size defaults to that of the node's routes.
+	res, err = env.BlockTxs(nil, nil, &page, nil)
+	require.NoError(t, err)
+	require.Len(t, res.Txs, 15)
+	require.Equal(t, uint32(30), res.Txs[0].Index)
+
+	page = 4
+	_, err = env.BlockTxs(nil, nil, &page, &perPage)
+	require.ErrorContains(t, err, "page should be within [1, 3] range")
+}
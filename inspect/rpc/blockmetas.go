@@ -0,0 +1,252 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cometbft/cometbft/libs/log"
+	cmtmath "github.com/cometbft/cometbft/libs/math"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	sm "github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+)
+
+// defaultMaxBlockMetasSpan is the default maximum number of heights returned
+// by the block_metas route.
+const defaultMaxBlockMetasSpan = 1000
+
+// MaxBlockMetasSpan sets the maximum number of heights returned by the
+// block_metas route. It is set apart from MaxRangeSpan since the metas can be
+// reduced to the fields needed by the clients.
+func MaxBlockMetasSpan(span int64) RoutesOption {
+	return func(env *environment) {
+		env.maxBlockMetasSpan = span
+	}
+}
+
+// ResultBlockMetas is the result of the block_metas route. The metas are
+// encoded as in the blockchain route, or reduced to the selected fields.
+type ResultBlockMetas struct {
+	LastHeight int64             `json:"last_height"`
+	BlockMetas []json.RawMessage `json:"block_metas"`
+	// NextCursor is the cursor returning the next page of the metas below
+	// the returned ones, or empty if there are none.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// BlockMetas returns the block metas for minHeight <= height <= maxHeight, in
+// ascending order. The range is resolved as in the blockchain route, but
+// limited to the span set by MaxBlockMetasSpan, and heights missing from the
+// block store are skipped.
+//
+// fields is an optional comma-separated list of the fields to return, among
+// the fields of the metas, such as num_txs, and of their headers, such as time
+// and proposer_address. Each meta is then reduced to an object holding the
+// selected fields, along with the height.
+//
+// If the range exceeds the span, the most recent heights are returned along
+// with a cursor, which passed as the cursor argument returns the next page
+// below them, in place of the other arguments. Cursors are signed as set by
+// CursorKey, and expire as set by CursorTTL.
+func (env *environment) BlockMetas(
+	_ *rpctypes.Context,
+	minHeight, maxHeight int64,
+	fields string,
+	cursor string,
+) (*ResultBlockMetas, error) {
+	if cursor != "" {
+		c, err := env.decodeCursor("block_metas", cursor)
+		if err != nil {
+			return nil, err
+		}
+		minHeight, maxHeight, fields = c.MinHeight, c.MaxHeight, c.Fields
+	}
+	selected, err := parseBlockMetaFields(fields)
+	if err != nil {
+		return nil, err
+	}
+	lowest := cmtmath.MaxInt64(cmtmath.MaxInt64(minHeight, 1), env.BlockStore.Base())
+	minHeight, maxHeight, err = env.heightRangeSpan(minHeight, maxHeight, env.maxBlockMetasSpan)
+	if err != nil {
+		return nil, err
+	}
+	var nextCursor string
+	if lowest < minHeight {
+		nextCursor, err = env.encodeCursor(pageCursor{
+			Route:     "block_metas",
+			MinHeight: lowest,
+			MaxHeight: minHeight - 1,
+			Fields:    fields,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	blockMetas := loadHeights(env, minHeight, maxHeight, func(height int64) (*types.BlockMeta, bool) {
+		blockMeta := env.BlockStore.LoadBlockMeta(height)
+		return blockMeta, blockMeta != nil
+	})
+	metas := make([]json.RawMessage, 0, len(blockMetas))
+	for _, blockMeta := range blockMetas {
+		meta, err := encodeBlockMeta(timestampMarshaler(env.Config.TimestampFormat), blockMeta, selected)
+		if err != nil {
+			return nil, err
+		}
+		metas = append(metas, meta)
+	}
+
+	return &ResultBlockMetas{
+		LastHeight: env.BlockStore.Height(),
+		BlockMetas: metas,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// blockMetaFields and headerFields are the names of the fields of the block
+// metas and of their headers in their JSON encoding.
+var (
+	blockMetaFields = jsonFieldNames(reflect.TypeOf(types.BlockMeta{}))
+	headerFields    = jsonFieldNames(reflect.TypeOf(types.Header{}))
+)
+
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		names[jsonFieldName(t.Field(i))] = true
+	}
+	return names
+}
+
+// parseBlockMetaFields returns the field names listed in fields, or nil if
+// fields is empty.
+func parseBlockMetaFields(fields string) ([]string, error) {
+	if strings.TrimSpace(fields) == "" {
+		return nil, nil
+	}
+	var names, unknown []string
+	for _, name := range strings.Split(fields, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+		case blockMetaFields[name] || headerFields[name]:
+			names = append(names, name)
+		default:
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown block meta fields: %s", strings.Join(unknown, ", "))
+	}
+	return names, nil
+}
+
+// encodeBlockMeta returns the JSON encoding of blockMeta with marshal, reduced
+// to the fields selected, if any, and the height.
+func encodeBlockMeta(marshal jsonMarshaler, blockMeta *types.BlockMeta, selected []string) (json.RawMessage, error) {
+	bz, err := marshal(blockMeta)
+	if err != nil || len(selected) == 0 {
+		return bz, err
+	}
+
+	var meta, header map[string]json.RawMessage
+	if err := json.Unmarshal(bz, &meta); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(meta["header"], &header); err != nil {
+		return nil, err
+	}
+	reduced := map[string]json.RawMessage{"height": header["height"]}
+	for _, name := range selected {
+		if blockMetaFields[name] {
+			reduced[name] = meta[name]
+		} else {
+			reduced[name] = header[name]
+		}
+	}
+	return json.Marshal(reduced)
+}
+
+// StreamBlockMetas serves the block metas stored in bs under
+// /block_metas_stream, without the span limit of the block_metas route.
+//
+// The route takes the minHeight, maxHeight and fields parameters of
+// block_metas in its URL, such as
+// /block_metas_stream?minHeight=1&fields=time,proposer_address, and responds
+// with NDJSONMediaType: every line is a block meta encoded as in the responses
+// of block_metas, in ascending order of height. A minHeight of 0 defaults to
+// the base of the store and a maxHeight of 0 to its latest height. Metas are
+// written as they are loaded and flushed periodically, and the route stops
+// when the client goes away. As with the streaming search route, a block meta
+// failing to encode ends the response with a last line holding the error.
+func StreamBlockMetas(bs sm.BlockStore) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.blockMetasStream = bs
+	}
+}
+
+// blockMetasStreamHandler serves the streaming block metas route on bs, with
+// the timestamps in timestampFormat. The errors writing the responses are
+// logged to logger.
+func blockMetasStreamHandler(bs sm.BlockStore, timestampFormat string, logger log.Logger) http.Handler {
+	marshal := timestampMarshaler(timestampFormat)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var heights [2]int64
+		for i, param := range []string{"minHeight", "maxHeight"} {
+			// Accept the heights quoted, as in the URI requests, or not.
+			value := strings.Trim(r.URL.Query().Get(param), `"`)
+			if value == "" {
+				continue
+			}
+			height, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || height < 0 {
+				http.Error(w, fmt.Sprintf("invalid %s %q", param, value), http.StatusBadRequest)
+				return
+			}
+			heights[i] = height
+		}
+		selected, err := parseBlockMetaFields(strings.Trim(r.URL.Query().Get("fields"), `"`))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		minHeight, maxHeight := cmtmath.MaxInt64(bs.Base(), heights[0]), bs.Height()
+		if heights[1] > 0 {
+			maxHeight = cmtmath.MinInt64(maxHeight, heights[1])
+		}
+
+		nw := newNDJSONWriter(w)
+		for height := minHeight; height <= maxHeight; height++ {
+			if r.Context().Err() != nil {
+				return
+			}
+			blockMeta := bs.LoadBlockMeta(height)
+			if blockMeta == nil {
+				continue
+			}
+			line, err := encodeBlockMeta(marshal, blockMeta, selected)
+			if err != nil {
+				nw.close(fmt.Errorf("encoding block meta %d: %w", height, err), logger)
+				return
+			}
+			if err := nw.writeLine(line); err != nil {
+				logger.Error("Failed to write streaming block metas response", "err", err)
+				return
+			}
+		}
+		nw.close(nil, logger)
+	})
+}
@@ -0,0 +1,81 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/cometbft/cometbft/libs/log"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func blockMetasStore(base, height int64) *statemocks.BlockStore {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(base)
+	blockStoreMock.On("Height").Return(height)
+	for h := base; h <= height; h++ {
+		blockStoreMock.On("LoadBlockMeta", h).Return(&types.BlockMeta{
+			NumTxs: int(h),
+			Header: types.Header{
+				Height:          h,
+				Time:            time.Unix(h, 0).UTC(),
+				ProposerAddress: []byte{byte(h)},
+			},
+		})
+	}
+	return blockStoreMock
+}
+
+func TestBlockMetas(t *testing.T) {
+	env := newTestEnvironment(blockMetasStore(1, 5), &statemocks.Store{}, MaxBlockMetasSpan(3))
+
+	res, err := env.BlockMetas(nil, 0, 0, "", "")
+	require.NoError(t, err)
+	require.Equal(t, int64(5), res.LastHeight)
+	require.Len(t, res.BlockMetas, 3)
+	// Without fields, the metas are encoded in full.
+	var meta types.BlockMeta
+	require.NoError(t, cmtjson.Unmarshal(res.BlockMetas[0], &meta))
+	require.Equal(t, int64(3), meta.Header.Height)
+	require.Equal(t, 3, meta.NumTxs)
+
+	res, err = env.BlockMetas(nil, 1, 2, "num_txs, proposer_address,time", "")
+	require.NoError(t, err)
+	require.Len(t, res.BlockMetas, 2)
+	require.JSONEq(t,
+		`{"height":"2","num_txs":"2","proposer_address":"02","time":"1970-01-01T00:00:02Z"}`,
+		string(res.BlockMetas[1]))
+
+	_, err = env.BlockMetas(nil, 0, 0, "time,size,bogus", "")
+	require.EqualError(t, err, "unknown block meta fields: bogus, size")
+}
+
+func TestBlockMetasStream(t *testing.T) {
+	h := blockMetasStreamHandler(blockMetasStore(2, 6), "", log.NewNopLogger())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/block_metas_stream?maxHeight=4&fields=num_txs", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, NDJSONMediaType, rec.Header().Get("Content-Type"))
+	require.Equal(t,
+		`{"height":"2","num_txs":"2"}`+"\n"+`{"height":"3","num_txs":"3"}`+"\n"+`{"height":"4","num_txs":"4"}`+"\n",
+		rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/block_metas_stream?minHeight=6", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, 1, strings.Count(rec.Body.String(), "\n"))
+	require.Contains(t, rec.Body.String(), `"block_id"`)
+
+	for _, query := range []string{"minHeight=-1", "maxHeight=x", "fields=bogus"} {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/block_metas_stream?"+query, nil))
+		require.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
@@ -0,0 +1,248 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/pubsub/query"
+	cmtsync "github.com/cometbft/cometbft/libs/sync"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/state/indexer"
+	blockidxnull "github.com/cometbft/cometbft/state/indexer/block/null"
+	"github.com/cometbft/cometbft/state/txindex"
+	"github.com/cometbft/cometbft/state/txindex/null"
+)
+
+// errIndexerUnavailable is returned by the indexers while their circuit
+// breaker is open.
+var errIndexerUnavailable = errors.New("indexer unavailable")
+
+// IndexerTimeout sets the maximum duration of the searches run on the
+// indexers, such as by the tx_search and block_search routes, independently
+// of the duration of the other routes. Searches taking longer are canceled
+// and fail with a search timeout error (code -32004). A value of 0 disables
+// the timeout. See StreamSearchTimeout for the streaming search route.
+//
+// The timeout is applied through the context of the search, so it is only
+// effective with indexers which honor it.
+func IndexerTimeout(timeout time.Duration) RoutesOption {
+	return func(env *environment) {
+		env.indexerTimeout = timeout
+	}
+}
+
+// IndexerCircuitBreaker stops calling the indexers for cooldown after
+// maxFailures consecutive calls to them failed, for instance because their
+// database is unreachable. While the breaker is open, the routes using the
+// indexers fail fast with a service unavailable error. Once cooldown has
+// passed, calls are let through again and the breaker closes on the first
+// success. A maxFailures of 0 disables the breaker.
+func IndexerCircuitBreaker(maxFailures int, cooldown time.Duration) RoutesOption {
+	return func(env *environment) {
+		env.breakerMaxFailures = maxFailures
+		env.breakerCooldown = cooldown
+	}
+}
+
+// WithMetrics sets the metrics of the Inspector routes.
+func WithMetrics(metrics *Metrics) RoutesOption {
+	return func(env *environment) {
+		env.metrics = metrics
+		env.metricsEnabled = true
+	}
+}
+
+// circuitBreaker counts the consecutive failures of the calls to a service and
+// rejects calls for a cooldown period once they reach a maximum.
+type circuitBreaker struct {
+	maxFailures int
+	cooldown    time.Duration
+	metrics     *Metrics
+
+	mtx       cmtsync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// allow returns the remaining time the breaker is open for, or 0 if calls are
+// allowed.
+func (cb *circuitBreaker) allow() time.Duration {
+	cb.mtx.Lock()
+	defer cb.mtx.Unlock()
+	if remaining := time.Until(cb.openUntil); remaining > 0 {
+		cb.metrics.IndexerRejectedCalls.Add(1)
+		return remaining
+	}
+	return 0
+}
+
+// done records the outcome of an allowed call.
+func (cb *circuitBreaker) done(err error) {
+	cb.mtx.Lock()
+	defer cb.mtx.Unlock()
+	if err == nil || errors.Is(err, context.Canceled) {
+		// A canceled call says nothing about the health of the service.
+		if err == nil {
+			cb.failures = 0
+			cb.metrics.IndexerBreakerOpen.Set(0)
+		}
+		return
+	}
+	cb.failures++
+	if cb.failures >= cb.maxFailures {
+		cb.openUntil = time.Now().Add(cb.cooldown)
+		cb.metrics.IndexerBreakerOpen.Set(1)
+	}
+}
+
+// indexerGuard applies the search timeout and the circuit breaker to the
+// calls to an indexer.
+type indexerGuard struct {
+	timeout time.Duration
+	breaker *circuitBreaker // nil if disabled
+	metrics *Metrics
+}
+
+func (g *indexerGuard) call(ctx context.Context, f func(ctx context.Context) error) error {
+	return g.callWithTimeout(ctx, g.timeout, f)
+}
+
+// callWithTimeout calls f through the circuit breaker, canceling it after
+// timeout rather than the timeout of the guard, if positive.
+func (g *indexerGuard) callWithTimeout(
+	ctx context.Context,
+	timeout time.Duration,
+	f func(ctx context.Context) error,
+) error {
+	if g.breaker != nil {
+		if remaining := g.breaker.allow(); remaining > 0 {
+			return unavailableError{err: errIndexerUnavailable, retryAfter: remaining}
+		}
+	}
+	parent := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	err := f(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		g.metrics.IndexerTimeouts.Add(1)
+		if timeout > 0 && parent.Err() == nil {
+			err = searchTimeoutError(timeout)
+		}
+	}
+	if g.breaker != nil {
+		g.breaker.done(err)
+	}
+	return err
+}
+
+// guardedTxIndexer is a TxIndexer whose reads go through an indexerGuard.
+type guardedTxIndexer struct {
+	txindex.TxIndexer
+	guard *indexerGuard
+}
+
+func (idx guardedTxIndexer) Get(hash []byte) (res *abci.TxResult, err error) {
+	err = idx.guard.call(context.Background(), func(context.Context) error {
+		res, err = idx.TxIndexer.Get(hash)
+		return err
+	})
+	return res, err
+}
+
+func (idx guardedTxIndexer) Search(ctx context.Context, q *query.Query) (res []*abci.TxResult, err error) {
+	err = idx.guard.call(ctx, func(ctx context.Context) error {
+		res, err = idx.TxIndexer.Search(ctx, q)
+		return err
+	})
+	return res, err
+}
+
+// searchTxs implements txSearcher. The timeout of the guard does not apply,
+// as the streaming search route has a timeout of its own.
+func (idx guardedTxIndexer) searchTxs(ctx context.Context, q *query.Query, desc bool) (it txIterator, err error) {
+	err = idx.guard.callWithTimeout(ctx, 0, func(ctx context.Context) error {
+		it, err = searchTxs(ctx, idx.TxIndexer, q, desc)
+		return err
+	})
+	return it, err
+}
+
+// guardedBlockIndexer is a BlockIndexer whose reads go through an
+// indexerGuard.
+type guardedBlockIndexer struct {
+	indexer.BlockIndexer
+	guard *indexerGuard
+}
+
+func (idx guardedBlockIndexer) Has(height int64) (has bool, err error) {
+	err = idx.guard.call(context.Background(), func(context.Context) error {
+		has, err = idx.BlockIndexer.Has(height)
+		return err
+	})
+	return has, err
+}
+
+func (idx guardedBlockIndexer) Search(ctx context.Context, q *query.Query) (res []int64, err error) {
+	err = idx.guard.call(ctx, func(ctx context.Context) error {
+		res, err = idx.BlockIndexer.Search(ctx, q)
+		return err
+	})
+	return res, err
+}
+
+// guardIndexers wraps the indexers of env, or of each of its event sinks,
+// with the timeout and circuit breaker set on env, if any. Every event sink
+// has a circuit breaker of its own. Disabled indexers are left as is, so that
+// the routes can still report that indexing is disabled.
+func (env *environment) guardIndexers() {
+	if env.indexerTimeout <= 0 && env.breakerMaxFailures <= 0 {
+		return
+	}
+	env.wrapIndexers(func(txidx txindex.TxIndexer, blkidx indexer.BlockIndexer) (txindex.TxIndexer, indexer.BlockIndexer) {
+		guard := &indexerGuard{timeout: env.indexerTimeout, metrics: env.metrics}
+		if env.breakerMaxFailures > 0 {
+			guard.breaker = &circuitBreaker{
+				maxFailures: env.breakerMaxFailures,
+				cooldown:    env.breakerCooldown,
+				metrics:     env.metrics,
+			}
+		}
+		if _, ok := txidx.(*null.TxIndex); !ok {
+			txidx = guardedTxIndexer{TxIndexer: txidx, guard: guard}
+		}
+		if _, ok := blkidx.(*blockidxnull.BlockerIndexer); !ok {
+			blkidx = guardedBlockIndexer{BlockIndexer: blkidx, guard: guard}
+		}
+		return txidx, blkidx
+	})
+}
+
+// unavailableError is an error meaning that a service is unavailable for at
+// least retryAfter.
+type unavailableError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e unavailableError) Error() string { return e.err.Error() }
+
+func (e unavailableError) Unwrap() error { return e.err }
+
+// unavailableMiddleware maps the unavailableErrors returned by the routes to a
+// service unavailable error.
+func unavailableMiddleware(_ string, next routeHandler) routeHandler {
+	return func(ctx *rpctypes.Context, args []reflect.Value) (interface{}, error) {
+		result, err := next(ctx, args)
+		var uErr unavailableError
+		if errors.As(err, &uErr) {
+			return nil, serviceUnavailableError(ctx, uErr.err, uErr.retryAfter)
+		}
+		return result, err
+	}
+}
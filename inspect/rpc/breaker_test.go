@@ -0,0 +1,68 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/libs/pubsub/query"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	indexermocks "github.com/cometbft/cometbft/state/indexer/mocks"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
+)
+
+func TestIndexerCircuitBreaker(t *testing.T) {
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	txIndexerMock.On("Search", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused")).Twice()
+	txIndexerMock.On("Search", mock.Anything, mock.Anything).Return([]*abcitypes.TxResult{}, nil)
+	h := newGuardedTestHandler(txIndexerMock, IndexerCircuitBreaker(2, 50*time.Millisecond))
+	search := func() *rpctypes.RPCError {
+		return callJSONRPC(t, h, "tx_search", `{"query":"tx.height=1"}`).Error
+	}
+
+	// The breaker opens after two consecutive failures.
+	require.Equal(t, -32603, search().Code)
+	require.Equal(t, -32603, search().Code)
+	require.Equal(t, codeServiceUnavailable, search().Code)
+	txIndexerMock.AssertNumberOfCalls(t, "Search", 2)
+
+	// Calls are let through again after the cooldown.
+	time.Sleep(50 * time.Millisecond)
+	require.Nil(t, search())
+	require.Nil(t, search())
+}
+
+func TestIndexerTimeout(t *testing.T) {
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	txIndexerMock.On("Search", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, _ *query.Query) []*abcitypes.TxResult {
+			<-ctx.Done()
+			return nil
+		},
+		func(ctx context.Context, _ *query.Query) error {
+			return ctx.Err()
+		})
+	h := newGuardedTestHandler(txIndexerMock, IndexerTimeout(10*time.Millisecond))
+
+	res := callJSONRPC(t, h, "tx_search", `{"query":"tx.height=1"}`)
+	require.NotNil(t, res.Error)
+	require.Equal(t, codeSearchTimeout, res.Error.Code)
+	require.Equal(t, searchTimeoutMessage(10*time.Millisecond), res.Error.Data)
+}
+
+func newGuardedTestHandler(txIndexer *txindexmocks.TxIndexer, options ...RoutesOption) http.Handler {
+	cfg := config.TestRPCConfig()
+	logger := log.NewNopLogger()
+	routes := Routes(*cfg, &statemocks.Store{}, &statemocks.BlockStore{}, txIndexer, &indexermocks.BlockIndexer{},
+		logger, options...)
+	return Handler(cfg, routes, logger)
+}
@@ -0,0 +1,117 @@
+package rpc
+
+import (
+	"errors"
+	"reflect"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+const (
+	// encodingOverhead is the factor applied to the size of the stored data
+	// to estimate the size of its JSON encoding, where byte slices are base64
+	// or hex encoded.
+	encodingOverhead = 2
+
+	// txResultSizeEstimate is the estimated size of the encoded result of
+	// executing a single transaction.
+	txResultSizeEstimate = 1024
+
+	// budgetRetryAfter is the delay after which clients are asked to retry
+	// requests rejected because the response budget is exhausted.
+	budgetRetryAfter = time.Second
+)
+
+// errBudgetExhausted is returned by the routes when the estimated size of
+// their response does not fit in the remaining response budget.
+var errBudgetExhausted = errors.New("too many large responses in flight")
+
+// MaxInFlightBytes caps the total estimated size of the responses of all the
+// requests served at once. The size of a response is estimated from the block
+// metadata before it is loaded, and reserved until the response is written.
+// Requests whose response does not fit in the remaining budget are rejected
+// with a service unavailable error. Only the routes returning blocks or block
+// results are accounted for. A value of 0 disables the budget.
+func MaxInFlightBytes(n int64) RoutesOption {
+	return func(env *environment) {
+		env.maxInFlightBytes = n
+	}
+}
+
+// responseSizeEstimators estimate the size of the response of a route from
+// the arguments of a call.
+var responseSizeEstimators = map[string]func(env *environment, args []reflect.Value) int64{
+	"block": func(env *environment, args []reflect.Value) int64 {
+		return blockMetaSize(env.heightBlockMeta(args[0]))
+	},
+	"block_with_commit": func(env *environment, args []reflect.Value) int64 {
+		return blockMetaSize(env.heightBlockMeta(args[0]))
+	},
+	"block_txs": func(env *environment, args []reflect.Value) int64 {
+		return blockMetaSize(env.heightBlockMeta(args[0]))
+	},
+	"block_by_hash": func(env *environment, args []reflect.Value) int64 {
+		hash, _ := args[0].Interface().([]byte)
+		return blockMetaSize(env.BlockStore.LoadBlockMetaByHash(hash))
+	},
+	"block_results": func(env *environment, args []reflect.Value) int64 {
+		if blockMeta := env.heightBlockMeta(args[0]); blockMeta != nil {
+			return int64(blockMeta.NumTxs) * txResultSizeEstimate * encodingOverhead
+		}
+		return 0
+	},
+}
+
+// heightBlockMeta loads the block meta at the height of an optional height
+// argument, defaulting to the latest height.
+func (env *environment) heightBlockMeta(heightArg reflect.Value) *types.BlockMeta {
+	height := env.BlockStore.Height()
+	if heightPtr, _ := heightArg.Interface().(*int64); heightPtr != nil {
+		height = *heightPtr
+	}
+	return env.BlockStore.LoadBlockMeta(height)
+}
+
+func blockMetaSize(blockMeta *types.BlockMeta) int64 {
+	if blockMeta == nil {
+		return 0
+	}
+	return int64(blockMeta.BlockSize) * encodingOverhead
+}
+
+// responseBudgetMiddleware reserves the estimated size of the response of the
+// routes with an estimator in budget, until the response has been written.
+func responseBudgetMiddleware(env *environment, budget *semaphore.Weighted, maxBytes int64) routeMiddleware {
+	return func(route string, next routeHandler) routeHandler {
+		estimate, ok := responseSizeEstimators[route]
+		if !ok {
+			return next
+		}
+		return func(ctx *rpctypes.Context, args []reflect.Value) (interface{}, error) {
+			// A response larger than the whole budget is served alone.
+			size := estimate(env, args)
+			if size > maxBytes {
+				size = maxBytes
+			}
+			if size <= 0 {
+				return next(ctx, args)
+			}
+			if !budget.TryAcquire(size) {
+				return nil, serviceUnavailableError(ctx, errBudgetExhausted, budgetRetryAfter)
+			}
+			release := func() { budget.Release(size) }
+
+			state := stateFromContext(ctx)
+			if state == nil {
+				defer release()
+			} else {
+				state.onDone = append(state.onDone, release)
+			}
+			return next(ctx, args)
+		}
+	}
+}
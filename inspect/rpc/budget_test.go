@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/semaphore"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestResponseBudget(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Height").Return(int64(2))
+	blockStoreMock.On("LoadBlockMeta", int64(1)).Return(&types.BlockMeta{BlockSize: 300})
+	blockStoreMock.On("LoadBlockMeta", int64(2)).Return(&types.BlockMeta{BlockSize: 100})
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{})
+	budget := semaphore.NewWeighted(1000)
+
+	// The budget is held until the request state is done.
+	var held routeHandler = func(*rpctypes.Context, []reflect.Value) (interface{}, error) {
+		require.False(t, budget.TryAcquire(401))
+		return nil, nil
+	}
+	h := responseBudgetMiddleware(env, budget, 1000)("block", held)
+	handler := requestStateHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := &rpctypes.Context{HTTPReq: r}
+		height := int64(1)
+		_, err := h(ctx, []reflect.Value{reflect.ValueOf(&height)})
+		require.NoError(t, err)
+
+		// The latest block fits in the remaining budget, unlike a second
+		// block at height 1.
+		_, err = h(ctx, []reflect.Value{reflect.ValueOf((*int64)(nil))})
+		require.NoError(t, err)
+		_, err = h(ctx, []reflect.Value{reflect.ValueOf(&height)})
+		require.Error(t, err)
+		require.Equal(t, codeServiceUnavailable, err.(*rpctypes.RPCError).Code)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/block", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	// Once the response is written, the whole budget is available again.
+	require.True(t, budget.TryAcquire(1000))
+}
@@ -0,0 +1,73 @@
+package rpc
+
+import (
+	"container/list"
+	"time"
+
+	cmtsync "github.com/cometbft/cometbft/libs/sync"
+)
+
+// lruCache is a thread-safe LRU cache whose entries may expire.
+type lruCache[K comparable, V any] struct {
+	mtx  cmtsync.Mutex
+	size int
+	// ttl is the maximum age of the entries, if positive.
+	ttl      time.Duration
+	now      func() time.Time
+	cacheMap map[K]*list.Element
+	// list holds the cached entries, most recently used last.
+	list *list.List
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+	added time.Time
+}
+
+func newLRUCache[K comparable, V any](size int, ttl time.Duration) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		size:     size,
+		ttl:      ttl,
+		now:      time.Now,
+		cacheMap: make(map[K]*list.Element, size),
+		list:     list.New(),
+	}
+}
+
+func (c *lruCache[K, V]) get(key K) (V, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	var zero V
+	e, ok := c.cacheMap[key]
+	if !ok {
+		return zero, false
+	}
+	entry := e.Value.(*lruEntry[K, V])
+	if c.ttl > 0 && c.now().Sub(entry.added) >= c.ttl {
+		delete(c.cacheMap, key)
+		c.list.Remove(e)
+		return zero, false
+	}
+	c.list.MoveToBack(e)
+	return entry.value, true
+}
+
+func (c *lruCache[K, V]) add(key K, value V) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.cacheMap[key]; ok {
+		e.Value = &lruEntry[K, V]{key: key, value: value, added: c.now()}
+		c.list.MoveToBack(e)
+		return
+	}
+	if c.list.Len() >= c.size {
+		if front := c.list.Front(); front != nil {
+			delete(c.cacheMap, front.Value.(*lruEntry[K, V]).key)
+			c.list.Remove(front)
+		}
+	}
+	c.cacheMap[key] = c.list.PushBack(&lruEntry[K, V]{key: key, value: value, added: c.now()})
+}
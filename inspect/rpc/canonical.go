@@ -0,0 +1,111 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+)
+
+// CanonicalJSON makes the handler write the JSON responses with the keys of
+// all objects sorted, so that identical results are encoded to identical
+// bytes. JSON responses are re-encoded once written by the routes, which
+// costs an additional decoding and encoding of each of them. The NDJSON
+// streams are written as they are, without being held back.
+func CanonicalJSON() HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.canonicalJSON = true
+	}
+}
+
+// canonicalHandler re-encodes the JSON responses of h with sorted keys.
+// Websocket connections are passed through to h, and the responses which are
+// not JSON, such as the NDJSON streams, are written through as they are
+// written by h.
+func canonicalHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &jsonResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+		if !rec.buffering {
+			return
+		}
+
+		body := rec.body.Bytes()
+		if canonical, err := canonicalizeJSON(body); err == nil {
+			body = canonical
+			w.Header().Del("Content-Length")
+		}
+		w.WriteHeader(rec.status)
+		w.Write(body) //nolint: errcheck
+	})
+}
+
+// jsonResponseWriter buffers the JSON responses written to it, so that they
+// can be rewritten. The other responses are written through to the
+// underlying writer, so that streams are not held back.
+type jsonResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+	// buffering is true if the response is JSON, once the header is
+	// written.
+	buffering   bool
+	wroteHeader bool
+}
+
+func (w *jsonResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.buffering = isJSON(w.Header().Get("Content-Type"))
+	if w.buffering {
+		w.status = status
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *jsonResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffering {
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher. Buffered responses are not flushed.
+func (w *jsonResponseWriter) Flush() {
+	if w.buffering {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// isJSON returns true if contentType is JSON or a JSON based media type.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || mediaType == CompactMediaType)
+}
+
+// canonicalizeJSON returns the JSON document b with the keys of all objects
+// sorted. Numbers are kept as written.
+func canonicalizeJSON(b []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	// Maps are encoded with their keys sorted.
+	return json.Marshal(v)
+}
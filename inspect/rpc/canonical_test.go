@@ -0,0 +1,72 @@
+package rpc
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	indexermocks "github.com/cometbft/cometbft/state/indexer/mocks"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestCanonicalizeJSON(t *testing.T) {
+	b, err := canonicalizeJSON([]byte(`{"b":{"d":1,"c":[{"z":null,"y":"x"}]},"a":12345678901234567890}`))
+	require.NoError(t, err)
+	require.Equal(t, `{"a":12345678901234567890,"b":{"c":[{"y":"x","z":null}],"d":1}}`, string(b))
+
+	_, err = canonicalizeJSON([]byte(`{"a":`))
+	require.Error(t, err)
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(1))
+	blockStoreMock.On("LoadBlockMeta", int64(1)).Return(&types.BlockMeta{
+		Header: types.Header{Height: 1, ChainID: "test-chain"},
+	})
+	cfg := config.TestRPCConfig()
+	logger := log.NewNopLogger()
+	routes := Routes(*cfg, &statemocks.Store{}, blockStoreMock,
+		&txindexmocks.TxIndexer{}, &indexermocks.BlockIndexer{}, logger)
+
+	serve := func(h http.Handler) []byte {
+		body := `{"jsonrpc":"2.0","id":1,"method":"header","params":{"height":"1"}}`
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body))))
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec.Body.Bytes()
+	}
+
+	plain := serve(Handler(cfg, routes, logger))
+	canonical := serve(Handler(cfg, routes, logger, CanonicalJSON()))
+	require.True(t, bytes.HasPrefix(plain, []byte(`{"jsonrpc"`)))
+	require.True(t, bytes.HasPrefix(canonical, []byte(`{"id":1,"jsonrpc":"2.0","result":{"header":{"app_hash"`)))
+
+	expected, err := canonicalizeJSON(plain)
+	require.NoError(t, err)
+	require.Equal(t, expected, canonical)
+}
+
+func TestCanonicalJSONStreams(t *testing.T) {
+	rec := httptest.NewRecorder()
+	h := canonicalHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", NDJSONMediaType)
+		w.Write([]byte(`{"b":1,"a":2}` + "\n")) //nolint: errcheck
+		w.(http.Flusher).Flush()
+		// The lines are written through as they are written.
+		require.True(t, rec.Flushed)
+		require.Equal(t, `{"b":1,"a":2}`+"\n", rec.Body.String())
+		w.Write([]byte(`{"d":3,"c":4}` + "\n")) //nolint: errcheck
+	}))
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/block_metas_stream", nil))
+	require.Equal(t, NDJSONMediaType, rec.Header().Get("Content-Type"))
+	require.Equal(t, `{"b":1,"a":2}`+"\n"+`{"d":3,"c":4}`+"\n", rec.Body.String())
+}
@@ -0,0 +1,95 @@
+package rpc
+
+import (
+	"sort"
+
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/state/indexer"
+	blockidxnull "github.com/cometbft/cometbft/state/indexer/block/null"
+	"github.com/cometbft/cometbft/state/txindex"
+	"github.com/cometbft/cometbft/state/txindex/null"
+)
+
+// storeCapability is a kind of data which the stores may not hold.
+type storeCapability string
+
+const (
+	capabilityTxIndex        storeCapability = "transaction index"
+	capabilityBlockIndex     storeCapability = "block index"
+	capabilityBlockResponses storeCapability = "FinalizeBlock responses"
+	capabilityGenesis        storeCapability = "genesis document"
+)
+
+// routeCapabilities maps the routes reading data which the stores may not
+// hold to the capability they require.
+var routeCapabilities = map[string]storeCapability{
+	"tx":             capabilityTxIndex,
+	"txs":            capabilityTxIndex,
+	"tx_locate":      capabilityTxIndex,
+	"tx_proof_full":  capabilityTxIndex,
+	"tx_search":      capabilityTxIndex,
+	"block_search":   capabilityBlockIndex,
+	"block_results":  capabilityBlockResponses,
+	"events":         capabilityBlockResponses,
+	"decoded_events": capabilityBlockResponses,
+	// The genesis document is not read from the stores, but passed with the
+	// Genesis option.
+	"genesis":         capabilityGenesis,
+	"genesis_chunked": capabilityGenesis,
+	"genesis_info":    capabilityGenesis,
+}
+
+// blockResponsesStore is implemented by the state stores which report
+// whether they persist the FinalizeBlock responses of every height, such as
+// the state stores returned by state.NewStore.
+type blockResponsesStore interface {
+	PersistsFinalizeBlockResponses() bool
+}
+
+// probeStores returns the capabilities which the stores of env are known to
+// lack. Indexers are lacking if they are the null indexers, which is the
+// case when indexing is disabled, and the stores which cannot tell whether
+// they hold some data are assumed to hold it. It must be called before the
+// stores are wrapped.
+func (env *environment) probeStores() map[storeCapability]bool {
+	missing := make(map[storeCapability]bool)
+	txIndexing, blockIndexing := isIndexing(env.TxIndexer, env.BlockIndexer)
+	if len(env.eventSinks) > 0 {
+		// The routes read from the event sinks instead of the indexers.
+		txIndexing, blockIndexing = false, false
+		for _, sink := range env.eventSinks {
+			txSink, blockSink := isIndexing(sink.TxIndexer, sink.BlockIndexer)
+			txIndexing, blockIndexing = txIndexing || txSink, blockIndexing || blockSink
+		}
+	}
+	missing[capabilityTxIndex] = !txIndexing
+	missing[capabilityBlockIndex] = !blockIndexing
+	missing[capabilityGenesis] = env.GenDoc == nil
+	if s, ok := env.StateStore.(blockResponsesStore); ok {
+		missing[capabilityBlockResponses] = !s.PersistsFinalizeBlockResponses()
+	}
+	return missing
+}
+
+// isIndexing returns whether the indexers are not the null indexers.
+func isIndexing(txidx txindex.TxIndexer, blkidx indexer.BlockIndexer) (tx, block bool) {
+	_, txNull := txidx.(*null.TxIndex)
+	_, blockNull := blkidx.(*blockidxnull.BlockerIndexer)
+	return !txNull, !blockNull
+}
+
+// removeUnsupportedRoutes removes from routes those which cannot be served,
+// as the data they read is missing, and logs them.
+func removeUnsupportedRoutes(routes map[string]route, missing map[storeCapability]bool, logger log.Logger) {
+	removed := make(map[storeCapability][]string)
+	for name, capability := range routeCapabilities {
+		if _, ok := routes[name]; ok && missing[capability] {
+			delete(routes, name)
+			removed[capability] = append(removed[capability], name)
+		}
+	}
+	for capability, names := range removed {
+		sort.Strings(names)
+		logger.Info("Not serving routes: missing the "+string(capability), "routes", names)
+	}
+}
@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	blockidxnull "github.com/cometbft/cometbft/state/indexer/block/null"
+	indexermocks "github.com/cometbft/cometbft/state/indexer/mocks"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
+	"github.com/cometbft/cometbft/state/txindex/null"
+	"github.com/cometbft/cometbft/types"
+)
+
+// discardingStateStore is a state store which does not persist the
+// FinalizeBlock responses.
+type discardingStateStore struct {
+	*statemocks.Store
+}
+
+func (discardingStateStore) PersistsFinalizeBlockResponses() bool { return false }
+
+func TestRoutesStoreCapabilities(t *testing.T) {
+	cfg := config.TestRPCConfig()
+	logger := log.NewNopLogger()
+
+	// Stores which cannot tell their capabilities are assumed to have them.
+	routes := Routes(*cfg, &statemocks.Store{}, &statemocks.BlockStore{},
+		&txindexmocks.TxIndexer{}, &indexermocks.BlockIndexer{}, logger, Genesis(&types.GenesisDoc{ChainID: "test"}))
+	for route := range routeCapabilities {
+		require.Contains(t, routes, route)
+	}
+
+	routes = Routes(*cfg, discardingStateStore{&statemocks.Store{}}, &statemocks.BlockStore{},
+		&null.TxIndex{}, &blockidxnull.BlockerIndexer{}, logger)
+	for route := range routeCapabilities {
+		require.NotContains(t, routes, route)
+	}
+	require.Contains(t, routes, "block")
+
+	// The routes are served if any event sink indexes the data, regardless
+	// of the indexers which the sinks replace.
+	routes = Routes(*cfg, &statemocks.Store{}, &statemocks.BlockStore{},
+		&null.TxIndex{}, &indexermocks.BlockIndexer{}, logger, EventSinks(
+			EventSink{Name: "null", TxIndexer: &null.TxIndex{}, BlockIndexer: &blockidxnull.BlockerIndexer{}},
+			EventSink{Name: "kv", TxIndexer: &txindexmocks.TxIndexer{}, BlockIndexer: &blockidxnull.BlockerIndexer{}},
+		))
+	require.Contains(t, routes, "tx_search")
+	require.NotContains(t, routes, "block_search")
+}
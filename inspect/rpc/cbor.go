@@ -0,0 +1,89 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// CBORMediaType is the media type a client sets in the Accept header of a
+// request to receive the response encoded in CBOR (RFC 8949) instead of JSON.
+//
+// The CBOR document has the same structure as the JSON response, with the
+// deterministic encoding of RFC 8949: JSON objects are encoded as maps with
+// text keys, in the bytewise order of their encodings, and JSON numbers as
+// integers if they are integral, or as the shortest floats holding them
+// otherwise.
+// As in JSON, 64-bit integers within results are encoded as text strings.
+const CBORMediaType = "application/cbor"
+
+// CBORCodec encodes the responses in CBOR, for the clients accepting
+// CBORMediaType. It is registered by default.
+type CBORCodec struct{}
+
+var _ ResponseCodec = CBORCodec{}
+
+// MediaType implements ResponseCodec.
+func (CBORCodec) MediaType() string { return CBORMediaType }
+
+// Encode implements ResponseCodec.
+func (CBORCodec) Encode(json []byte) ([]byte, error) { return jsonToCBOR(json) }
+
+// cborEncMode encodes the responses with the deterministic encoding of RFC
+// 8949, section 4.2.1.
+var cborEncMode = func() cbor.EncMode {
+	em, err := cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return em
+}()
+
+// jsonToCBOR encodes the JSON document b in CBOR.
+func jsonToCBOR(b []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	v, err := cborValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return cborEncMode.Marshal(v)
+}
+
+// cborValue returns v, a value decoded from JSON with numbers as json.Number,
+// with the numbers as integers if they are integral, or as floats otherwise.
+func cborValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case json.Number:
+		if u, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return u, nil
+		}
+		if i, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case []interface{}:
+		for i, item := range v {
+			item, err := cborValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = item
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			item, err := cborValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = item
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,75 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestJSONToCBOR(t *testing.T) {
+	// Expected encodings from RFC 8949, appendix A.
+	testCases := []struct {
+		json string
+		cbor string
+	}{
+		{`0`, "00"},
+		{`23`, "17"},
+		{`24`, "1818"},
+		{`1000000`, "1a000f4240"},
+		{`18446744073709551615`, "1bffffffffffffffff"},
+		{`-1`, "20"},
+		{`-1000`, "3903e7"},
+		{`1.5`, "f93e00"},
+		{`100000.0`, "fa47c35000"},
+		{`1.1`, "fb3ff199999999999a"},
+		{`null`, "f6"},
+		{`true`, "f5"},
+		{`false`, "f4"},
+		{`"IETF"`, "6449455446"},
+		{`[1,[2,3]]`, "8201820203"},
+		{`{"b":[2],"a":1,"aa":null}`, "a361610161628102626161f6"},
+	}
+	for _, tc := range testCases {
+		b, err := jsonToCBOR([]byte(tc.json))
+		require.NoError(t, err, tc.json)
+		require.Equal(t, tc.cbor, hex.EncodeToString(b), tc.json)
+	}
+
+	_, err := jsonToCBOR([]byte(`{`))
+	require.Error(t, err)
+}
+
+func TestCBORResponses(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(1))
+	blockStoreMock.On("LoadBlockMeta", int64(1)).Return(&types.BlockMeta{Header: types.Header{Height: 1}})
+	h := newTestHandler(blockStoreMock, &statemocks.Store{})
+
+	serve := func(accept string) *httptest.ResponseRecorder {
+		body := `{"jsonrpc":"2.0","id":1,"method":"header","params":{"height":"1"}}`
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body)))
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("application/json")
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	expected, err := jsonToCBOR(rec.Body.Bytes())
+	require.NoError(t, err)
+
+	rec = serve(CBORMediaType + ", application/json")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, CBORMediaType, rec.Header().Get("Content-Type"))
+	require.Equal(t, "Accept", rec.Header().Get("Vary"))
+	require.Equal(t, expected, rec.Body.Bytes())
+}
@@ -0,0 +1,52 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/rpc/jsonrpc/server"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// ChainIDHeader is the request header carrying the chain ID expected by the
+// client, for RequireChainID.
+const ChainIDHeader = "X-Chain-ID"
+
+// RequireChainID rejects the requests which do not carry chainID in their
+// ChainIDHeader header, so that clients of a fleet of Inspectors do not query
+// the wrong chain by mistake. Requests without the header are rejected with a
+// 400 status, and requests for another chain with a 409 status. Browsers can
+// only send the header if it is allowed by the CORS configuration.
+func RequireChainID(chainID string) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.chainID = &chainID
+	}
+}
+
+// chainIDHandler rejects the requests to h whose ChainIDHeader header is not
+// chainID.
+func chainIDHandler(h http.Handler, chainID string, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			err    error
+			status int
+		)
+		switch value := r.Header.Get(ChainIDHeader); {
+		case value == "":
+			err, status = errors.New("missing chain ID"), http.StatusBadRequest
+		case value != chainID:
+			err, status = fmt.Errorf("chain ID %q does not match the chain %q of the server", value, chainID),
+				http.StatusConflict
+		}
+		if err != nil {
+			res := rpctypes.RPCInvalidRequestError(nil, fmt.Errorf("%s: %w", ChainIDHeader, err))
+			if wErr := server.WriteRPCResponseHTTPError(w, status, res); wErr != nil {
+				logger.Error("failed to write response", "err", wErr)
+			}
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
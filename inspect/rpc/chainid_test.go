@@ -0,0 +1,32 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/rpc/core"
+)
+
+func TestRequireChainID(t *testing.T) {
+	h := Handler(config.TestRPCConfig(), core.RoutesMap{}, log.NewNopLogger(), RequireChainID("test-chain"))
+	serve := func(chainID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if chainID != "" {
+			req.Header.Set(ChainIDHeader, chainID)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, serve("test-chain").Code)
+	rec := serve("other-chain")
+	require.Equal(t, http.StatusConflict, rec.Code)
+	require.Contains(t, rec.Body.String(), `chain ID \"other-chain\" does not match the chain \"test-chain\" of the server`)
+	require.Equal(t, http.StatusBadRequest, serve("").Code)
+}
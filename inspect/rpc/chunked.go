@@ -0,0 +1,248 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ResponseChunkSize makes the handler stream the responses in chunks of size
+// bytes, flushing each chunk to the connection once it is written. A value of
+// 0 writes responses at once.
+//
+// The results of the routes are then encoded as the responses are written,
+// rather than in full beforehand, so that a large response, such as that of a
+// block, is not held in memory while a slow client receives it. The responses
+// which are rewritten once complete, such as with CanonicalJSON, the pretty
+// parameter or a codec other than JSON, are still held in memory to be
+// rewritten. The responses to the clients accepting gzip are compressed, and
+// the compressed bytes chunked. Since the length of the responses is not known
+// in advance, they are sent with chunked transfer encoding.
+func ResponseChunkSize(size int) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.chunkSize = size
+	}
+}
+
+// resultStreamHandler makes the routes defer the encoding of their results to
+// the writing of the responses of h, which encodes the results into the
+// responses as they are written, in place of their tokens.
+func resultStreamHandler(h http.Handler, size int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := requestStateFrom(r.Context())
+		if state == nil || r.Header.Get("Upgrade") != "" || !state.initTokens() {
+			h.ServeHTTP(w, r)
+			return
+		}
+		state.streamResults = true
+		h.ServeHTTP(&resultStreamWriter{ResponseWriter: w, state: state, size: size}, r)
+	})
+}
+
+// resultStreamWriter replaces the tokens of the deferred results in the
+// responses written to it by the encoding of the results, written as they are
+// encoded. The JSON-RPC server writes each response at once, so the tokens are
+// not split across writes.
+type resultStreamWriter struct {
+	http.ResponseWriter
+	state *requestState
+	size  int
+}
+
+func (w *resultStreamWriter) Write(b []byte) (int, error) {
+	written := len(b)
+	prefix := []byte(`"` + w.state.resultTokenPrefix())
+	for {
+		start := bytes.Index(b, prefix)
+		if start < 0 {
+			break
+		}
+		rest := b[start+len(prefix):]
+		end := bytes.IndexByte(rest, '"')
+		if end < 0 {
+			break
+		}
+		index, err := strconv.Atoi(string(rest[:end]))
+		if err != nil || index < 0 || index >= len(w.state.deferredResults) {
+			break
+		}
+		if _, err := w.ResponseWriter.Write(b[:start]); err != nil {
+			return 0, err
+		}
+		if err := w.writeResult(w.state.deferredResults[index]); err != nil {
+			return 0, err
+		}
+		// Release the result once written.
+		w.state.deferredResults[index] = routeResult{}
+		b = rest[end+1:]
+	}
+	if _, err := w.ResponseWriter.Write(b); err != nil {
+		return 0, err
+	}
+	return written, nil
+}
+
+// writeResult encodes result into the response. Once the encoding started,
+// an error leaves the response truncated.
+func (w *resultStreamWriter) writeResult(result routeResult) error {
+	bw := bufio.NewWriterSize(w.ResponseWriter, w.size)
+	if err := newJSONEncoder(bw, result.protobufTimestamps).Encode(result.value); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Flush implements http.Flusher.
+func (w *resultStreamWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by
+// http.ResponseController.
+func (w *resultStreamWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// chunkedHandler writes the responses of h in chunks of size bytes, which are
+// compressed with gzip first if the request accepts it. Websocket connections
+// are passed through to h.
+func chunkedHandler(h http.Handler, size int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		cw := &chunkedResponseWriter{ResponseWriter: w, size: size, acceptsGzip: acceptsGzip(r)}
+		defer cw.close()
+		h.ServeHTTP(cw, r)
+	})
+}
+
+// acceptsGzip returns true if the Accept-Encoding header of r lists gzip.
+func acceptsGzip(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept-Encoding") {
+		for _, coding := range strings.Split(accept, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(coding), ";")
+			if strings.EqualFold(strings.TrimSpace(coding), "gzip") && strings.TrimSpace(params) != "q=0" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// chunkedResponseWriter writes the responses to the underlying writer in
+// chunks, flushing each of them, after compressing them if gzip is accepted.
+type chunkedResponseWriter struct {
+	http.ResponseWriter
+	size        int
+	acceptsGzip bool
+
+	wroteHeader bool
+	gz          *gzip.Writer
+	// unflushed is the number of bytes of the current chunk written to
+	// the underlying writer.
+	unflushed int
+}
+
+func (w *chunkedResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	hasBody := status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+	if w.acceptsGzip && hasBody && w.Header().Get("Content-Encoding") == "" {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.Header().Del("Content-Length")
+		w.gz = gzip.NewWriter(chunkWriter{w})
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *chunkedResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz != nil {
+		return w.gz.Write(b)
+	}
+	return w.writeChunks(b)
+}
+
+// writeChunks writes b to the underlying writer, flushing it whenever a chunk
+// is complete.
+func (w *chunkedResponseWriter) writeChunks(b []byte) (int, error) {
+	var written int
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > w.size-w.unflushed {
+			chunk = chunk[:w.size-w.unflushed]
+		}
+		n, err := w.ResponseWriter.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		w.unflushed += n
+		if w.unflushed >= w.size {
+			w.flush()
+		}
+		b = b[n:]
+	}
+	return written, nil
+}
+
+func (w *chunkedResponseWriter) flush() {
+	w.unflushed = 0
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Flush implements http.Flusher.
+func (w *chunkedResponseWriter) Flush() {
+	if w.gz != nil {
+		w.gz.Flush() //nolint: errcheck
+	}
+	w.flush()
+}
+
+// close writes the end of the compressed response, if compressed.
+func (w *chunkedResponseWriter) close() {
+	if w.gz != nil {
+		w.gz.Close() //nolint: errcheck
+	}
+}
+
+// Hijack implements http.Hijacker, which is required by the websocket handler.
+func (w *chunkedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	return hj.Hijack()
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by
+// http.ResponseController.
+func (w *chunkedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// chunkWriter writes the compressed responses of a chunkedResponseWriter in
+// chunks.
+type chunkWriter struct {
+	w *chunkedResponseWriter
+}
+
+func (cw chunkWriter) Write(b []byte) (int, error) {
+	return cw.w.writeChunks(b)
+}
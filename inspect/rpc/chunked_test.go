@@ -0,0 +1,135 @@
+package rpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	indexermocks "github.com/cometbft/cometbft/state/indexer/mocks"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+type recordingFlusher struct {
+	*httptest.ResponseRecorder
+	writes []int
+}
+
+func (w *recordingFlusher) Write(b []byte) (int, error) {
+	w.writes = append(w.writes, len(b))
+	return w.ResponseRecorder.Write(b)
+}
+
+func TestChunkedHandler(t *testing.T) {
+	body := bytes.Repeat([]byte("a"), 10)
+	h := chunkedHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n, err := w.Write(body)
+		require.NoError(t, err)
+		require.Equal(t, len(body), n)
+	}), 4)
+
+	w := &recordingFlusher{ResponseRecorder: httptest.NewRecorder()}
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, []int{4, 4, 2}, w.writes)
+	require.Equal(t, body, w.Body.Bytes())
+	require.True(t, w.Flushed)
+}
+
+func TestChunkedHandlerGzip(t *testing.T) {
+	body := bytes.Repeat([]byte("a"), 1000)
+	h := chunkedHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Length", "1000")
+		_, err := w.Write(body)
+		require.NoError(t, err)
+	}), 4)
+
+	w := &recordingFlusher{ResponseRecorder: httptest.NewRecorder()}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "deflate, gzip;q=0.5")
+	h.ServeHTTP(w, req)
+	require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	require.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	require.Empty(t, w.Header().Get("Content-Length"))
+
+	// The compressed response is chunked.
+	for _, n := range w.writes {
+		require.LessOrEqual(t, n, 4)
+	}
+	r, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, body, decompressed)
+
+	// Responses are not compressed for the clients not accepting gzip.
+	w = &recordingFlusher{ResponseRecorder: httptest.NewRecorder()}
+	req.Header.Set("Accept-Encoding", "gzip;q=0")
+	h.ServeHTTP(w, req)
+	require.Empty(t, w.Header().Get("Content-Encoding"))
+	require.Equal(t, body, w.Body.Bytes())
+}
+
+func TestResponseChunkSize(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(1))
+	blockStoreMock.On("LoadBlockMeta", int64(1)).Return(&types.BlockMeta{
+		Header: types.Header{Height: 1, ChainID: "test-chain", Time: time.Unix(1, 0)},
+	})
+	serve := func(format string, req *http.Request, opts ...HandlerOption) *recordingFlusher {
+		cfg := config.TestRPCConfig()
+		cfg.TimestampFormat = format
+		logger := log.NewNopLogger()
+		routes := Routes(*cfg, &statemocks.Store{}, blockStoreMock,
+			&txindexmocks.TxIndexer{}, &indexermocks.BlockIndexer{}, logger)
+		w := &recordingFlusher{ResponseRecorder: httptest.NewRecorder()}
+		Handler(cfg, routes, logger, opts...).ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		return w
+	}
+	get := func() *http.Request {
+		return httptest.NewRequest(http.MethodGet, "/header?height=1", nil)
+	}
+	batch := func() *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/", strings.NewReader(
+			`[{"jsonrpc":"2.0","id":1,"method":"header","params":{}},`+
+				`{"jsonrpc":"2.0","id":2,"method":"header","params":{"height":"1"}}]`))
+	}
+
+	// The results are encoded into the responses, which are the same as
+	// those written at once.
+	for _, format := range []string{TimestampFormatRFC3339, TimestampFormatProtobuf} {
+		for _, req := range []func() *http.Request{get, batch} {
+			expected := serve(format, req()).Body.String()
+			w := serve(format, req(), ResponseChunkSize(16))
+			require.Equal(t, expected, w.Body.String())
+			require.NotContains(t, w.Body.String(), "inspect-")
+			require.Greater(t, len(w.writes), 1)
+			require.True(t, w.Flushed)
+		}
+	}
+
+	req := get()
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := serve("", req, ResponseChunkSize(16))
+	require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	r, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, serve("", get()).Body.String(), string(decompressed))
+
+	// The responses rewritten once complete are the same as well.
+	expected := serve("", get(), CanonicalJSON()).Body.String()
+	require.Equal(t, expected, serve("", get(), CanonicalJSON(), ResponseChunkSize(16)).Body.String())
+}
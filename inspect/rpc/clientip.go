@@ -0,0 +1,221 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies sets the address ranges of the reverse proxies in front of
+// the Inspector, whose forwarding headers are trusted to determine the
+// address of the clients and the scheme of their requests. See ClientIP and
+// RequestScheme.
+func TrustedProxies(prefixes ...netip.Prefix) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.trustedProxies = append(opts.trustedProxies, prefixes...)
+	}
+}
+
+type (
+	clientIPKey      struct{}
+	requestSchemeKey struct{}
+)
+
+// ClientIP returns the address of the client of the request served with ctx.
+//
+// If the peer of the request is a trusted proxy, the client is the nearest
+// address, in the forwarding headers, that is not a trusted proxy itself. The
+// RFC 7239 Forwarded header takes precedence over the X-Forwarded-For header
+// when a request carries both. If the chain of addresses ends with an unknown
+// or obfuscated address, the client is the proxy which forwarded it.
+// Otherwise, the client is the peer of the request.
+func ClientIP(ctx context.Context) (netip.Addr, bool) {
+	addr, ok := ctx.Value(clientIPKey{}).(netip.Addr)
+	return addr, ok
+}
+
+// RequestScheme returns the scheme, "http" or "https", with which the client
+// of the request served with ctx reached the Inspector. It is the scheme to
+// use when building URLs referring to the Inspector itself.
+//
+// If the peer of the request is a trusted proxy, such as a proxy terminating
+// TLS, the scheme is taken from the proto parameter of the nearest element of
+// the RFC 7239 Forwarded header carrying one or, failing that, from the
+// X-Forwarded-Proto header. Otherwise, the scheme is that of the connection
+// of the request.
+func RequestScheme(ctx context.Context) string {
+	if scheme, ok := ctx.Value(requestSchemeKey{}).(string); ok {
+		return scheme
+	}
+	return "http"
+}
+
+// clientIPHandler stores the address of the client of every request and the
+// scheme of the request in the request context, for retrieval with ClientIP
+// and RequestScheme.
+func clientIPHandler(h http.Handler, trustedProxies []netip.Prefix) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), requestSchemeKey{}, requestScheme(r, trustedProxies))
+		if addr, ok := clientIP(r, trustedProxies); ok {
+			ctx = context.WithValue(ctx, clientIPKey{}, addr)
+		}
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func requestScheme(r *http.Request, trustedProxies []netip.Prefix) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	peer, ok := parseHostPort(r.RemoteAddr)
+	if !ok || !isTrusted(peer, trustedProxies) {
+		return scheme
+	}
+
+	var proto string
+	if forwarded := r.Header.Values("Forwarded"); len(forwarded) > 0 {
+		protos := forwardedParams(forwarded, "proto")
+		if len(protos) > 0 {
+			proto = protos[len(protos)-1]
+		}
+	}
+	if proto == "" {
+		// Proxies appending to the header list the nearest scheme last.
+		if xfp := r.Header.Values("X-Forwarded-Proto"); len(xfp) > 0 {
+			protos := strings.Split(xfp[len(xfp)-1], ",")
+			proto = strings.TrimSpace(protos[len(protos)-1])
+		}
+	}
+	switch proto = strings.ToLower(proto); proto {
+	case "http", "https":
+		return proto
+	default:
+		return scheme
+	}
+}
+
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) (netip.Addr, bool) {
+	peer, ok := parseHostPort(r.RemoteAddr)
+	if !ok {
+		return netip.Addr{}, false
+	}
+	if !isTrusted(peer, trustedProxies) {
+		return peer, true
+	}
+
+	var hops []string
+	if forwarded := r.Header.Values("Forwarded"); len(forwarded) > 0 {
+		hops = forwardedParams(forwarded, "for")
+	} else {
+		for _, xff := range r.Header.Values("X-Forwarded-For") {
+			for _, hop := range strings.Split(xff, ",") {
+				hops = append(hops, strings.TrimSpace(hop))
+			}
+		}
+	}
+
+	// Walk the hops from the nearest to the farthest.
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseHostPort(hops[i])
+		if !ok {
+			break
+		}
+		client = addr
+		if !isTrusted(addr, trustedProxies) {
+			break
+		}
+	}
+	return client, true
+}
+
+// forwardedParams returns the values of the parameters named param of the
+// elements of the Forwarded headers, unquoted, in order. Elements without the
+// parameter are skipped.
+func forwardedParams(headers []string, param string) []string {
+	var hops []string
+	for _, header := range headers {
+		for _, element := range splitQuoted(header, ',') {
+			for _, pair := range splitQuoted(element, ';') {
+				name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok || !strings.EqualFold(name, param) {
+					continue
+				}
+				hops = append(hops, unquote(value))
+			}
+		}
+	}
+	return hops
+}
+
+// splitQuoted splits s on sep, ignoring the separators within quoted strings.
+func splitQuoted(s string, sep byte) []string {
+	var (
+		parts  []string
+		quoted bool
+		start  int
+	)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quoted:
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unquote returns the value of a token or quoted string.
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	var b strings.Builder
+	for i := 1; i < len(s)-1; i++ {
+		if s[i] == '\\' && i+1 < len(s)-1 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// parseHostPort parses an IP address with an optional port, where IPv6
+// addresses with a port are enclosed in brackets, as they may be without one.
+// Unknown and obfuscated identifiers, such as "unknown" or "_hidden", are not
+// addresses, nor are IPv6 addresses with a port but without brackets or with
+// unbalanced brackets.
+//
+// The zone of IPv6 addresses, such as eth0 in fe80::1%eth0, is dropped: it
+// names a network interface of the host which received the address, and
+// addresses with a zone are contained in no prefix, so that link-local peers
+// would never be trusted proxies and would be told apart by their zone.
+func parseHostPort(s string) (netip.Addr, bool) {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	} else if len(s) >= 2 && s[0] == '[' && s[len(s)-1] == ']' {
+		s = s[1 : len(s)-1]
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.WithZone("").Unmap(), true
+}
+
+func isTrusted(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
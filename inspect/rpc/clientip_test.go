@@ -0,0 +1,143 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/8")}
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		clientIP   string
+	}{
+		{"direct", "192.0.2.1:1234", nil, "192.0.2.1"},
+		{"untrusted peer", "192.0.2.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "192.0.2.1"},
+		{"x-forwarded-for", "10.0.0.1:1234",
+			map[string]string{"X-Forwarded-For": "203.0.113.9, 198.51.100.1, 10.0.0.2"}, "198.51.100.1"},
+		{"forwarded", "10.0.0.1:1234",
+			map[string]string{"Forwarded": `for=198.51.100.1;proto=https, for=10.0.0.2`}, "198.51.100.1"},
+		{"forwarded quoted ipv6 with port", "[fd00::1]:1234",
+			map[string]string{"Forwarded": `for="[2001:db8:cafe::17]:4711"`}, "2001:db8:cafe::17"},
+		{"forwarded over x-forwarded-for", "10.0.0.1:1234",
+			map[string]string{"Forwarded": "for=198.51.100.1", "X-Forwarded-For": "203.0.113.9"}, "198.51.100.1"},
+		{"forwarded quoted separators", "10.0.0.1:1234",
+			map[string]string{"Forwarded": `for=198.51.100.1;by="a,b;c", for=10.0.0.3`}, "198.51.100.1"},
+		{"obfuscated", "10.0.0.1:1234", map[string]string{"Forwarded": "for=_hidden, for=10.0.0.2"}, "10.0.0.2"},
+		{"all trusted", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "10.0.0.2"}, "10.0.0.2"},
+		{"trusted peer with zone", "[fd00::1%eth0]:1234",
+			map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+		{"forwarded ipv6 with zone", "10.0.0.1:1234",
+			map[string]string{"Forwarded": `for="[fe80::1%25eth1]:4711"`}, "fe80::1"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var addr netip.Addr
+			h := clientIPHandler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				var ok bool
+				addr, ok = ClientIP(r.Context())
+				require.True(t, ok)
+			}), trusted)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			require.Equal(t, netip.MustParseAddr(tc.clientIP), addr)
+		})
+	}
+}
+
+func TestRequestScheme(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		tls        bool
+		headers    map[string]string
+		scheme     string
+	}{
+		{"direct", "192.0.2.1:1234", false, nil, "http"},
+		{"direct tls", "192.0.2.1:1234", true, nil, "https"},
+		{"untrusted peer", "192.0.2.1:1234", false, map[string]string{"X-Forwarded-Proto": "https"}, "http"},
+		{"x-forwarded-proto", "10.0.0.1:1234", false, map[string]string{"X-Forwarded-Proto": "HTTPS"}, "https"},
+		{"x-forwarded-proto list", "10.0.0.1:1234", true, map[string]string{"X-Forwarded-Proto": "https, http"}, "http"},
+		{"forwarded", "10.0.0.1:1234", false,
+			map[string]string{"Forwarded": "for=198.51.100.1;proto=http, for=10.0.0.2;proto=https"}, "https"},
+		{"forwarded over x-forwarded-proto", "10.0.0.1:1234", false,
+			map[string]string{"Forwarded": "proto=https", "X-Forwarded-Proto": "http"}, "https"},
+		{"forwarded without proto", "10.0.0.1:1234", false,
+			map[string]string{"Forwarded": "for=198.51.100.1", "X-Forwarded-Proto": "https"}, "https"},
+		{"unknown scheme", "10.0.0.1:1234", true, map[string]string{"X-Forwarded-Proto": "gopher"}, "https"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var scheme string
+			h := clientIPHandler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				scheme = RequestScheme(r.Context())
+			}), trusted)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			if tc.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			require.Equal(t, tc.scheme, scheme)
+		})
+	}
+}
+
+func TestParseHostPort(t *testing.T) {
+	testCases := []struct {
+		s    string
+		addr string
+	}{
+		{"192.0.2.1", "192.0.2.1"},
+		{"192.0.2.1:1234", "192.0.2.1"},
+		{" 192.0.2.1 ", "192.0.2.1"},
+		{"2001:db8::1", "2001:db8::1"},
+		{"[2001:db8::1]", "2001:db8::1"},
+		{"[2001:db8::1]:1234", "2001:db8::1"},
+		{"[::ffff:192.0.2.1]:1234", "192.0.2.1"},
+		{"fe80::1%eth0", "fe80::1"},
+		{"[fe80::1%eth0]", "fe80::1"},
+		{"[fe80::1%eth0]:1234", "fe80::1"},
+		{"[fe80::1%25eth0]:1234", "fe80::1"},
+		// Without brackets, the last group is part of the address.
+		{"2001:db8::1:1234", "2001:db8::1:1234"},
+		{"", ""},
+		{"unknown", ""},
+		{"_hidden", ""},
+		{"[2001:db8::1", ""},
+		{"2001:db8::1]", ""},
+		{"[[2001:db8::1]]", ""},
+		{"fe80::1%", ""},
+		{"192.0.2.1%eth0", ""},
+		{"192.0.2.1:", "192.0.2.1"},
+		{"@", ""},
+	}
+	for _, tc := range testCases {
+		addr, ok := parseHostPort(tc.s)
+		if tc.addr == "" {
+			require.False(t, ok, tc.s)
+			continue
+		}
+		require.True(t, ok, tc.s)
+		require.Equal(t, netip.MustParseAddr(tc.addr), addr, tc.s)
+	}
+}
@@ -0,0 +1,67 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"golang.org/x/sync/singleflight"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// CoalesceRequests serves the concurrent calls to a route with identical
+// arguments with a single call, whose result is shared by all of them, so
+// that a burst of requests for the same block reads the stores once. Calls
+// still return as soon as their own request is canceled or times out. Errors
+// are only shared with the calls waiting for them, never with later ones.
+// Disabled by default.
+func CoalesceRequests() RoutesOption {
+	return func(env *environment) {
+		env.coalesceRequests = true
+	}
+}
+
+// coalesceMiddleware serves the concurrent calls to the routes with identical
+// arguments with a single call to the next handler, whose effects on the state
+// of its request are applied to those of all the calls.
+func coalesceMiddleware() routeMiddleware {
+	var group singleflight.Group
+	return func(route string, next routeHandler) routeHandler {
+		return func(ctx *rpctypes.Context, args []reflect.Value) (interface{}, error) {
+			key, err := routeCacheKey(args)
+			if err != nil {
+				return next(ctx, args)
+			}
+			reqCtx := context.Background()
+			if ctx != nil {
+				reqCtx = ctx.Context()
+			}
+
+			results := group.DoChan(route+key, func() (interface{}, error) {
+				result, effects, err := callRecordingEffects(ctx, args, next)
+				return sharedResult{value: result, effects: effects}, err
+			})
+			select {
+			case res := <-results:
+				// The call may have failed because the request which made it
+				// was canceled, while this one is still being served.
+				if res.Shared && isContextError(res.Err) && reqCtx.Err() == nil {
+					return next(ctx, args)
+				}
+				shared := res.Val.(sharedResult)
+				shared.effects.apply(ctx)
+				if res.Err != nil {
+					return nil, res.Err
+				}
+				return shared.value, nil
+			case <-reqCtx.Done():
+				return nil, reqCtx.Err()
+			}
+		}
+	}
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+func TestCoalesceMiddleware(t *testing.T) {
+	var calls atomic.Int32
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var result interface{} = "result"
+	var resultErr error
+	next := func(*rpctypes.Context, []reflect.Value) (interface{}, error) {
+		calls.Add(1)
+		entered <- struct{}{}
+		<-release
+		return result, resultErr
+	}
+	h := coalesceMiddleware()("block", next)
+	height := int64(1)
+	args := []reflect.Value{reflect.ValueOf(&height)}
+	requestCtx := func(ctx context.Context) *rpctypes.Context {
+		return &rpctypes.Context{HTTPReq: httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)}
+	}
+
+	// Concurrent calls with the same arguments share a single call.
+	var wg sync.WaitGroup
+	results := make([]interface{}, 3)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, err := h(requestCtx(context.Background()), args)
+			require.NoError(t, err)
+			results[i] = res
+		}(i)
+		if i == 0 {
+			<-entered
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	require.EqualValues(t, 1, calls.Load())
+	require.Equal(t, []interface{}{"result", "result", "result"}, results)
+
+	// Errors are not shared with later calls.
+	resultErr = errors.New("failed")
+	_, err := h(requestCtx(context.Background()), args)
+	require.EqualError(t, err, "failed")
+	<-entered
+	_, err = h(requestCtx(context.Background()), args)
+	require.EqualError(t, err, "failed")
+	<-entered
+	require.EqualValues(t, 3, calls.Load())
+}
+
+func TestCoalesceMiddlewareDeadline(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	next := func(*rpctypes.Context, []reflect.Value) (interface{}, error) {
+		close(entered)
+		<-release
+		return "result", nil
+	}
+	h := coalesceMiddleware()("block", next)
+	defer close(release)
+
+	go func() {
+		_, _ = h(nil, nil)
+	}()
+	<-entered
+
+	// A call waiting for a shared result returns when its own deadline
+	// expires.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := h(&rpctypes.Context{HTTPReq: httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)}, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestCoalesceMiddlewareEffects(t *testing.T) {
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	next := func(ctx *rpctypes.Context, _ []reflect.Value) (interface{}, error) {
+		stateFromContext(ctx).eventSink = "psql"
+		entered <- struct{}{}
+		<-release
+		return "result", nil
+	}
+	h := coalesceMiddleware()("tx_search", next)
+	args := []reflect.Value{reflect.ValueOf("tx.height=1")}
+
+	// The effects of the shared call are applied to the state of every
+	// request it serves.
+	states := []*requestState{{}, {}}
+	var wg sync.WaitGroup
+	for i, state := range states {
+		wg.Add(1)
+		go func(state *requestState) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = req.WithContext(context.WithValue(req.Context(), requestStateKey{}, state))
+			_, err := h(&rpctypes.Context{HTTPReq: req}, args)
+			require.NoError(t, err)
+		}(state)
+		if i == 0 {
+			<-entered
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	require.Equal(t, "psql", states[0].eventSink)
+	require.Equal(t, "psql", states[1].eventSink)
+}
@@ -0,0 +1,118 @@
+package rpc
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ResponseCodec encodes the responses of the Inspector in a media type of its
+// own. The routes encode their responses in JSON, which the codec selected by
+// the Accept header of a request encodes in turn.
+type ResponseCodec interface {
+	// MediaType returns the media type of the encoded responses, which the
+	// clients list in the Accept header of their requests to select the
+	// codec.
+	MediaType() string
+	// Encode encodes the JSON document of a response.
+	Encode(json []byte) ([]byte, error)
+}
+
+// ResponseCodecs registers codecs for the responses of the handler, in
+// addition to JSONCodec and CBORCodec. A codec replaces any codec previously
+// registered for its media type.
+func ResponseCodecs(codecs ...ResponseCodec) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.codecs = append(opts.codecs, codecs...)
+	}
+}
+
+// JSONCodec is the default codec, serving the JSON responses of the routes as
+// they are.
+type JSONCodec struct{}
+
+var _ ResponseCodec = JSONCodec{}
+
+// MediaType implements ResponseCodec.
+func (JSONCodec) MediaType() string { return "application/json" }
+
+// Encode implements ResponseCodec.
+func (JSONCodec) Encode(json []byte) ([]byte, error) { return json, nil }
+
+// codecRegistry maps media types to the codecs encoding them.
+type codecRegistry map[string]ResponseCodec
+
+func newCodecRegistry(codecs []ResponseCodec) codecRegistry {
+	registry := codecRegistry{}
+	for _, codec := range append([]ResponseCodec{JSONCodec{}, CBORCodec{}}, codecs...) {
+		registry[codec.MediaType()] = codec
+	}
+	return registry
+}
+
+// negotiate returns the codec of the media type the Accept header of r
+// prefers, by quality and then by order, among the registered media types. It
+// returns nil if the request accepts none of them, or only JSON.
+func (registry codecRegistry) negotiate(r *http.Request) ResponseCodec {
+	var (
+		best  ResponseCodec
+		bestQ float64
+	)
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+			codec, ok := registry[mediaType]
+			if !ok {
+				continue
+			}
+			q := 1.0
+			if v, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(v, 64); err != nil {
+					continue
+				}
+			}
+			if q > bestQ {
+				best, bestQ = codec, q
+			}
+		}
+	}
+	if _, ok := best.(JSONCodec); ok {
+		return nil
+	}
+	return best
+}
+
+// codecHandler encodes the JSON responses of h with the codec negotiated for
+// each request. The requests for which no codec other than JSONCodec is
+// negotiated are passed through to h unchanged.
+func codecHandler(h http.Handler, registry codecRegistry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		codec := registry.negotiate(r)
+		if r.Header.Get("Upgrade") != "" || codec == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept")
+
+		rec := &bufferedResponseWriter{header: make(http.Header), status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+
+		body := rec.body.Bytes()
+		if isJSON(rec.header.Get("Content-Type")) {
+			if encoded, err := codec.Encode(body); err == nil {
+				body = encoded
+				rec.header.Set("Content-Type", codec.MediaType())
+				rec.header.Del("Content-Length")
+			}
+		}
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.status)
+		w.Write(body) //nolint: errcheck
+	})
+}
@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/rpc/core"
+	rpcserver "github.com/cometbft/cometbft/rpc/jsonrpc/server"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// upperCodec is a ResponseCodec for tests, encoding the responses in upper
+// case.
+type upperCodec struct{}
+
+func (upperCodec) MediaType() string { return "application/x-upper" }
+
+func (upperCodec) Encode(json []byte) ([]byte, error) { return bytes.ToUpper(json), nil }
+
+type pong struct {
+	Reply string `json:"reply"`
+}
+
+func TestResponseCodecs(t *testing.T) {
+	routes := core.RoutesMap{
+		"ping": rpcserver.NewRPCFunc(func(*rpctypes.Context) (*pong, error) { return &pong{Reply: "pong"}, nil }, ""),
+	}
+	h := Handler(config.TestRPCConfig(), routes, log.NewNopLogger(), ResponseCodecs(upperCodec{}))
+
+	serve := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("")
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.Contains(t, rec.Body.String(), `"pong"`)
+
+	rec = serve("application/x-upper")
+	require.Equal(t, "application/x-upper", rec.Header().Get("Content-Type"))
+	require.Equal(t, "Accept", rec.Header().Get("Vary"))
+	require.Contains(t, rec.Body.String(), `"PONG"`)
+
+	// The preferred media type is selected by quality, then by order.
+	require.Equal(t, "application/json", serve("application/x-upper;q=0.5, application/json").Header().Get("Content-Type"))
+	require.Equal(t, "application/json", serve("application/json, application/x-upper").Header().Get("Content-Type"))
+	require.Equal(t, CBORMediaType, serve("text/html, "+CBORMediaType+", application/x-upper").Header().Get("Content-Type"))
+	require.Equal(t, "application/json", serve("text/html").Header().Get("Content-Type"))
+}
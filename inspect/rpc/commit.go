@@ -0,0 +1,140 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/cometbft/cometbft/libs/bits"
+	cmtmath "github.com/cometbft/cometbft/libs/math"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+// MaxCommitSignatures sets the maximum number of signatures returned in the
+// commits of the commit route. Commits with more signatures are truncated,
+// and their responses carry the number of signatures and a bitmap of the
+// validators that signed instead. A maximum of 0 omits all signatures. By
+// default, all signatures are returned.
+func MaxCommitSignatures(count int) RoutesOption {
+	return func(env *environment) {
+		env.maxCommitSignatures = &count
+	}
+}
+
+// ResultCommit is the result of the commit route. It extends the result of
+// the commit route of the node, whose fields it repeats since embedded structs
+// are not inlined by the JSON encoding of the RPC server.
+type ResultCommit struct {
+	types.SignedHeader `json:"signed_header"`
+	CanonicalCommit    bool `json:"canonical"`
+	// SignatureCount and Signers are only set if the signatures of the commit
+	// were truncated, in which case the commit no longer verifies. Signers
+	// has the bits of the validators which signed for the block set, in
+	// validator set order.
+	SignatureCount int            `json:"signature_count,omitempty"`
+	Signers        *bits.BitArray `json:"signers,omitempty"`
+}
+
+// commit serves the commit route: it returns the commit at the given height,
+// as returned by the node, with its signatures truncated to the maximum set
+// with MaxCommitSignatures.
+func (env *environment) commit(ctx *rpctypes.Context, heightPtr *int64) (*ResultCommit, error) {
+	resultCommit, err := env.Commit(ctx, heightPtr)
+	if err != nil || resultCommit == nil {
+		return nil, err
+	}
+	res := &ResultCommit{
+		SignedHeader:    resultCommit.SignedHeader,
+		CanonicalCommit: resultCommit.CanonicalCommit,
+	}
+	commit := resultCommit.Commit
+	if env.maxCommitSignatures == nil || commit == nil || len(commit.Signatures) <= *env.maxCommitSignatures {
+		return res, nil
+	}
+
+	res.SignatureCount = len(commit.Signatures)
+	res.Signers = bits.NewBitArray(len(commit.Signatures))
+	for i, sig := range commit.Signatures {
+		res.Signers.SetIndex(i, sig.BlockIDFlag == types.BlockIDFlagCommit)
+	}
+	// The commit is copied, since the block store may return cached commits.
+	truncated := *commit
+	truncated.Signatures = commit.Signatures[:*env.maxCommitSignatures:*env.maxCommitSignatures]
+	res.Commit = &truncated
+	return res, nil
+}
+
+// ResultLastCommit is the result of the last_commit route.
+type ResultLastCommit struct {
+	// Height is the height of Commit, which is null if no commit is stored.
+	Height int64 `json:"height"`
+	// LatestHeight is the height of the latest block in the block store. It
+	// is above Height if the commit for the latest block is not stored.
+	LatestHeight int64         `json:"latest_height"`
+	Commit       *types.Commit `json:"commit"`
+	// Seen is true if Commit is the commit seen by the node for the latest
+	// block, rather than a commit included in the next block.
+	Seen bool `json:"seen"`
+}
+
+// LastCommit returns the highest commit in the block store: the commit seen
+// by the node for the latest block if stored, or else the highest commit
+// included in a block, searched down to at most the maximum range span below
+// the latest height.
+func (env *environment) LastCommit(ctx *rpctypes.Context) (*ResultLastCommit, error) {
+	latest := env.BlockStore.Height()
+	res := &ResultLastCommit{LatestHeight: latest}
+	if latest == 0 {
+		return res, nil
+	}
+	if commit := env.BlockStore.LoadSeenCommit(latest); commit != nil {
+		res.Height, res.Commit, res.Seen = latest, commit, true
+		return res, nil
+	}
+	minHeight := cmtmath.MaxInt64(env.BlockStore.Base(), latest-env.maxRangeSpan)
+	for height := latest - 1; height >= minHeight; height-- {
+		if err := requestContext(ctx).Err(); err != nil {
+			return nil, err
+		}
+		if commit := env.BlockStore.LoadBlockCommit(height); commit != nil {
+			res.Height, res.Commit = height, commit
+			return res, nil
+		}
+	}
+	return res, nil
+}
+
+// ResultLastCommitInBlock is the result of the last_commit_in_block route.
+type ResultLastCommitInBlock struct {
+	// BlockHeight is the height of the block including Commit, and
+	// CommitHeight the height of the block which Commit commits: the height
+	// before BlockHeight. Commit is null for the block at the initial height,
+	// which includes no commit, in which case CommitHeight is 0.
+	BlockHeight  int64         `json:"block_height"`
+	CommitHeight int64         `json:"commit_height"`
+	Commit       *types.Commit `json:"commit"`
+}
+
+// LastCommitInBlock returns the commit included in the block at the given
+// height, or in the latest block if no height is given, as its LastCommit.
+//
+// The commit route at a height returns the commit for the block at that
+// height, which is included in the next block, while this route returns the
+// commit included in the block at the height, for the previous block. The
+// block store keeps the commits included in blocks as the commits of the
+// heights before, so both commits only differ for the latest block, whose
+// commit route returns the commit seen by the node.
+func (env *environment) LastCommitInBlock(_ *rpctypes.Context, heightPtr *int64) (*ResultLastCommitInBlock, error) {
+	height, err := env.getHeight(env.BlockStore.Height(), heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	block := env.BlockStore.LoadBlock(height)
+	if block == nil {
+		return nil, fmt.Errorf("block not found for height %d", height)
+	}
+	res := &ResultLastCommitInBlock{BlockHeight: height}
+	if block.LastCommit != nil && block.LastCommit.Height > 0 {
+		res.CommitHeight, res.Commit = block.LastCommit.Height, block.LastCommit
+	}
+	return res, nil
+}
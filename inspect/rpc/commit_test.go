@@ -0,0 +1,116 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestCommitSignatureLimit(t *testing.T) {
+	height := int64(3)
+	commit := &types.Commit{Height: height}
+	for i := 0; i < 4; i++ {
+		sig := types.CommitSig{BlockIDFlag: types.BlockIDFlagCommit, Timestamp: time.Now()}
+		if i == 1 {
+			sig = types.NewCommitSigAbsent()
+		}
+		commit.Signatures = append(commit.Signatures, sig)
+	}
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(height)
+	blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{Header: types.Header{Height: height}})
+	blockStoreMock.On("LoadSeenCommit", height).Return(commit)
+
+	// By default, all signatures are returned.
+	res, err := newTestEnvironment(blockStoreMock, &statemocks.Store{}).commit(nil, nil)
+	require.NoError(t, err)
+	require.Len(t, res.Commit.Signatures, 4)
+	require.Zero(t, res.SignatureCount)
+	require.Nil(t, res.Signers)
+
+	for _, limit := range []int{0, 2} {
+		env := newTestEnvironment(blockStoreMock, &statemocks.Store{}, MaxCommitSignatures(limit))
+		res, err = env.commit(nil, nil)
+		require.NoError(t, err)
+		require.Len(t, res.Commit.Signatures, limit)
+		require.Equal(t, 4, res.SignatureCount)
+		require.Equal(t, "BA{4:x_xx}", res.Signers.String())
+	}
+	// The stored commit is left untouched.
+	require.Len(t, commit.Signatures, 4)
+
+	// Commits within the limit are returned whole.
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{}, MaxCommitSignatures(4))
+	res, err = env.commit(nil, nil)
+	require.NoError(t, err)
+	require.Len(t, res.Commit.Signatures, 4)
+	require.Nil(t, res.Signers)
+
+	height = 4
+	res, err = env.commit(nil, &height)
+	require.Error(t, err)
+	require.Nil(t, res)
+}
+
+func TestLastCommit(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(5))
+	blockStoreMock.On("LoadSeenCommit", int64(5)).Return(nil)
+	blockStoreMock.On("LoadBlockCommit", int64(4)).Return(nil)
+	blockStoreMock.On("LoadBlockCommit", int64(3)).Return(&types.Commit{Height: 3})
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{})
+
+	// The commits for the latest blocks were not stored.
+	res, err := env.LastCommit(nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), res.Height)
+	require.Equal(t, int64(5), res.LatestHeight)
+	require.Equal(t, int64(3), res.Commit.Height)
+	require.False(t, res.Seen)
+
+	blockStoreMock = &statemocks.BlockStore{}
+	blockStoreMock.On("Height").Return(int64(5))
+	blockStoreMock.On("LoadSeenCommit", int64(5)).Return(&types.Commit{Height: 5})
+	env = newTestEnvironment(blockStoreMock, &statemocks.Store{})
+	res, err = env.LastCommit(nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), res.Height)
+	require.True(t, res.Seen)
+}
+
+func TestLastCommitInBlock(t *testing.T) {
+	lastCommit := &types.Commit{Height: 2, Round: 1}
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(3))
+	blockStoreMock.On("LoadBlock", int64(1)).Return(&types.Block{
+		Header:     types.Header{Height: 1},
+		LastCommit: &types.Commit{},
+	})
+	blockStoreMock.On("LoadBlock", int64(2)).Return(nil)
+	blockStoreMock.On("LoadBlock", int64(3)).Return(&types.Block{
+		Header:     types.Header{Height: 3},
+		LastCommit: lastCommit,
+	})
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{})
+
+	res, err := env.LastCommitInBlock(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, &ResultLastCommitInBlock{BlockHeight: 3, CommitHeight: 2, Commit: lastCommit}, res)
+
+	// The block at the initial height includes no commit.
+	height := int64(1)
+	res, err = env.LastCommitInBlock(nil, &height)
+	require.NoError(t, err)
+	require.Equal(t, &ResultLastCommitInBlock{BlockHeight: 1}, res)
+
+	height = 2
+	_, err = env.LastCommitInBlock(nil, &height)
+	require.Error(t, err)
+}
@@ -0,0 +1,175 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// CompactMediaType is the media type a client sets in the Accept header of a
+// request to receive compact responses from the bulk routes.
+//
+// A compact response omits the "jsonrpc" and "id" members of the JSON-RPC
+// response objects, keeping only "result" or "error". The responses to a batch
+// request are in the order of the requests, excluding notifications, as with
+// standard JSON-RPC batches. Requests calling any route other than the bulk
+// routes are answered with standard JSON-RPC responses.
+const CompactMediaType = "application/vnd.cometbft.compact+json"
+
+// compactRoutes are the routes returning lists of items, which may be served
+// with compact responses.
+var compactRoutes = map[string]bool{
+	"blockchain":             true,
+	"tx_search":              true,
+	"block_search":           true,
+	"txs":                    true,
+	"apphash_range":          true,
+	"block_id_range":         true,
+	"validator_hashes_range": true,
+	"tx_counts_range":        true,
+	"block_sizes_range":      true,
+	"voting_power_range":     true,
+	"participation":          true,
+	"versions_range":         true,
+	"header_hashes":          true,
+	"block_metas":            true,
+	"commit_rounds":          true,
+}
+
+// compactResponse is a JSON-RPC response without the envelope members.
+type compactResponse struct {
+	Result json.RawMessage    `json:"result,omitempty"`
+	Error  *rpctypes.RPCError `json:"error,omitempty"`
+}
+
+// compactHandler serves compact responses to the requests to the bulk routes
+// which accept CompactMediaType. All other requests are passed through to h
+// unchanged.
+func compactHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !accepts(r, CompactMediaType) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept")
+
+		methods, ok := requestMethods(r)
+		if !ok || len(methods) == 0 {
+			h.ServeHTTP(w, r)
+			return
+		}
+		for _, method := range methods {
+			if !compactRoutes[method] {
+				h.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		rec := &bufferedResponseWriter{header: make(http.Header), status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+
+		body := rec.body.Bytes()
+		if compacted, err := compactResponses(body); err == nil {
+			body = compacted
+			rec.header.Set("Content-Type", CompactMediaType)
+			rec.header.Del("Content-Length")
+		}
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.status)
+		w.Write(body) //nolint: errcheck
+	})
+}
+
+// accepts returns true if the Accept header of r lists mediaType.
+func accepts(r *http.Request, mediaType string) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			accepted, _, err := mime.ParseMediaType(mediaRange)
+			if err == nil && accepted == mediaType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requestMethods returns the methods called by r, which is either a URI
+// request or a JSON-RPC request or batch. The body of r is restored so that it
+// can be read again by the handler serving r.
+func requestMethods(r *http.Request) ([]string, bool) {
+	if r.URL.Path != "/" {
+		return []string{strings.TrimPrefix(r.URL.Path, "/")}, true
+	}
+
+	b, err := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(b), errReader{err}))
+	if err != nil {
+		return nil, false
+	}
+
+	var requests []struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(b, &requests); err != nil {
+		var request struct {
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal(b, &request); err != nil {
+			return nil, false
+		}
+		return []string{request.Method}, true
+	}
+	methods := make([]string, len(requests))
+	for i, request := range requests {
+		methods[i] = request.Method
+	}
+	return methods, true
+}
+
+// compactResponses strips the envelope members from the JSON-RPC response or
+// batch of responses in body.
+func compactResponses(body []byte) ([]byte, error) {
+	var responses []compactResponse
+	if err := json.Unmarshal(body, &responses); err == nil {
+		return json.Marshal(responses)
+	}
+	var response compactResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	return json.Marshal(response)
+}
+
+// bufferedResponseWriter records a response so that it can be rewritten
+// before being sent.
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Header() http.Header { return w.header }
+
+func (w *bufferedResponseWriter) WriteHeader(status int) { w.status = status }
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) { return w.body.Write(b) }
+
+// errReader returns err once the body read before it is consumed. A nil err
+// results in io.EOF.
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
@@ -0,0 +1,65 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestCompactResponses(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(1))
+	blockStoreMock.On("LoadBlockMeta", int64(1)).Return(&types.BlockMeta{
+		Header: types.Header{Height: 1, AppHash: []byte{1}},
+	})
+	h := newTestHandler(blockStoreMock, &statemocks.Store{})
+
+	serve := func(body string, compact bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body)))
+		if compact {
+			req.Header.Set("Accept", "application/json, "+CompactMediaType)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	batch := `[{"jsonrpc":"2.0","id":1,"method":"apphash_range","params":{}},` +
+		`{"jsonrpc":"2.0","id":2,"method":"apphash_range","params":{"minHeight":"2","maxHeight":"1"}}]`
+
+	rec := serve(batch, true)
+	require.Equal(t, CompactMediaType, rec.Header().Get("Content-Type"))
+	var responses []map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responses))
+	require.Len(t, responses, 2)
+	require.Equal(t, []string{"result"}, keys(responses[0]))
+	require.Equal(t, []string{"error"}, keys(responses[1]))
+
+	// Without the Accept header, the standard envelope is returned.
+	rec = serve(batch, false)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responses))
+	require.Contains(t, responses[0], "jsonrpc")
+	require.Contains(t, responses[0], "id")
+
+	// Requests calling other routes are not compacted.
+	rec = serve(`{"jsonrpc":"2.0","id":1,"method":"header","params":{"height":"0"}}`, true)
+	require.NotEqual(t, CompactMediaType, rec.Header().Get("Content-Type"))
+	var response map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Contains(t, response, "id")
+}
+
+func keys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,41 @@
+package rpc
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/rpc/jsonrpc/server"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// RequireJSONContentType rejects the POST requests whose Content-Type is not
+// application/json, or CompactMediaType, with a 415 status, instead of
+// parsing their body as JSON regardless. It lets operators controlling their
+// clients catch the clients which do not set the header. The requests with
+// other methods carry no body and are not checked.
+func RequireJSONContentType() HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.requireJSONContentType = true
+	}
+}
+
+// contentTypeHandler rejects the POST requests to h whose body is not
+// declared as JSON.
+func contentTypeHandler(h http.Handler, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType := r.Header.Get("Content-Type")
+		if r.Method != http.MethodPost || isJSON(contentType) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		err := fmt.Errorf("unsupported Content-Type %q, expected application/json", contentType)
+		if contentType == "" {
+			err = fmt.Errorf("missing Content-Type, expected application/json")
+		}
+		res := rpctypes.RPCInvalidRequestError(nil, err)
+		if wErr := server.WriteRPCResponseHTTPError(w, http.StatusUnsupportedMediaType, res); wErr != nil {
+			logger.Error("failed to write response", "err", wErr)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/rpc/core"
+)
+
+func TestRequireJSONContentType(t *testing.T) {
+	h := Handler(config.TestRPCConfig(), core.RoutesMap{}, log.NewNopLogger(), RequireJSONContentType())
+	serve := func(method, contentType string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"health"}`))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.NotEqual(t, http.StatusUnsupportedMediaType, serve(http.MethodPost, "application/json").Code)
+	require.NotEqual(t, http.StatusUnsupportedMediaType, serve(http.MethodPost, "application/json; charset=utf-8").Code)
+	require.NotEqual(t, http.StatusUnsupportedMediaType, serve(http.MethodPost, CompactMediaType).Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, "").Code)
+
+	rec := serve(http.MethodPost, "")
+	require.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	require.Contains(t, rec.Body.String(), "missing Content-Type")
+	rec = serve(http.MethodPost, "text/plain")
+	require.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	require.Contains(t, rec.Body.String(), `unsupported Content-Type \"text/plain\"`)
+
+	// Without the option, the body is parsed regardless.
+	h = Handler(config.TestRPCConfig(), core.RoutesMap{}, log.NewNopLogger())
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+	require.NotEqual(t, http.StatusUnsupportedMediaType, rec.Code)
+}
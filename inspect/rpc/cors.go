@@ -0,0 +1,117 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// corsRegexPrefix marks the allowed CORS origins which are regular
+// expressions, such as "regex:https://[a-z]+\.example\.com".
+const corsRegexPrefix = "regex:"
+
+// ValidateCORSOrigins returns an error if any of the allowed CORS origins is
+// an invalid pattern. Besides exact origins and "*", the Inspector accepts
+// wildcard origins such as "https://*.example.com", where each * stands for
+// one or more characters of the host, and regular expressions prefixed with
+// "regex:", which must match the whole origin, in lower case. Wildcard origins
+// without a scheme, such as "*.example.com", match any scheme.
+func ValidateCORSOrigins(origins []string) error {
+	_, err := newOriginMatcher(origins)
+	return err
+}
+
+// hasOriginPatterns returns whether any of origins is a wildcard or regular
+// expression pattern, other than "*".
+func hasOriginPatterns(origins []string) bool {
+	for _, origin := range origins {
+		if origin != "*" && (strings.Contains(origin, "*") || strings.HasPrefix(origin, corsRegexPrefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// originMatcher matches the origins of requests against the allowed CORS
+// origins.
+type originMatcher struct {
+	any      bool
+	exact    map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// newOriginMatcher returns the matcher of the allowed origins. The invalid
+// origins are left out of the matcher and reported in the error.
+func newOriginMatcher(origins []string) (*originMatcher, error) {
+	m := &originMatcher{exact: make(map[string]bool)}
+	var errs []error
+	for _, origin := range origins {
+		switch {
+		case origin == "*":
+			m.any = true
+		case strings.HasPrefix(origin, corsRegexPrefix):
+			re, err := regexp.Compile("^(?:" + strings.TrimPrefix(origin, corsRegexPrefix) + ")$")
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid CORS origin %q: %w", origin, err))
+				continue
+			}
+			m.patterns = append(m.patterns, re)
+		case strings.Contains(origin, "*"):
+			re, err := wildcardOrigin(origin)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid CORS origin %q: %w", origin, err))
+				continue
+			}
+			m.patterns = append(m.patterns, re)
+		default:
+			m.exact[strings.ToLower(origin)] = true
+		}
+	}
+	return m, errors.Join(errs...)
+}
+
+// wildcardOrigin compiles a wildcard origin into a regular expression.
+func wildcardOrigin(origin string) (*regexp.Regexp, error) {
+	scheme, host, ok := strings.Cut(strings.ToLower(origin), "://")
+	if !ok {
+		scheme, host = "", scheme
+	}
+	switch {
+	case strings.Contains(scheme, "*"):
+		return nil, errors.New("the scheme cannot contain a wildcard")
+	case host == "" || strings.Contains(host, "/"):
+		return nil, errors.New("an origin is a scheme, a host and an optional port")
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if scheme == "" {
+		expr.WriteString("[a-z][a-z0-9+.-]*")
+	} else {
+		expr.WriteString(regexp.QuoteMeta(scheme))
+	}
+	expr.WriteString("://")
+	for i, part := range strings.Split(host, "*") {
+		if i > 0 {
+			expr.WriteString("[^/:]+")
+		}
+		expr.WriteString(regexp.QuoteMeta(part))
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+// allowed returns whether origin is allowed.
+func (m *originMatcher) allowed(origin string) bool {
+	origin = strings.ToLower(origin)
+	if m.any || m.exact[origin] {
+		return true
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+)
+
+func TestOriginMatcher(t *testing.T) {
+	m, err := newOriginMatcher([]string{
+		"https://app.example.org",
+		"*.example.com",
+		"http://*.test:8080",
+		`regex:https://explorer-[0-9]+\.example\.net`,
+	})
+	require.NoError(t, err)
+
+	for origin, allowed := range map[string]bool{
+		"https://app.example.org":                 true,
+		"HTTPS://APP.EXAMPLE.ORG":                 true,
+		"https://other.example.org":               false,
+		"https://a.example.com":                   true,
+		"http://a.b.example.com":                  true,
+		"https://example.com":                     false,
+		"https://a.example.com:443":               false,
+		"https://example.com.evil.org":            false,
+		"http://local.test:8080":                  true,
+		"https://local.test:8080":                 false,
+		"https://explorer-12.example.net":         true,
+		"https://explorer-x.example.net":          false,
+		"https://explorer-1.example.net.evil.org": false,
+	} {
+		require.Equal(t, allowed, m.allowed(origin), origin)
+	}
+}
+
+func TestValidateCORSOrigins(t *testing.T) {
+	require.NoError(t, ValidateCORSOrigins([]string{"*", "https://a.org", "https://*.a.org", "regex:https://.*"}))
+
+	err := ValidateCORSOrigins([]string{"http*://a.org", "https://*.a.org/path", "regex:(", "https://b.org"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `"http*://a.org"`)
+	require.Contains(t, err.Error(), `"https://*.a.org/path"`)
+	require.Contains(t, err.Error(), `"regex:("`)
+	require.NotContains(t, err.Error(), "b.org\"")
+}
+
+func TestCORSOriginPatterns(t *testing.T) {
+	cfg := config.DefaultRPCConfig()
+	cfg.CORSAllowedOrigins = []string{"https://*.example.com"}
+	check := func(origins map[string]bool) {
+		h := addCORSHandler(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), log.NewNopLogger())
+		for origin, allowed := range origins {
+			req := httptest.NewRequest(http.MethodGet, "/status", nil)
+			req.Header.Set("Origin", origin)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if allowed {
+				require.Equal(t, origin, rec.Header().Get("Access-Control-Allow-Origin"))
+			} else {
+				require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+			}
+		}
+	}
+
+	check(map[string]bool{
+		"https://app.example.com": true,
+		"https://example.org":     false,
+	})
+
+	// Invalid origins refuse all origins, the valid ones included.
+	cfg.CORSAllowedOrigins = []string{"https://*.example.com", "regex:("}
+	check(map[string]bool{
+		"https://app.example.com": false,
+		"https://example.org":     false,
+	})
+}
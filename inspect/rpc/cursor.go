@@ -0,0 +1,122 @@
+package rpc
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// codeCursorExpired is the JSON-RPC error code returned for the cursors used
+// after their expiry.
+const codeCursorExpired = -32005
+
+// CursorKey sets the key signing the cursors returned by the paginated
+// routes, such as block_metas. Servers sharing a key accept the cursors of
+// each other, so that clients may resume paging on any instance. By default,
+// a random key is generated on start, and cursors are only accepted by the
+// server which returned them.
+func CursorKey(key []byte) RoutesOption {
+	return func(env *environment) {
+		env.cursorKey = key
+	}
+}
+
+// CursorTTL sets how long the cursors returned by the paginated routes are
+// accepted for. Expired cursors are rejected with the "Cursor expired" error,
+// and paging must be restarted. By default, cursors do not expire.
+func CursorTTL(ttl time.Duration) RoutesOption {
+	return func(env *environment) {
+		env.cursorTTL = ttl
+	}
+}
+
+// pageCursor is the position in the results of a paginated route from which
+// paging continues. It is encoded in the cursors returned to clients as JSON,
+// followed by its signature.
+type pageCursor struct {
+	Route     string `json:"route"`
+	MinHeight int64  `json:"min_height"`
+	MaxHeight int64  `json:"max_height"`
+	Fields    string `json:"fields,omitempty"`
+	// Expires is the Unix time in seconds after which the cursor is
+	// rejected, or 0 if it does not expire.
+	Expires int64 `json:"expires,omitempty"`
+}
+
+// initCursorKey generates the random key signing the cursors if none is set.
+func (env *environment) initCursorKey() error {
+	if len(env.cursorKey) > 0 {
+		return nil
+	}
+	env.cursorKey = make([]byte, sha256.Size)
+	_, err := rand.Read(env.cursorKey)
+	return err
+}
+
+// encodeCursor returns the signed cursor of c, which expires after the TTL
+// set by CursorTTL.
+func (env *environment) encodeCursor(c pageCursor) (string, error) {
+	if env.cursorTTL > 0 {
+		c.Expires = time.Now().Add(env.cursorTTL).Unix()
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	encoding := base64.RawURLEncoding
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(env.signCursor(payload)), nil
+}
+
+// decodeCursor returns the cursor of route encoded in token. It fails if the
+// signature of the cursor does not match, if the cursor is for another
+// route, or if it expired.
+func (env *environment) decodeCursor(route, token string) (pageCursor, error) {
+	var c pageCursor
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return c, invalidCursorError("malformed cursor")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return c, invalidCursorError("malformed cursor")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, env.signCursor(payload)) {
+		return c, invalidCursorError("cursor signature mismatch")
+	}
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return c, invalidCursorError("malformed cursor")
+	}
+	if c.Route != route {
+		return c, invalidCursorError(fmt.Sprintf("cursor of route %s", c.Route))
+	}
+	if c.Expires > 0 && time.Now().Unix() > c.Expires {
+		return c, &rpctypes.RPCError{
+			Code:    codeCursorExpired,
+			Message: "Cursor expired",
+			Data:    fmt.Sprintf("cursor expired at %s; restart paging", time.Unix(c.Expires, 0).UTC()),
+		}
+	}
+	return c, nil
+}
+
+func (env *environment) signCursor(payload []byte) []byte {
+	mac := hmac.New(sha256.New, env.cursorKey)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func invalidCursorError(reason string) error {
+	return &rpctypes.RPCError{
+		Code:    codeInvalidParams,
+		Message: "Invalid params",
+		Data:    "invalid cursor: " + reason,
+	}
+}
@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+)
+
+func TestBlockMetasCursor(t *testing.T) {
+	env := newTestEnvironment(blockMetasStore(1, 5), &statemocks.Store{}, MaxBlockMetasSpan(3))
+
+	res, err := env.BlockMetas(nil, 0, 0, "num_txs", "")
+	require.NoError(t, err)
+	require.Len(t, res.BlockMetas, 3)
+	require.JSONEq(t, `{"height":"3","num_txs":"3"}`, string(res.BlockMetas[0]))
+	require.NotEmpty(t, res.NextCursor)
+
+	// The cursor returns the heights below the page, with the same fields.
+	res, err = env.BlockMetas(nil, 0, 0, "", res.NextCursor)
+	require.NoError(t, err)
+	require.Len(t, res.BlockMetas, 2)
+	require.JSONEq(t, `{"height":"1","num_txs":"1"}`, string(res.BlockMetas[0]))
+	require.Empty(t, res.NextCursor)
+
+	res, err = env.BlockMetas(nil, 3, 5, "", "")
+	require.NoError(t, err)
+	require.Empty(t, res.NextCursor)
+}
+
+func TestCursorSignature(t *testing.T) {
+	key := []byte("shared key")
+	env := newTestEnvironment(&statemocks.BlockStore{}, &statemocks.Store{}, CursorKey(key))
+	cursor, err := env.encodeCursor(pageCursor{Route: "blockchain", MinHeight: 1, MaxHeight: 2})
+	require.NoError(t, err)
+
+	// Servers sharing the key accept the cursors of each other.
+	other := newTestEnvironment(&statemocks.BlockStore{}, &statemocks.Store{}, CursorKey(key))
+	c, err := other.decodeCursor("blockchain", cursor)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), c.MaxHeight)
+
+	// By default, each server signs with a key of its own.
+	other = newTestEnvironment(&statemocks.BlockStore{}, &statemocks.Store{})
+	_, err = other.decodeCursor("blockchain", cursor)
+	require.Equal(t, codeInvalidParams, err.(*rpctypes.RPCError).Code)
+
+	tampered := []byte(cursor)
+	tampered[1] ^= 1
+	_, err = env.decodeCursor("blockchain", string(tampered))
+	require.Equal(t, codeInvalidParams, err.(*rpctypes.RPCError).Code)
+
+	_, err = env.decodeCursor("blockchain", "bogus")
+	require.Equal(t, codeInvalidParams, err.(*rpctypes.RPCError).Code)
+
+	_, err = env.decodeCursor("validators", cursor)
+	require.Equal(t, codeInvalidParams, err.(*rpctypes.RPCError).Code)
+}
+
+func TestCursorExpiry(t *testing.T) {
+	env := newTestEnvironment(&statemocks.BlockStore{}, &statemocks.Store{}, CursorTTL(time.Hour))
+	cursor, err := env.encodeCursor(pageCursor{Route: "blockchain", MinHeight: 1, MaxHeight: 2})
+	require.NoError(t, err)
+	c, err := env.decodeCursor("blockchain", cursor)
+	require.NoError(t, err)
+	require.Greater(t, c.Expires, time.Now().Unix())
+
+	env.cursorTTL = 0
+	cursor, err = env.encodeCursor(pageCursor{
+		Route:     "blockchain",
+		MinHeight: 1,
+		MaxHeight: 2,
+		Expires:   time.Now().Add(-time.Minute).Unix(),
+	})
+	require.NoError(t, err)
+	_, err = env.decodeCursor("blockchain", cursor)
+	require.Equal(t, codeCursorExpired, err.(*rpctypes.RPCError).Code)
+	require.Equal(t, "Cursor expired", err.(*rpctypes.RPCError).Message)
+}
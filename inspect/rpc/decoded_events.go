@@ -0,0 +1,105 @@
+package rpc
+
+import (
+	"encoding/base64"
+	"unicode/utf8"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// encodingBase64 is the encoding reported for the attribute keys and values
+// which are not valid UTF-8.
+const encodingBase64 = "base64"
+
+// DecodedAttribute is an event attribute whose key and value are readable
+// strings. The keys and values which are valid UTF-8 are returned as they
+// are, while the others are base64 encoded, which KeyEncoding and
+// ValueEncoding report. Such attributes are emitted by applications storing
+// binary data in events, which JSON cannot carry losslessly.
+type DecodedAttribute struct {
+	Key           string `json:"key"`
+	Value         string `json:"value"`
+	KeyEncoding   string `json:"key_encoding,omitempty"`
+	ValueEncoding string `json:"value_encoding,omitempty"`
+	Index         bool   `json:"index"`
+}
+
+// DecodedEvent is an event whose attributes are decoded.
+type DecodedEvent struct {
+	// TxIndex is the index, in the block, of the transaction which emitted the
+	// event, or null for the events emitted by the block itself.
+	TxIndex    *int               `json:"tx_index"`
+	Attributes []DecodedAttribute `json:"attributes"`
+}
+
+// DecodedEventGroup holds the events of a type.
+type DecodedEventGroup struct {
+	Type   string         `json:"type"`
+	Events []DecodedEvent `json:"events"`
+}
+
+// ResultDecodedEvents is the result of the decoded_events route.
+type ResultDecodedEvents struct {
+	Height     int64               `json:"height"`
+	EventTypes []DecodedEventGroup `json:"event_types"`
+}
+
+// DecodedEvents returns the events emitted while executing the block at the
+// given height, or the latest block if no height is given, with their
+// attributes decoded for reading, see DecodedAttribute. The events are grouped
+// by type, in the order in which each type was first emitted, and are in the
+// order of the events route within a group.
+func (env *environment) DecodedEvents(_ *rpctypes.Context, heightPtr *int64) (*ResultDecodedEvents, error) {
+	height, err := env.getHeight(env.BlockStore.Height(), heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	results, err := env.StateStore.LoadFinalizeBlockResponse(height)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ResultDecodedEvents{Height: height, EventTypes: []DecodedEventGroup{}}
+	groups := make(map[string]int)
+	appendEvents := func(txIndex *int, events []abci.Event) {
+		for _, event := range events {
+			i, ok := groups[event.Type]
+			if !ok {
+				i = len(res.EventTypes)
+				groups[event.Type] = i
+				res.EventTypes = append(res.EventTypes, DecodedEventGroup{Type: event.Type})
+			}
+			decoded := DecodedEvent{
+				TxIndex:    txIndex,
+				Attributes: make([]DecodedAttribute, len(event.Attributes)),
+			}
+			for j, attr := range event.Attributes {
+				decoded.Attributes[j] = decodeAttribute(attr)
+			}
+			res.EventTypes[i].Events = append(res.EventTypes[i].Events, decoded)
+		}
+	}
+	appendEvents(nil, results.Events)
+	for i, txResult := range results.TxResults {
+		if txResult == nil {
+			continue
+		}
+		txIndex := i
+		appendEvents(&txIndex, txResult.Events)
+	}
+	return res, nil
+}
+
+func decodeAttribute(attr abci.EventAttribute) DecodedAttribute {
+	decoded := DecodedAttribute{Key: attr.Key, Value: attr.Value, Index: attr.Index}
+	if !utf8.ValidString(attr.Key) {
+		decoded.Key = base64.StdEncoding.EncodeToString([]byte(attr.Key))
+		decoded.KeyEncoding = encodingBase64
+	}
+	if !utf8.ValidString(attr.Value) {
+		decoded.Value = base64.StdEncoding.EncodeToString([]byte(attr.Value))
+		decoded.ValueEncoding = encodingBase64
+	}
+	return decoded
+}
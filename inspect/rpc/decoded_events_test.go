@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+)
+
+func TestDecodedEvents(t *testing.T) {
+	transfer := abcitypes.Event{
+		Type: "transfer",
+		Attributes: []abcitypes.EventAttribute{
+			{Key: "amount", Value: "1", Index: true},
+			{Key: "memo\xff", Value: "\x00\x01\xfe"},
+		},
+	}
+	reward := abcitypes.Event{Type: "reward"}
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(2))
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("LoadFinalizeBlockResponse", int64(2)).Return(&abcitypes.ResponseFinalizeBlock{
+		Events: []abcitypes.Event{reward},
+		TxResults: []*abcitypes.ExecTxResult{
+			{Events: []abcitypes.Event{transfer, reward}},
+			nil,
+			{Events: []abcitypes.Event{transfer}},
+		},
+	}, nil)
+	env := newTestEnvironment(blockStoreMock, stateStoreMock)
+
+	res, err := env.DecodedEvents(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res.Height)
+	require.Len(t, res.EventTypes, 2)
+
+	require.Equal(t, "reward", res.EventTypes[0].Type)
+	require.Len(t, res.EventTypes[0].Events, 2)
+	require.Nil(t, res.EventTypes[0].Events[0].TxIndex)
+	require.Equal(t, 0, *res.EventTypes[0].Events[1].TxIndex)
+
+	require.Equal(t, "transfer", res.EventTypes[1].Type)
+	require.Len(t, res.EventTypes[1].Events, 2)
+	require.Equal(t, 0, *res.EventTypes[1].Events[0].TxIndex)
+	require.Equal(t, 2, *res.EventTypes[1].Events[1].TxIndex)
+	require.Equal(t, []DecodedAttribute{
+		{Key: "amount", Value: "1", Index: true},
+		{Key: "bWVtb/8=", Value: "AAH+", KeyEncoding: "base64", ValueEncoding: "base64"},
+	}, res.EventTypes[1].Events[0].Attributes)
+}
@@ -0,0 +1,93 @@
+package rpc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/rpc/jsonrpc/server"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// defaultMaxJSONDepth is the default maximum nesting depth of the JSON
+// documents of the requests.
+const defaultMaxJSONDepth = 64
+
+// MaxJSONDepth sets the maximum nesting depth of the arrays and objects of the
+// JSON-RPC requests, including the batches, and of the params of the URI
+// requests. Requests nested deeper are rejected with an invalid request error
+// before being decoded. It defaults to 64. A depth of 0 disables the limit.
+//
+// The messages received over websocket connections are not checked.
+func MaxJSONDepth(depth int) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.maxJSONDepth = depth
+	}
+}
+
+// jsonDepthHandler rejects the requests to h whose JSON body or URI params
+// are nested deeper than maxDepth.
+func jsonDepthHandler(h http.Handler, maxDepth int, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		if r.Method == http.MethodGet {
+			for _, values := range r.URL.Query() {
+				for _, v := range values {
+					if err = checkJSONDepth([]byte(v), maxDepth); err != nil {
+						break
+					}
+				}
+			}
+		} else if r.Body != nil {
+			body, readErr := io.ReadAll(r.Body)
+			r.Body.Close()
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			if readErr != nil {
+				err = fmt.Errorf("error reading request body: %w", readErr)
+			} else {
+				err = checkJSONDepth(body, maxDepth)
+			}
+		}
+		if err != nil {
+			res := rpctypes.RPCInvalidRequestError(nil, err)
+			if wErr := server.WriteRPCResponseHTTPError(w, http.StatusBadRequest, res); wErr != nil {
+				logger.Error("failed to write response", "err", wErr)
+			}
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// checkJSONDepth returns an error if the arrays and objects of the JSON
+// document b are nested deeper than maxDepth. The document is scanned without
+// being decoded, so malformed documents are left to the decoder to report.
+func checkJSONDepth(b []byte, maxDepth int) error {
+	depth := 0
+	inString, escaped := false, false
+	for _, c := range b {
+		switch {
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '[' || c == '{':
+			depth++
+			if depth > maxDepth {
+				return fmt.Errorf("maximum JSON nesting depth of %d exceeded", maxDepth)
+			}
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return nil
+}
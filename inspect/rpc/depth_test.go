@@ -0,0 +1,46 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/rpc/core"
+)
+
+func TestCheckJSONDepth(t *testing.T) {
+	require.NoError(t, checkJSONDepth([]byte(`{"a":[1,{"b":[]}]}`), 4))
+	require.Error(t, checkJSONDepth([]byte(`{"a":[1,{"b":[]}]}`), 3))
+	// Brackets within strings are not counted.
+	require.NoError(t, checkJSONDepth([]byte(`{"a":"[[[{\"[[["}`), 1))
+}
+
+func TestMaxJSONDepth(t *testing.T) {
+	h := Handler(config.TestRPCConfig(), core.RoutesMap{}, log.NewNopLogger(), MaxJSONDepth(4))
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	params := strings.Repeat("[", 3) + strings.Repeat("]", 3)
+	body := `{"jsonrpc":"2.0","id":1,"method":"health","params":` + params + `}`
+	rec := serve(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	require.NotContains(t, rec.Body.String(), "nesting depth")
+
+	params = strings.Repeat("[", 4) + strings.Repeat("]", 4)
+	body = `{"jsonrpc":"2.0","id":1,"method":"health","params":` + params + `}`
+	rec = serve(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "maximum JSON nesting depth of 4 exceeded")
+
+	rec = serve(httptest.NewRequest(http.MethodGet, "/health?x="+url.QueryEscape(strings.Repeat("{", 5)), nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "maximum JSON nesting depth of 4 exceeded")
+}
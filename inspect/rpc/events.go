@@ -0,0 +1,81 @@
+package rpc
+
+import (
+	abci "github.com/cometbft/cometbft/abci/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// BlockEvent is an event emitted while executing a block.
+type BlockEvent struct {
+	// TxIndex is the index, in the block, of the transaction which emitted the
+	// event, or null for the events emitted by the block itself.
+	TxIndex    *int                  `json:"tx_index"`
+	Type       string                `json:"type"`
+	Attributes []abci.EventAttribute `json:"attributes"`
+}
+
+// ResultEvents is the result of the events route.
+type ResultEvents struct {
+	Height     int64        `json:"height"`
+	Events     []BlockEvent `json:"events"`
+	TotalCount int          `json:"total_count"`
+}
+
+// Events returns the events emitted while executing the block at the given
+// height, or the latest block if no height is given: first the events of the
+// block, then those of its transactions, in order. For blocks executed by
+// earlier versions of the node, the events of the block include both the
+// begin block and end block events. If eventType is not empty, only the
+// events of this type are returned.
+//
+// All the events are returned unless a page or a number of events per page is
+// given, in which case they are paginated as in tx_search.
+func (env *environment) Events(
+	_ *rpctypes.Context,
+	heightPtr *int64,
+	eventType string,
+	pagePtr, perPagePtr *int,
+) (*ResultEvents, error) {
+	height, err := env.getHeight(env.BlockStore.Height(), heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	results, err := env.StateStore.LoadFinalizeBlockResponse(height)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ResultEvents{Height: height, Events: []BlockEvent{}}
+	appendEvents := func(txIndex *int, events []abci.Event) {
+		for _, event := range events {
+			if eventType != "" && event.Type != eventType {
+				continue
+			}
+			res.Events = append(res.Events, BlockEvent{
+				TxIndex:    txIndex,
+				Type:       event.Type,
+				Attributes: event.Attributes,
+			})
+		}
+	}
+	appendEvents(nil, results.Events)
+	for i, txResult := range results.TxResults {
+		if txResult == nil {
+			continue
+		}
+		txIndex := i
+		appendEvents(&txIndex, txResult.Events)
+	}
+
+	res.TotalCount = len(res.Events)
+	if pagePtr != nil || perPagePtr != nil {
+		perPage := validatePerPage(perPagePtr)
+		page, err := validatePage(pagePtr, perPage, res.TotalCount)
+		if err != nil {
+			return nil, err
+		}
+		start := (page - 1) * perPage
+		res.Events = res.Events[start:min(start+perPage, res.TotalCount)]
+	}
+	return res, nil
+}
@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+)
+
+func TestEvents(t *testing.T) {
+	transfer := abcitypes.Event{
+		Type:       "transfer",
+		Attributes: []abcitypes.EventAttribute{{Key: "amount", Value: "1", Index: true}},
+	}
+	reward := abcitypes.Event{Type: "reward"}
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(2))
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("LoadFinalizeBlockResponse", int64(2)).Return(&abcitypes.ResponseFinalizeBlock{
+		Events: []abcitypes.Event{reward},
+		TxResults: []*abcitypes.ExecTxResult{
+			{Events: []abcitypes.Event{transfer, reward}},
+			{},
+			{Events: []abcitypes.Event{transfer}},
+		},
+	}, nil)
+	env := newTestEnvironment(blockStoreMock, stateStoreMock)
+
+	res, err := env.Events(nil, nil, "", nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res.Height)
+	require.Len(t, res.Events, 4)
+	require.Nil(t, res.Events[0].TxIndex)
+	require.Equal(t, "reward", res.Events[0].Type)
+	for i, txIndex := range []int{0, 0, 2} {
+		require.Equal(t, txIndex, *res.Events[i+1].TxIndex)
+	}
+	require.Equal(t, transfer.Attributes, res.Events[1].Attributes)
+
+	res, err = env.Events(nil, nil, "transfer", nil, nil)
+	require.NoError(t, err)
+	require.Len(t, res.Events, 2)
+	require.Equal(t, 0, *res.Events[0].TxIndex)
+	require.Equal(t, 2, *res.Events[1].TxIndex)
+
+	page, perPage := 2, 3
+	res, err = env.Events(nil, nil, "", &page, &perPage)
+	require.NoError(t, err)
+	require.Equal(t, 4, res.TotalCount)
+	require.Len(t, res.Events, 1)
+	require.Equal(t, 2, *res.Events[0].TxIndex)
+
+	res, err = env.Events(nil, nil, "unknown", nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, res.Events)
+}
@@ -0,0 +1,69 @@
+package rpc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+// BlockEvidence is an evidence of byzantine behavior committed in a block, as
+// returned by the evidence route.
+type BlockEvidence struct {
+	// Type is "duplicate_vote" or "light_client_attack".
+	Type string         `json:"type"`
+	Hash bytes.HexBytes `json:"hash"`
+	// Height and Time are those of the misbehavior, not of the block.
+	Height   int64          `json:"height"`
+	Time     time.Time      `json:"time"`
+	Evidence types.Evidence `json:"evidence"`
+}
+
+// ResultEvidence is the result of the evidence route.
+type ResultEvidence struct {
+	Height   int64           `json:"height"`
+	Evidence []BlockEvidence `json:"evidence"`
+}
+
+// Evidence returns the evidence committed in the block at the given height, or
+// in the latest block if no height is given. The list is empty if the block
+// holds no evidence.
+func (env *environment) Evidence(_ *rpctypes.Context, heightPtr *int64) (*ResultEvidence, error) {
+	height, err := env.getHeight(env.BlockStore.Height(), heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	block := env.BlockStore.LoadBlock(height)
+	if block == nil {
+		return nil, fmt.Errorf("block at height %d not found", height)
+	}
+
+	res := &ResultEvidence{
+		Height:   height,
+		Evidence: make([]BlockEvidence, len(block.Evidence.Evidence)),
+	}
+	for i, ev := range block.Evidence.Evidence {
+		res.Evidence[i] = BlockEvidence{
+			Type:     evidenceType(ev),
+			Hash:     ev.Hash(),
+			Height:   ev.Height(),
+			Time:     ev.Time(),
+			Evidence: ev,
+		}
+	}
+	return res, nil
+}
+
+// evidenceType returns the name of the type of ev.
+func evidenceType(ev types.Evidence) string {
+	switch ev.(type) {
+	case *types.DuplicateVoteEvidence:
+		return "duplicate_vote"
+	case *types.LightClientAttackEvidence:
+		return "light_client_attack"
+	default:
+		return fmt.Sprintf("%T", ev)
+	}
+}
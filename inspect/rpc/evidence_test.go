@@ -0,0 +1,44 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestEvidence(t *testing.T) {
+	ev, err := types.NewMockDuplicateVoteEvidence(1, time.Now(), "test-chain")
+	require.NoError(t, err)
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(3))
+	blockStoreMock.On("LoadBlock", int64(2)).Return(&types.Block{
+		Evidence: types.EvidenceData{Evidence: types.EvidenceList{ev}},
+	})
+	blockStoreMock.On("LoadBlock", int64(3)).Return(&types.Block{})
+	h := newTestHandler(blockStoreMock, &statemocks.Store{})
+
+	res := callJSONRPC(t, h, "evidence", `{"height":"2"}`)
+	require.Nil(t, res.Error)
+	var result ResultEvidence
+	require.NoError(t, cmtjson.Unmarshal(res.Result, &result))
+	require.Equal(t, int64(2), result.Height)
+	require.Len(t, result.Evidence, 1)
+	require.Equal(t, "duplicate_vote", result.Evidence[0].Type)
+	require.Equal(t, ev.Hash(), []byte(result.Evidence[0].Hash))
+	require.Equal(t, int64(1), result.Evidence[0].Height)
+	require.Equal(t, ev.Hash(), result.Evidence[0].Evidence.Hash())
+
+	// Blocks without evidence have an empty list.
+	res = callJSONRPC(t, h, "evidence", `{}`)
+	require.Nil(t, res.Error)
+	require.JSONEq(t, `{"height":"3","evidence":[]}`, string(res.Result))
+
+	res = callJSONRPC(t, h, "evidence", `{"height":"4"}`)
+	require.NotNil(t, res.Error)
+}
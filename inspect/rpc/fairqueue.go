@@ -0,0 +1,161 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/log"
+	cmtsync "github.com/cometbft/cometbft/libs/sync"
+	"github.com/cometbft/cometbft/rpc/jsonrpc/server"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// fairQueueRetryAfter is the delay after which clients are asked to retry
+// requests rejected because the queue is full.
+const fairQueueRetryAfter = time.Second
+
+var errQueueFull = errors.New("too many queued requests")
+
+// FairQueue limits the number of requests served at once to maxConcurrent.
+// Requests beyond the limit wait in a queue, from which they are served
+// round-robin across the clients, as identified by ClientIP, rather than in
+// arrival order, so that a client sending many requests does not starve the
+// others. Once maxQueued requests are waiting, further requests are rejected
+// with a 503 status. Websocket connections are not limited.
+func FairQueue(maxConcurrent, maxQueued int) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.fairQueue = &fairQueue{
+			maxActive: maxConcurrent,
+			maxQueued: maxQueued,
+			waiters:   make(map[netip.Addr][]chan struct{}),
+		}
+	}
+}
+
+// fairQueueHandler serves the requests to h through q.
+func fairQueueHandler(h http.Handler, q *fairQueue, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/websocket" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		client, _ := ClientIP(r.Context())
+		if err := q.acquire(r.Context(), client); err != nil {
+			if errors.Is(err, errQueueFull) {
+				w.Header().Set("Retry-After", retryAfterSeconds(fairQueueRetryAfter))
+				res := rpctypes.NewRPCErrorResponse(nil, codeServiceUnavailable, "Service temporarily unavailable",
+					fmt.Sprintf("%v; retry after %s seconds", err, retryAfterSeconds(fairQueueRetryAfter)))
+				if wErr := server.WriteRPCResponseHTTPError(w, http.StatusServiceUnavailable, res); wErr != nil {
+					logger.Error("failed to write response", "err", wErr)
+				}
+			}
+			return
+		}
+		defer q.release()
+		h.ServeHTTP(w, r)
+	})
+}
+
+// fairQueue is a semaphore whose waiters are granted the semaphore
+// round-robin across clients.
+type fairQueue struct {
+	maxActive int
+	maxQueued int
+
+	mtx     cmtsync.Mutex
+	active  int
+	queued  int
+	waiters map[netip.Addr][]chan struct{}
+	// clients are the clients with waiters, in the order in which they are
+	// next granted the semaphore.
+	clients []netip.Addr
+}
+
+// acquire waits until client is granted the semaphore or ctx is done.
+func (q *fairQueue) acquire(ctx context.Context, client netip.Addr) error {
+	q.mtx.Lock()
+	if q.active < q.maxActive && q.queued == 0 {
+		q.active++
+		q.mtx.Unlock()
+		return nil
+	}
+	if q.queued >= q.maxQueued {
+		q.mtx.Unlock()
+		return errQueueFull
+	}
+	ready := make(chan struct{})
+	if len(q.waiters[client]) == 0 {
+		q.clients = append(q.clients, client)
+	}
+	q.waiters[client] = append(q.waiters[client], ready)
+	q.queued++
+	q.mtx.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		q.mtx.Lock()
+		defer q.mtx.Unlock()
+		select {
+		case <-ready:
+			// The semaphore was granted concurrently; pass it on.
+			q.releaseLocked()
+		default:
+			q.removeLocked(client, ready)
+		}
+		return ctx.Err()
+	}
+}
+
+// release releases the semaphore, granting it to the next waiter if any.
+func (q *fairQueue) release() {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	q.releaseLocked()
+}
+
+func (q *fairQueue) releaseLocked() {
+	if q.queued == 0 {
+		q.active--
+		return
+	}
+	client := q.clients[0]
+	q.clients = q.clients[1:]
+	waiters := q.waiters[client]
+	ready := waiters[0]
+	if len(waiters) > 1 {
+		q.waiters[client] = waiters[1:]
+		q.clients = append(q.clients, client)
+	} else {
+		delete(q.waiters, client)
+	}
+	q.queued--
+	close(ready)
+}
+
+func (q *fairQueue) removeLocked(client netip.Addr, ready chan struct{}) {
+	waiters := q.waiters[client]
+	for i, w := range waiters {
+		if w == ready {
+			waiters = append(waiters[:i:i], waiters[i+1:]...)
+			break
+		}
+	}
+	q.queued--
+	if len(waiters) > 0 {
+		q.waiters[client] = waiters
+		return
+	}
+	delete(q.waiters, client)
+	for i, c := range q.clients {
+		if c == client {
+			q.clients = append(q.clients[:i:i], q.clients[i+1:]...)
+			break
+		}
+	}
+}
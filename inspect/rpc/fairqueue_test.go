@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFairQueue(t *testing.T) {
+	q := &fairQueue{maxActive: 1, maxQueued: 4, waiters: make(map[netip.Addr][]chan struct{})}
+	aggressive, other := netip.MustParseAddr("192.0.2.1"), netip.MustParseAddr("192.0.2.2")
+	require.NoError(t, q.acquire(context.Background(), aggressive))
+
+	granted := make(chan string, 4)
+	enqueue := func(name string, client netip.Addr) {
+		queued := q.queued
+		go func() {
+			require.NoError(t, q.acquire(context.Background(), client))
+			granted <- name
+		}()
+		require.Eventually(t, func() bool {
+			q.mtx.Lock()
+			defer q.mtx.Unlock()
+			return q.queued == queued+1
+		}, time.Second, time.Millisecond)
+	}
+	enqueue("a1", aggressive)
+	enqueue("a2", aggressive)
+	enqueue("a3", aggressive)
+	enqueue("b1", other)
+
+	// The queue is full.
+	require.ErrorIs(t, q.acquire(context.Background(), other), errQueueFull)
+
+	// The other client is served before the rest of the aggressive client's
+	// requests.
+	var order []string
+	for i := 0; i < 4; i++ {
+		q.release()
+		order = append(order, <-granted)
+	}
+	require.Equal(t, []string{"a1", "b1", "a2", "a3"}, order)
+
+	q.release()
+	require.Equal(t, 0, q.active)
+}
+
+func TestFairQueueCanceled(t *testing.T) {
+	q := &fairQueue{maxActive: 1, maxQueued: 1, waiters: make(map[netip.Addr][]chan struct{})}
+	client := netip.MustParseAddr("192.0.2.1")
+	require.NoError(t, q.acquire(context.Background(), client))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, q.acquire(ctx, client), context.DeadlineExceeded)
+	require.Equal(t, 0, q.queued)
+	require.Empty(t, q.clients)
+
+	q.release()
+	require.NoError(t, q.acquire(context.Background(), client))
+}
@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"crypto/sha256"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+// Genesis serves genDoc under the genesis, genesis_chunked and genesis_info
+// routes, which are not served otherwise.
+func Genesis(genDoc *types.GenesisDoc) RoutesOption {
+	return func(env *environment) {
+		env.GenDoc = genDoc
+	}
+}
+
+// ResultGenesisInfo is the result of the genesis_info route.
+type ResultGenesisInfo struct {
+	// TotalChunks is the number of chunks served by genesis_chunked.
+	TotalChunks int `json:"total_chunks"`
+	// TotalBytes is the size of the genesis document reassembled from the
+	// chunks, and SHA256 its hash.
+	TotalBytes int            `json:"total_bytes"`
+	SHA256     bytes.HexBytes `json:"sha256"`
+}
+
+// initGenesis splits the genesis document in the chunks served by
+// genesis_chunked and computes its info, once for all requests.
+func (env *environment) initGenesis() error {
+	if err := env.InitGenesisChunks(); err != nil {
+		return err
+	}
+	chunk, err := env.GenesisChunked(nil, 0)
+	if err != nil {
+		return err
+	}
+	// The chunks are those of the document encoded in the same way.
+	data, err := cmtjson.Marshal(env.GenDoc)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(data)
+	env.genesisInfo = &ResultGenesisInfo{
+		TotalChunks: chunk.TotalChunks,
+		TotalBytes:  len(data),
+		SHA256:      hash[:],
+	}
+	return nil
+}
+
+// GenesisInfo returns the number of chunks served by genesis_chunked, along
+// with the size and the SHA-256 hash of the genesis document, so that clients
+// can download the chunks in parallel and verify the document they reassemble.
+func (env *environment) GenesisInfo(_ *rpctypes.Context) (*ResultGenesisInfo, error) {
+	return env.genesisInfo, nil
+}
@@ -0,0 +1,33 @@
+package rpc
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestGenesisInfo(t *testing.T) {
+	genDoc := &types.GenesisDoc{
+		ChainID:       "test-chain",
+		GenesisTime:   time.Unix(1, 0).UTC(),
+		InitialHeight: 1,
+	}
+	env := newTestEnvironment(&statemocks.BlockStore{}, &statemocks.Store{}, Genesis(genDoc))
+
+	res, err := env.GenesisInfo(nil)
+	require.NoError(t, err)
+	data, err := cmtjson.Marshal(genDoc)
+	require.NoError(t, err)
+	hash := sha256.Sum256(data)
+	require.Equal(t, &ResultGenesisInfo{TotalChunks: 1, TotalBytes: len(data), SHA256: hash[:]}, res)
+
+	chunk, err := env.GenesisChunked(nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, res.TotalChunks, chunk.TotalChunks)
+}
@@ -0,0 +1,25 @@
+package rpc
+
+import (
+	"net/http"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// h2cHandler wraps h to serve HTTP/2 over cleartext connections, using either
+// prior knowledge or an upgrade from HTTP/1.1. HTTP/1.1 requests, including
+// websocket upgrades, are passed to h unchanged.
+//
+// Websockets cannot be served over HTTP/2 since the connection cannot be
+// hijacked, so websocket requests received over HTTP/2 are rejected with a
+// 505 status, telling the client to retry over HTTP/1.1.
+func h2cHandler(h http.Handler) http.Handler {
+	return h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && r.URL.Path == "/websocket" {
+			http.Error(w, "websocket connections require HTTP/1.1", http.StatusHTTPVersionNotSupported)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}), &http2.Server{})
+}
@@ -0,0 +1,54 @@
+package rpc
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cometbft/cometbft/rpc/core"
+)
+
+// ShortCircuitHeadRequests answers the HEAD requests to the URI routes, such
+// as /status, without calling the routes. The response carries the status and
+// the Content-Type of a successful GET request to the route, as negotiated
+// with the Accept header of the request, and no Content-Length, which depends
+// on the result. The HEAD requests to unknown routes are answered with a 404
+// status.
+//
+// This lets monitoring tools probe the availability of the server cheaply,
+// but the status of the response does not reflect the errors which the route
+// would return, such as for invalid parameters. By default, the HEAD requests
+// are served by calling the routes as GET requests, and the body is discarded.
+func ShortCircuitHeadRequests() HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.shortCircuitHead = true
+	}
+}
+
+// headHandler answers the HEAD requests to the URI routes of routes, passing
+// all other requests through to h.
+func headHandler(h http.Handler, routes core.RoutesMap, registry codecRegistry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		if r.Method != http.MethodHead || name == "" || strings.Contains(name, "/") {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if _, ok := routes[name]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		contentType := "application/json"
+		if codec := registry.negotiate(r); codec != nil {
+			w.Header().Add("Vary", "Accept")
+			contentType = codec.MediaType()
+		} else if accepts(r, CompactMediaType) {
+			w.Header().Add("Vary", "Accept")
+			if compactRoutes[name] {
+				contentType = CompactMediaType
+			}
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+	})
+}
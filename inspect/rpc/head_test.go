@@ -0,0 +1,44 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	indexermocks "github.com/cometbft/cometbft/state/indexer/mocks"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
+)
+
+func TestShortCircuitHeadRequests(t *testing.T) {
+	cfg := config.TestRPCConfig()
+	logger := log.NewNopLogger()
+	// The mocks fail the test if the routes read from the stores.
+	routes := Routes(*cfg, &statemocks.Store{}, &statemocks.BlockStore{},
+		&txindexmocks.TxIndexer{}, &indexermocks.BlockIndexer{}, logger)
+	h := Handler(cfg, routes, logger, ShortCircuitHeadRequests())
+
+	head := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodHead, path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := head("/block?height=5", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.Empty(t, rec.Body.Bytes())
+
+	require.Equal(t, CBORMediaType, head("/block", CBORMediaType).Header().Get("Content-Type"))
+	require.Equal(t, CompactMediaType, head("/blockchain", CompactMediaType).Header().Get("Content-Type"))
+	require.Equal(t, "application/json", head("/block", CompactMediaType).Header().Get("Content-Type"))
+	require.Equal(t, http.StatusNotFound, head("/missing", "").Code)
+}
@@ -0,0 +1,342 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	cmtmath "github.com/cometbft/cometbft/libs/math"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+// AppHash is the application hash recorded in the header at a height.
+type AppHash struct {
+	Height  int64          `json:"height"`
+	AppHash bytes.HexBytes `json:"app_hash"`
+}
+
+// ResultAppHashRange is the result of the apphash_range route.
+type ResultAppHashRange struct {
+	LastHeight int64     `json:"last_height"`
+	AppHashes  []AppHash `json:"app_hashes"`
+}
+
+// AppHashRange returns the application hashes of the headers for
+// minHeight <= height <= maxHeight, in ascending order. The hash in the
+// header at a height is the one returned by the application after executing
+// the block at the previous height.
+//
+// Only block metas are read; heights missing from the block store are
+// skipped. The range is resolved as in the blockchain route.
+func (env *environment) AppHashRange(
+	_ *rpctypes.Context,
+	minHeight, maxHeight int64,
+) (*ResultAppHashRange, error) {
+	appHashes, err := blockMetaRange(env, minHeight, maxHeight, func(height int64, meta *types.BlockMeta) AppHash {
+		return AppHash{Height: height, AppHash: meta.Header.AppHash}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResultAppHashRange{
+		LastHeight: env.BlockStore.Height(),
+		AppHashes:  appHashes,
+	}, nil
+}
+
+// ValidatorHashes are the hashes of the validator sets recorded in the
+// header at a height.
+type ValidatorHashes struct {
+	Height             int64          `json:"height"`
+	ValidatorsHash     bytes.HexBytes `json:"validators_hash"`
+	NextValidatorsHash bytes.HexBytes `json:"next_validators_hash"`
+}
+
+// ResultValidatorHashesRange is the result of the validator_hashes_range
+// route.
+type ResultValidatorHashesRange struct {
+	LastHeight      int64             `json:"last_height"`
+	ValidatorHashes []ValidatorHashes `json:"validator_hashes"`
+}
+
+// ValidatorHashesRange returns the hashes of the validator set and of the next
+// validator set recorded in the headers for minHeight <= height <= maxHeight,
+// in ascending order, so that the transitions of the validator set can be
+// checked without loading the sets.
+//
+// Only block metas are read; heights missing from the block store are
+// skipped. The range is resolved as in the blockchain route.
+func (env *environment) ValidatorHashesRange(
+	_ *rpctypes.Context,
+	minHeight, maxHeight int64,
+) (*ResultValidatorHashesRange, error) {
+	hashes, err := blockMetaRange(env, minHeight, maxHeight, func(height int64, meta *types.BlockMeta) ValidatorHashes {
+		return ValidatorHashes{
+			Height:             height,
+			ValidatorsHash:     meta.Header.ValidatorsHash,
+			NextValidatorsHash: meta.Header.NextValidatorsHash,
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResultValidatorHashesRange{
+		LastHeight:      env.BlockStore.Height(),
+		ValidatorHashes: hashes,
+	}, nil
+}
+
+// TxCount is the number of transactions of the block at a height.
+type TxCount struct {
+	Height int64 `json:"height"`
+	NumTxs int   `json:"num_txs"`
+}
+
+// ResultTxCountsRange is the result of the tx_counts_range route.
+type ResultTxCountsRange struct {
+	LastHeight int64     `json:"last_height"`
+	TxCounts   []TxCount `json:"tx_counts"`
+}
+
+// TxCountsRange returns the number of transactions of the blocks for
+// minHeight <= height <= maxHeight, in ascending order.
+//
+// Only block metas are read; heights missing from the block store are
+// skipped. The range is resolved as in the blockchain route.
+func (env *environment) TxCountsRange(
+	_ *rpctypes.Context,
+	minHeight, maxHeight int64,
+) (*ResultTxCountsRange, error) {
+	counts, err := blockMetaRange(env, minHeight, maxHeight, func(height int64, meta *types.BlockMeta) TxCount {
+		return TxCount{Height: height, NumTxs: meta.NumTxs}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResultTxCountsRange{
+		LastHeight: env.BlockStore.Height(),
+		TxCounts:   counts,
+	}, nil
+}
+
+// BlockSize is the size in bytes of the encoded block at a height.
+type BlockSize struct {
+	Height int64 `json:"height"`
+	Bytes  int   `json:"bytes"`
+}
+
+// ResultBlockSizesRange is the result of the block_sizes_range route.
+type ResultBlockSizesRange struct {
+	LastHeight int64       `json:"last_height"`
+	BlockSizes []BlockSize `json:"block_sizes"`
+}
+
+// BlockSizesRange returns the sizes of the blocks for
+// minHeight <= height <= maxHeight, in ascending order.
+//
+// The sizes are read from the block metas. The blocks are only loaded to
+// compute their size if their meta has none recorded. Heights missing from the
+// block store are skipped. The range is resolved as in the blockchain route.
+func (env *environment) BlockSizesRange(
+	_ *rpctypes.Context,
+	minHeight, maxHeight int64,
+) (*ResultBlockSizesRange, error) {
+	minHeight, maxHeight, err := env.heightRange(minHeight, maxHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := loadHeights(env, minHeight, maxHeight, func(height int64) (BlockSize, bool) {
+		blockMeta := env.BlockStore.LoadBlockMeta(height)
+		if blockMeta == nil {
+			return BlockSize{}, false
+		}
+		size := blockMeta.BlockSize
+		if size <= 0 {
+			block := env.BlockStore.LoadBlock(height)
+			if block == nil {
+				return BlockSize{}, false
+			}
+			size = block.Size()
+		}
+		return BlockSize{Height: height, Bytes: size}, true
+	})
+
+	return &ResultBlockSizesRange{
+		LastHeight: env.BlockStore.Height(),
+		BlockSizes: sizes,
+	}, nil
+}
+
+// HeightVersion is the version of the protocols recorded in the header of the
+// block at a height.
+type HeightVersion struct {
+	Height       int64  `json:"height"`
+	BlockVersion uint64 `json:"block_version"`
+	AppVersion   uint64 `json:"app_version"`
+}
+
+// ResultVersionsRange is the result of the versions_range route.
+type ResultVersionsRange struct {
+	LastHeight int64           `json:"last_height"`
+	Versions   []HeightVersion `json:"versions"`
+}
+
+// VersionsRange returns the block and app versions recorded in the headers of
+// the blocks for minHeight <= height <= maxHeight, in ascending order, such
+// as to find the block from which an app upgrade took effect. Heights missing
+// from the block store are skipped. The range is resolved as in the
+// blockchain route.
+func (env *environment) VersionsRange(
+	_ *rpctypes.Context,
+	minHeight, maxHeight int64,
+) (*ResultVersionsRange, error) {
+	versions, err := blockMetaRange(env, minHeight, maxHeight, func(height int64, meta *types.BlockMeta) HeightVersion {
+		return HeightVersion{
+			Height:       height,
+			BlockVersion: meta.Header.Version.Block,
+			AppVersion:   meta.Header.Version.App,
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResultVersionsRange{
+		LastHeight: env.BlockStore.Height(),
+		Versions:   versions,
+	}, nil
+}
+
+// HeaderHashes are the hashes of the results, data and consensus params
+// recorded in the header of the block at a height.
+type HeaderHashes struct {
+	Height          int64          `json:"height"`
+	LastResultsHash bytes.HexBytes `json:"last_results_hash"`
+	DataHash        bytes.HexBytes `json:"data_hash"`
+	ConsensusHash   bytes.HexBytes `json:"consensus_hash"`
+}
+
+// ResultHeaderHashes is the result of the header_hashes route.
+type ResultHeaderHashes struct {
+	LastHeight   int64          `json:"last_height"`
+	HeaderHashes []HeaderHashes `json:"header_hashes"`
+}
+
+// HeaderHashes returns the last results, data and consensus params hashes
+// recorded in the headers for minHeight <= height <= maxHeight, in ascending
+// order, so that the fields can be compared across heights when localizing a
+// nondeterminism without loading the full headers.
+//
+// Only block metas are read; heights missing from the block store are
+// skipped. The range is resolved as in the blockchain route.
+func (env *environment) HeaderHashes(
+	_ *rpctypes.Context,
+	minHeight, maxHeight int64,
+) (*ResultHeaderHashes, error) {
+	hashes, err := blockMetaRange(env, minHeight, maxHeight, func(height int64, meta *types.BlockMeta) HeaderHashes {
+		return HeaderHashes{
+			Height:          height,
+			LastResultsHash: meta.Header.LastResultsHash,
+			DataHash:        meta.Header.DataHash,
+			ConsensusHash:   meta.Header.ConsensusHash,
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResultHeaderHashes{
+		LastHeight:   env.BlockStore.Height(),
+		HeaderHashes: hashes,
+	}, nil
+}
+
+// defaultLatestHeaders is the number of headers returned by the
+// latest_headers route when no count is given.
+const defaultLatestHeaders = 20
+
+// ResultLatestHeaders is the result of the latest_headers route.
+type ResultLatestHeaders struct {
+	LastHeight int64          `json:"last_height"`
+	Headers    []types.Header `json:"headers"`
+}
+
+// LatestHeaders returns the headers of the latest count blocks in the block
+// store, newest first. A count of 0 defaults to 20 headers, and count is
+// capped to the maximum range span. Fewer headers are returned if the store
+// holds fewer blocks.
+func (env *environment) LatestHeaders(_ *rpctypes.Context, count int64) (*ResultLatestHeaders, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("count must be non-negative, but got %d", count)
+	}
+	if count == 0 {
+		count = defaultLatestHeaders
+	}
+	count = cmtmath.MinInt64(count, env.maxRangeSpan)
+
+	base, height := env.BlockStore.Base(), env.BlockStore.Height()
+	headers := make([]types.Header, 0, cmtmath.MinInt64(count, height-base+1))
+	for h := height; h > 0 && h >= base && h > height-count; h-- {
+		blockMeta := env.BlockStore.LoadBlockMeta(h)
+		if blockMeta == nil {
+			continue
+		}
+		headers = append(headers, blockMeta.Header)
+	}
+
+	return &ResultLatestHeaders{
+		LastHeight: height,
+		Headers:    headers,
+	}, nil
+}
+
+// ResultBlockID is the result of the block_id route.
+type ResultBlockID struct {
+	Height int64          `json:"height"`
+	Hash   bytes.HexBytes `json:"hash"`
+}
+
+// BlockID returns the hash of the block at the given height, or of the latest
+// block if no height is given. Only the block meta is read.
+func (env *environment) BlockID(_ *rpctypes.Context, heightPtr *int64) (*ResultBlockID, error) {
+	height, err := env.getHeight(env.BlockStore.Height(), heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	blockMeta := env.BlockStore.LoadBlockMeta(height)
+	if blockMeta == nil {
+		return nil, fmt.Errorf("block meta not found for height %d", height)
+	}
+	return &ResultBlockID{Height: height, Hash: blockMeta.BlockID.Hash}, nil
+}
+
+// ResultBlockIDRange is the result of the block_id_range route.
+type ResultBlockIDRange struct {
+	LastHeight int64           `json:"last_height"`
+	BlockIDs   []ResultBlockID `json:"block_ids"`
+}
+
+// BlockIDRange returns the hashes of the blocks for
+// minHeight <= height <= maxHeight, in ascending order. Heights missing from
+// the block store are skipped. The range is resolved as in the blockchain
+// route.
+func (env *environment) BlockIDRange(
+	_ *rpctypes.Context,
+	minHeight, maxHeight int64,
+) (*ResultBlockIDRange, error) {
+	blockIDs, err := blockMetaRange(env, minHeight, maxHeight, func(height int64, meta *types.BlockMeta) ResultBlockID {
+		return ResultBlockID{Height: height, Hash: meta.BlockID.Hash}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResultBlockIDRange{
+		LastHeight: env.BlockStore.Height(),
+		BlockIDs:   blockIDs,
+	}, nil
+}
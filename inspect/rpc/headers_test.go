@@ -0,0 +1,218 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cmtversion "github.com/cometbft/cometbft/proto/tendermint/version"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestAppHashRange(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(5))
+	for height := int64(3); height <= 5; height++ {
+		blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{
+			Header: types.Header{Height: height, AppHash: []byte{byte(height / 5)}},
+		})
+	}
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{}, MaxRangeSpan(3))
+
+	res, err := env.AppHashRange(nil, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), res.LastHeight)
+	require.Equal(t, []AppHash{
+		{Height: 3, AppHash: []byte{0}},
+		{Height: 4, AppHash: []byte{0}},
+		{Height: 5, AppHash: []byte{1}},
+	}, res.AppHashes)
+
+	_, err = env.AppHashRange(nil, 5, 4)
+	require.Error(t, err)
+}
+
+func TestLatestHeaders(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(2))
+	blockStoreMock.On("Height").Return(int64(5))
+	for height := int64(2); height <= 5; height++ {
+		blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{Header: types.Header{Height: height}})
+	}
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{}, MaxRangeSpan(3))
+
+	heights := func(res *ResultLatestHeaders) []int64 {
+		heights := make([]int64, len(res.Headers))
+		for i, header := range res.Headers {
+			heights[i] = header.Height
+		}
+		return heights
+	}
+
+	res, err := env.LatestHeaders(nil, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), res.LastHeight)
+	require.Equal(t, []int64{5, 4}, heights(res))
+
+	// The count is capped to the maximum range span.
+	res, err = env.LatestHeaders(nil, 10)
+	require.NoError(t, err)
+	require.Equal(t, []int64{5, 4, 3}, heights(res))
+
+	// The store holds fewer blocks than requested.
+	env = newTestEnvironment(blockStoreMock, &statemocks.Store{})
+	res, err = env.LatestHeaders(nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, []int64{5, 4, 3, 2}, heights(res))
+
+	_, err = env.LatestHeaders(nil, -1)
+	require.Error(t, err)
+}
+
+func TestBlockID(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(5))
+	blockStoreMock.On("LoadBlockMeta", int64(3)).Return(nil)
+	for _, height := range []int64{2, 4, 5} {
+		blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{
+			BlockID: types.BlockID{Hash: []byte{byte(height)}},
+		})
+	}
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{}, MaxRangeSpan(4))
+
+	res, err := env.BlockID(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, &ResultBlockID{Height: 5, Hash: []byte{5}}, res)
+
+	height := int64(3)
+	_, err = env.BlockID(nil, &height)
+	require.Error(t, err)
+
+	resRange, err := env.BlockIDRange(nil, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), resRange.LastHeight)
+	require.Equal(t, []ResultBlockID{
+		{Height: 2, Hash: []byte{2}},
+		{Height: 4, Hash: []byte{4}},
+		{Height: 5, Hash: []byte{5}},
+	}, resRange.BlockIDs)
+}
+
+func TestValidatorHashesRange(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(5))
+	for height := int64(3); height <= 5; height++ {
+		blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{
+			Header: types.Header{
+				Height:             height,
+				ValidatorsHash:     []byte{byte(height)},
+				NextValidatorsHash: []byte{byte(height + 1)},
+			},
+		})
+	}
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{}, MaxRangeSpan(3))
+
+	res, err := env.ValidatorHashesRange(nil, 3, 4)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), res.LastHeight)
+	require.Equal(t, []ValidatorHashes{
+		{Height: 3, ValidatorsHash: []byte{3}, NextValidatorsHash: []byte{4}},
+		{Height: 4, ValidatorsHash: []byte{4}, NextValidatorsHash: []byte{5}},
+	}, res.ValidatorHashes)
+
+	_, err = env.ValidatorHashesRange(nil, 5, 4)
+	require.Error(t, err)
+}
+
+func TestTxCountsRange(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(5))
+	blockStoreMock.On("LoadBlockMeta", int64(3)).Return(nil)
+	for _, height := range []int64{2, 4, 5} {
+		blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{NumTxs: int(height) * 10})
+	}
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{}, MaxRangeSpan(4))
+
+	res, err := env.TxCountsRange(nil, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), res.LastHeight)
+	require.Equal(t, []TxCount{{Height: 2, NumTxs: 20}, {Height: 4, NumTxs: 40}, {Height: 5, NumTxs: 50}}, res.TxCounts)
+}
+
+func TestBlockSizesRange(t *testing.T) {
+	block := &types.Block{Header: types.Header{Height: 4}}
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(5))
+	blockStoreMock.On("LoadBlockMeta", int64(2)).Return(&types.BlockMeta{BlockSize: 200})
+	blockStoreMock.On("LoadBlockMeta", int64(3)).Return(nil)
+	blockStoreMock.On("LoadBlockMeta", int64(5)).Return(&types.BlockMeta{BlockSize: 500})
+	// Without a recorded size, it is computed from the block.
+	blockStoreMock.On("LoadBlockMeta", int64(4)).Return(&types.BlockMeta{})
+	blockStoreMock.On("LoadBlock", int64(4)).Return(block)
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{}, MaxRangeSpan(4))
+
+	res, err := env.BlockSizesRange(nil, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), res.LastHeight)
+	require.Equal(t, []BlockSize{{Height: 2, Bytes: 200}, {Height: 4, Bytes: block.Size()}, {Height: 5, Bytes: 500}},
+		res.BlockSizes)
+	blockStoreMock.AssertNumberOfCalls(t, "LoadBlock", 1)
+}
+
+func TestVersionsRange(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(4))
+	blockStoreMock.On("LoadBlockMeta", int64(1)).Return(nil)
+	for height := int64(2); height <= 4; height++ {
+		app := uint64(1)
+		if height == 4 {
+			app = 2
+		}
+		blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{
+			Header: types.Header{Height: height, Version: cmtversion.Consensus{Block: 11, App: app}},
+		})
+	}
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{})
+
+	res, err := env.VersionsRange(nil, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), res.LastHeight)
+	require.Equal(t, []HeightVersion{
+		{Height: 2, BlockVersion: 11, AppVersion: 1},
+		{Height: 3, BlockVersion: 11, AppVersion: 1},
+		{Height: 4, BlockVersion: 11, AppVersion: 2},
+	}, res.Versions)
+}
+
+func TestHeaderHashes(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(3))
+	blockStoreMock.On("LoadBlockMeta", int64(2)).Return(nil)
+	for _, height := range []int64{1, 3} {
+		blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{
+			Header: types.Header{
+				Height:          height,
+				LastResultsHash: []byte{byte(height), 1},
+				DataHash:        []byte{byte(height), 2},
+				ConsensusHash:   []byte{byte(height), 3},
+			},
+		})
+	}
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{})
+
+	res, err := env.HeaderHashes(nil, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), res.LastHeight)
+	require.Equal(t, []HeaderHashes{
+		{Height: 1, LastResultsHash: []byte{1, 1}, DataHash: []byte{1, 2}, ConsensusHash: []byte{1, 3}},
+		{Height: 3, LastResultsHash: []byte{3, 1}, DataHash: []byte{3, 2}, ConsensusHash: []byte{3, 3}},
+	}, res.HeaderHashes)
+}
@@ -0,0 +1,67 @@
+package rpc
+
+import (
+	"fmt"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+const (
+	healthStatusOK       = "ok"
+	healthStatusDegraded = "degraded"
+)
+
+// ResultHealth is the result of the health route.
+type ResultHealth struct {
+	// Status is "ok", or "degraded" if a deep check found a problem.
+	Status string `json:"status"`
+	// Problems describes the problems found by a deep check.
+	Problems []string `json:"problems,omitempty"`
+}
+
+// Health returns the health of the Inspector. Without deep, it only proves
+// that the server is responding. With deep, it also reads the base and tip of
+// the block store and reports a degraded status, with the problems found, if
+// the reads fail or the store is inconsistent.
+func (env *environment) Health(_ *rpctypes.Context, deep bool) (*ResultHealth, error) {
+	res := &ResultHealth{Status: healthStatusOK}
+	if deep {
+		res.Problems = env.checkBlockStore()
+	}
+	if len(res.Problems) > 0 {
+		res.Status = healthStatusDegraded
+	}
+	return res, nil
+}
+
+// checkBlockStore returns the problems found reading the base and tip of the
+// block store.
+func (env *environment) checkBlockStore() (problems []string) {
+	defer func() {
+		if r := recover(); r != nil {
+			problems = append(problems, fmt.Sprintf("reading the block store: %v", r))
+		}
+	}()
+
+	base, height := env.BlockStore.Base(), env.BlockStore.Height()
+	if height == 0 {
+		return nil
+	}
+	if base > height {
+		return []string{fmt.Sprintf("block store base %d is above its height %d", base, height)}
+	}
+	for _, h := range []int64{base, height} {
+		blockMeta := env.BlockStore.LoadBlockMeta(h)
+		switch {
+		case blockMeta == nil:
+			problems = append(problems, fmt.Sprintf("missing block meta at height %d", h))
+		case blockMeta.Header.Height != h:
+			problems = append(problems, fmt.Sprintf("block meta at height %d has header height %d",
+				h, blockMeta.Header.Height))
+		}
+		if base == height {
+			break
+		}
+	}
+	return problems
+}
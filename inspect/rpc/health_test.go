@@ -0,0 +1,87 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestHealth(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setup    func(*statemocks.BlockStore)
+		problems []string
+	}{
+		{
+			name: "healthy",
+			setup: func(bs *statemocks.BlockStore) {
+				bs.On("Base").Return(int64(2))
+				bs.On("Height").Return(int64(5))
+				bs.On("LoadBlockMeta", int64(2)).Return(&types.BlockMeta{Header: types.Header{Height: 2}})
+				bs.On("LoadBlockMeta", int64(5)).Return(&types.BlockMeta{Header: types.Header{Height: 5}})
+			},
+		},
+		{
+			name: "empty",
+			setup: func(bs *statemocks.BlockStore) {
+				bs.On("Base").Return(int64(0))
+				bs.On("Height").Return(int64(0))
+			},
+		},
+		{
+			name: "base above height",
+			setup: func(bs *statemocks.BlockStore) {
+				bs.On("Base").Return(int64(6))
+				bs.On("Height").Return(int64(5))
+			},
+			problems: []string{"block store base 6 is above its height 5"},
+		},
+		{
+			name: "missing and mismatched metas",
+			setup: func(bs *statemocks.BlockStore) {
+				bs.On("Base").Return(int64(2))
+				bs.On("Height").Return(int64(5))
+				bs.On("LoadBlockMeta", int64(2)).Return(nil)
+				bs.On("LoadBlockMeta", int64(5)).Return(&types.BlockMeta{Header: types.Header{Height: 4}})
+			},
+			problems: []string{
+				"missing block meta at height 2",
+				"block meta at height 5 has header height 4",
+			},
+		},
+		{
+			name: "read failure",
+			setup: func(bs *statemocks.BlockStore) {
+				bs.On("Base").Return(int64(1))
+				bs.On("Height").Return(int64(5))
+				bs.On("LoadBlockMeta", int64(1)).Panic("corrupt meta")
+			},
+			problems: []string{"reading the block store: corrupt meta"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			blockStoreMock := &statemocks.BlockStore{}
+			tc.setup(blockStoreMock)
+			env := newTestEnvironment(blockStoreMock, &statemocks.Store{})
+
+			res, err := env.Health(nil, true)
+			require.NoError(t, err)
+			require.Equal(t, tc.problems, res.Problems)
+			if tc.problems == nil {
+				require.Equal(t, healthStatusOK, res.Status)
+			} else {
+				require.Equal(t, healthStatusDegraded, res.Status)
+			}
+		})
+	}
+
+	// The shallow check does not read the stores.
+	env := newTestEnvironment(&statemocks.BlockStore{}, &statemocks.Store{})
+	res, err := env.Health(nil, false)
+	require.NoError(t, err)
+	require.Equal(t, healthStatusOK, res.Status)
+}
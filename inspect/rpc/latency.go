@@ -0,0 +1,109 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/pubsub/query"
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/state/indexer"
+	blockidxnull "github.com/cometbft/cometbft/state/indexer/block/null"
+	"github.com/cometbft/cometbft/state/txindex"
+	"github.com/cometbft/cometbft/state/txindex/null"
+	"github.com/cometbft/cometbft/types"
+)
+
+// timeStoreReads records the durations of the reads of the block store and
+// indexers of env in the StoreReadDurationSeconds metric. The durations
+// exclude the time spent in the indexer timeouts and circuit breaker, so that
+// they reflect the database backend rather than the RPC layer.
+func (env *environment) timeStoreReads() {
+	duration := env.metrics.StoreReadDurationSeconds
+	env.BlockStore = timedBlockStore{BlockStore: env.BlockStore, duration: duration}
+	env.wrapIndexers(func(txidx txindex.TxIndexer, blkidx indexer.BlockIndexer) (txindex.TxIndexer, indexer.BlockIndexer) {
+		if _, ok := txidx.(*null.TxIndex); !ok {
+			txidx = timedTxIndexer{TxIndexer: txidx, duration: duration}
+		}
+		if _, ok := blkidx.(*blockidxnull.BlockerIndexer); !ok {
+			blkidx = timedBlockIndexer{BlockIndexer: blkidx, duration: duration}
+		}
+		return txidx, blkidx
+	})
+}
+
+// observeSince records the time elapsed since start for operation. It is
+// meant to be deferred, so that reads which panic are recorded as well.
+func observeSince(duration metrics.Histogram, operation string, start time.Time) {
+	duration.With("operation", operation).Observe(time.Since(start).Seconds())
+}
+
+// timedBlockStore is a BlockStore recording the durations of its reads.
+type timedBlockStore struct {
+	state.BlockStore
+	duration metrics.Histogram
+}
+
+func (bs timedBlockStore) LoadBlock(height int64) *types.Block {
+	defer observeSince(bs.duration, "block", time.Now())
+	return bs.BlockStore.LoadBlock(height)
+}
+
+func (bs timedBlockStore) LoadBlockByHash(hash []byte) *types.Block {
+	defer observeSince(bs.duration, "block", time.Now())
+	return bs.BlockStore.LoadBlockByHash(hash)
+}
+
+func (bs timedBlockStore) LoadBaseMeta() *types.BlockMeta {
+	defer observeSince(bs.duration, "meta", time.Now())
+	return bs.BlockStore.LoadBaseMeta()
+}
+
+func (bs timedBlockStore) LoadBlockMeta(height int64) *types.BlockMeta {
+	defer observeSince(bs.duration, "meta", time.Now())
+	return bs.BlockStore.LoadBlockMeta(height)
+}
+
+func (bs timedBlockStore) LoadBlockMetaByHash(hash []byte) *types.BlockMeta {
+	defer observeSince(bs.duration, "meta", time.Now())
+	return bs.BlockStore.LoadBlockMetaByHash(hash)
+}
+
+func (bs timedBlockStore) LoadBlockCommit(height int64) *types.Commit {
+	defer observeSince(bs.duration, "commit", time.Now())
+	return bs.BlockStore.LoadBlockCommit(height)
+}
+
+func (bs timedBlockStore) LoadSeenCommit(height int64) *types.Commit {
+	defer observeSince(bs.duration, "commit", time.Now())
+	return bs.BlockStore.LoadSeenCommit(height)
+}
+
+// timedTxIndexer is a TxIndexer recording the durations of its reads.
+type timedTxIndexer struct {
+	txindex.TxIndexer
+	duration metrics.Histogram
+}
+
+func (idx timedTxIndexer) Get(hash []byte) (*abci.TxResult, error) {
+	defer observeSince(idx.duration, "tx", time.Now())
+	return idx.TxIndexer.Get(hash)
+}
+
+func (idx timedTxIndexer) Search(ctx context.Context, q *query.Query) ([]*abci.TxResult, error) {
+	defer observeSince(idx.duration, "search", time.Now())
+	return idx.TxIndexer.Search(ctx, q)
+}
+
+// timedBlockIndexer is a BlockIndexer recording the durations of its reads.
+type timedBlockIndexer struct {
+	indexer.BlockIndexer
+	duration metrics.Histogram
+}
+
+func (idx timedBlockIndexer) Search(ctx context.Context, q *query.Query) ([]int64, error) {
+	defer observeSince(idx.duration, "search", time.Now())
+	return idx.BlockIndexer.Search(ctx, q)
+}
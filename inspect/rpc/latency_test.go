@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	blockidxnull "github.com/cometbft/cometbft/state/indexer/block/null"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+// operationHistogram counts the observations of a histogram by operation.
+type operationHistogram struct {
+	operation string
+	counts    map[string]int
+}
+
+func (h *operationHistogram) With(labelValues ...string) metrics.Histogram {
+	return &operationHistogram{operation: labelValues[1], counts: h.counts}
+}
+
+func (h *operationHistogram) Observe(float64) { h.counts[h.operation]++ }
+
+func TestStoreReadDuration(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("LoadBlock", mock.Anything).Return(&types.Block{})
+	blockStoreMock.On("LoadBlockMeta", mock.Anything).Return(&types.BlockMeta{})
+	blockStoreMock.On("LoadSeenCommit", mock.Anything).Return(&types.Commit{})
+	histogram := &operationHistogram{counts: make(map[string]int)}
+	metrics := NopMetrics()
+	metrics.StoreReadDurationSeconds = histogram
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	txIndexerMock.On("Search", mock.Anything, mock.Anything).Return([]*abcitypes.TxResult{}, nil)
+	env := newEnvironment(*config.DefaultRPCConfig(), &statemocks.Store{}, blockStoreMock,
+		txIndexerMock, &blockidxnull.BlockerIndexer{}, log.NewNopLogger(), WithMetrics(metrics))
+
+	env.BlockStore.LoadBlock(1)
+	env.BlockStore.LoadBlockMeta(1)
+	env.BlockStore.LoadBlockMeta(2)
+	env.BlockStore.LoadSeenCommit(1)
+	require.Equal(t, map[string]int{"block": 1, "meta": 2, "commit": 1}, histogram.counts)
+
+	_, err := env.TxIndexer.Search(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, histogram.counts["search"])
+
+	// The null indexers are not wrapped, so that disabled indexing is still
+	// reported as such.
+	_, ok := env.BlockIndexer.(*blockidxnull.BlockerIndexer)
+	require.True(t, ok)
+}
@@ -0,0 +1,76 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+
+	"golang.org/x/net/netutil"
+
+	"github.com/cometbft/cometbft/rpc/jsonrpc/server"
+)
+
+// errListenOptionUnsupported is returned on the platforms where the listener
+// backlog and SO_REUSEPORT cannot be set.
+var errListenOptionUnsupported = errors.New("listener backlog and SO_REUSEPORT not supported on this platform")
+
+// listen returns a listener on srv.Addr, set up with the listener options of
+// srv. Without listener options, it is the listener of server.Listen.
+func (srv *Server) listen(ctx context.Context) (net.Listener, error) {
+	if srv.ListenBacklog <= 0 && !srv.ReusePort {
+		return server.Listen(srv.Addr, srv.Config.MaxOpenConnections)
+	}
+	proto, addr, ok := strings.Cut(srv.Addr, "://")
+	if !ok {
+		return nil, fmt.Errorf(
+			"invalid listening address %s (use fully formed addresses, including the tcp:// or unix:// prefix)",
+			srv.Addr,
+		)
+	}
+
+	var lc net.ListenConfig
+	if srv.ReusePort && strings.HasPrefix(proto, "tcp") {
+		lc.Control = func(_, _ string, c syscall.RawConn) error {
+			var sockErr error
+			if err := c.Control(func(fd uintptr) { sockErr = setReusePort(fd) }); err != nil {
+				return err
+			}
+			return sockErr
+		}
+	}
+	listener, err := lc.Listen(ctx, proto, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %v: %w", addr, err)
+	}
+	if srv.ListenBacklog > 0 {
+		if err := setListenBacklog(listener, srv.ListenBacklog); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("failed to set the listener backlog on %v: %w", addr, err)
+		}
+	}
+	if srv.Config.MaxOpenConnections > 0 {
+		listener = netutil.LimitListener(listener, srv.Config.MaxOpenConnections)
+	}
+	return listener, nil
+}
+
+// setListenBacklog sets the length of the queue of pending connections of
+// listener, by listening again on its socket with backlog.
+func setListenBacklog(listener net.Listener, backlog int) error {
+	sc, ok := listener.(syscall.Conn)
+	if !ok {
+		return errListenOptionUnsupported
+	}
+	c, err := sc.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var listenErr error
+	if err := c.Control(func(fd uintptr) { listenErr = relisten(fd, backlog) }); err != nil {
+		return err
+	}
+	return listenErr
+}
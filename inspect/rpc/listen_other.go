@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package rpc
+
+func setReusePort(uintptr) error {
+	return errListenOptionUnsupported
+}
+
+func relisten(uintptr, int) error {
+	return errListenOptionUnsupported
+}
@@ -0,0 +1,37 @@
+//go:build linux || darwin
+
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+)
+
+func TestListenReusePort(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv := &Server{
+		Addr:          "tcp://127.0.0.1:0",
+		Config:        config.DefaultRPCConfig(),
+		ListenBacklog: 16,
+		ReusePort:     true,
+	}
+	first, err := srv.listen(ctx)
+	require.NoError(t, err)
+	defer first.Close()
+
+	// A second listener on the same port is only allowed with SO_REUSEPORT.
+	srv.Addr = "tcp://" + first.Addr().String()
+	second, err := srv.listen(ctx)
+	require.NoError(t, err)
+	second.Close()
+
+	srv.ReusePort = false
+	_, err = srv.listen(ctx)
+	require.Error(t, err)
+}
@@ -0,0 +1,16 @@
+//go:build linux || darwin
+
+package rpc
+
+import "golang.org/x/sys/unix"
+
+// setReusePort sets SO_REUSEPORT on the socket fd.
+func setReusePort(fd uintptr) error {
+	return unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+}
+
+// relisten listens again on the listening socket fd, which updates the length
+// of its queue of pending connections to backlog.
+func relisten(fd uintptr, backlog int) error {
+	return unix.Listen(int(fd), backlog)
+}
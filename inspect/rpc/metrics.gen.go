@@ -0,0 +1,83 @@
+// Code generated by metricsgen. DO NOT EDIT.
+
+package rpc
+
+import (
+	"github.com/go-kit/kit/metrics/discard"
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	return &Metrics{
+		IndexerBreakerOpen: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "indexer_breaker_open",
+			Help:      "Whether the circuit breaker of the indexer is open (1) or closed (0).",
+		}, labels).With(labelsAndValues...),
+		IndexerTimeouts: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "indexer_timeouts",
+			Help:      "Number of indexer queries that timed out.",
+		}, labels).With(labelsAndValues...),
+		IndexerRejectedCalls: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "indexer_rejected_calls",
+			Help:      "Number of indexer calls rejected while the circuit breaker was open.",
+		}, labels).With(labelsAndValues...),
+		StoreReadDurationSeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "store_read_duration_seconds",
+			Help:      "Duration of the reads of the block store and indexers, in seconds, by operation: block, meta, commit, tx or search.",
+
+			Buckets: stdprometheus.ExponentialBucketsRange(0.0001, 10, 11),
+		}, append(labels, "operation")).With(labelsAndValues...),
+		Requests: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "requests",
+			Help:      "Number of HTTP requests served by the RPC servers, by status code.",
+		}, append(labels, "code")).With(labelsAndValues...),
+		RequestDurationSeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the HTTP requests served by the RPC servers, in seconds.",
+
+			Buckets: stdprometheus.ExponentialBucketsRange(0.0001, 10, 11),
+		}, labels).With(labelsAndValues...),
+		ResponseBytes: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "response_bytes",
+			Help:      "Number of bytes of the HTTP responses written by the RPC servers.",
+		}, labels).With(labelsAndValues...),
+		WebsocketSlowClientsClosed: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "websocket_slow_clients_closed",
+			Help:      "Number of websocket connections closed because their send buffer was full.",
+		}, labels).With(labelsAndValues...),
+	}
+}
+
+func NopMetrics() *Metrics {
+	return &Metrics{
+		IndexerBreakerOpen:         discard.NewGauge(),
+		IndexerTimeouts:            discard.NewCounter(),
+		IndexerRejectedCalls:       discard.NewCounter(),
+		StoreReadDurationSeconds:   discard.NewHistogram(),
+		Requests:                   discard.NewCounter(),
+		RequestDurationSeconds:     discard.NewHistogram(),
+		ResponseBytes:              discard.NewCounter(),
+		WebsocketSlowClientsClosed: discard.NewCounter(),
+	}
+}
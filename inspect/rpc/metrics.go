@@ -0,0 +1,42 @@
+package rpc
+
+import (
+	"github.com/go-kit/kit/metrics"
+)
+
+const (
+	// MetricsSubsystem is a subsystem shared by all metrics exposed by this
+	// package.
+	MetricsSubsystem = "inspect"
+)
+
+//go:generate go run ../../scripts/metricsgen -struct=Metrics
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Whether the circuit breaker of the indexer is open (1) or closed (0).
+	IndexerBreakerOpen metrics.Gauge
+
+	// Number of indexer queries that timed out.
+	IndexerTimeouts metrics.Counter
+
+	// Number of indexer calls rejected while the circuit breaker was open.
+	IndexerRejectedCalls metrics.Counter
+
+	// Duration of the reads of the block store and indexers, in seconds, by
+	// operation: block, meta, commit, tx or search.
+	StoreReadDurationSeconds metrics.Histogram `metrics_labels:"operation" metrics_buckettype:"exprange" metrics_bucketsizes:"0.0001, 10, 11"`
+
+	// Number of HTTP requests served by the RPC servers, by status code.
+	Requests metrics.Counter `metrics_labels:"code"`
+
+	// Duration of the HTTP requests served by the RPC servers, in seconds.
+	RequestDurationSeconds metrics.Histogram `metrics_buckettype:"exprange" metrics_bucketsizes:"0.0001, 10, 11"`
+
+	// Number of bytes of the HTTP responses written by the RPC servers.
+	ResponseBytes metrics.Counter
+
+	// Number of websocket connections closed because their send buffer was
+	// full.
+	WebsocketSlowClientsClosed metrics.Counter
+}
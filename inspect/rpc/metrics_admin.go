@@ -0,0 +1,133 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	cmtsync "github.com/cometbft/cometbft/libs/sync"
+)
+
+// MetricsAdmin serves a snapshot of the metrics gathered by gatherer whose
+// names start with prefix under /admin/metrics. A GET request returns the
+// snapshot, and a POST request returns it and then resets the resettable
+// metrics.
+//
+// Counters, as well as the count and sum of histograms and summaries, are
+// resettable: their values in the snapshots are relative to the last reset.
+// Gauges are reported as is. Resetting only affects the snapshots, the values
+// exported to Prometheus are left untouched.
+//
+// The route is only served along with an Authenticator.
+func MetricsAdmin(gatherer prometheus.Gatherer, prefix string) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.metricsAdmin = &metricsAdmin{
+			gatherer: gatherer,
+			prefix:   prefix,
+			baseline: make(map[string]float64),
+		}
+	}
+}
+
+// ResultMetricsSnapshot is the response of the metrics admin route.
+type ResultMetricsSnapshot struct {
+	// Metrics maps the series names, including their labels, to their values.
+	Metrics map[string]float64 `json:"metrics"`
+	// Reset is true if the resettable metrics were reset after the snapshot.
+	Reset bool `json:"reset"`
+}
+
+type metricsAdmin struct {
+	gatherer prometheus.Gatherer
+	prefix   string
+
+	mtx      cmtsync.Mutex
+	baseline map[string]float64
+}
+
+func (a *metricsAdmin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	values, resettable, err := a.gather()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	a.mtx.Lock()
+	res := ResultMetricsSnapshot{Metrics: make(map[string]float64, len(values))}
+	for name, value := range values {
+		if resettable[name] {
+			value -= a.baseline[name]
+		}
+		res.Metrics[name] = value
+	}
+	if r.Method == http.MethodPost {
+		for name := range resettable {
+			a.baseline[name] = values[name]
+		}
+		res.Reset = true
+	}
+	a.mtx.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res) //nolint: errcheck
+}
+
+// gather returns the current values of the series of the metrics matching
+// the prefix, along with the set of the resettable ones.
+func (a *metricsAdmin) gather() (map[string]float64, map[string]bool, error) {
+	families, err := a.gatherer.Gather()
+	if err != nil {
+		return nil, nil, err
+	}
+	values := make(map[string]float64)
+	resettable := make(map[string]bool)
+	for _, family := range families {
+		name := family.GetName()
+		if !strings.HasPrefix(name, a.prefix) {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			series := name + seriesLabels(m.GetLabel())
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				values[series] = m.GetCounter().GetValue()
+				resettable[series] = true
+			case dto.MetricType_GAUGE:
+				values[series] = m.GetGauge().GetValue()
+			case dto.MetricType_HISTOGRAM:
+				values[series+"_count"] = float64(m.GetHistogram().GetSampleCount())
+				values[series+"_sum"] = m.GetHistogram().GetSampleSum()
+				resettable[series+"_count"], resettable[series+"_sum"] = true, true
+			case dto.MetricType_SUMMARY:
+				values[series+"_count"] = float64(m.GetSummary().GetSampleCount())
+				values[series+"_sum"] = m.GetSummary().GetSampleSum()
+				resettable[series+"_count"], resettable[series+"_sum"] = true, true
+			default:
+				values[series] = m.GetUntyped().GetValue()
+			}
+		}
+	}
+	return values, resettable, nil
+}
+
+// seriesLabels formats labels as in the Prometheus exposition format.
+func seriesLabels(labels []*dto.LabelPair) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(labels))
+	for i, l := range labels {
+		pairs[i] = l.GetName() + "=\"" + l.GetValue() + "\""
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,54 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/rpc/core"
+)
+
+func TestMetricsAdmin(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_inspect_calls"})
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_inspect_open"})
+	other := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_other_calls"})
+	registry.MustRegister(counter, gauge, other)
+	counter.Add(3)
+	gauge.Set(1)
+
+	auth := BearerAuth{Tokens: map[string]string{"admin": "admin"}}
+	h := Handler(config.TestRPCConfig(), core.RoutesMap{}, log.NewNopLogger(),
+		Authenticate(auth), MetricsAdmin(registry, "test_inspect_"))
+	snapshot := func(method string) ResultMetricsSnapshot {
+		req := httptest.NewRequest(method, "/admin/metrics", nil)
+		req.Header.Set("Authorization", "Bearer admin")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var res ResultMetricsSnapshot
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return res
+	}
+
+	res := snapshot(http.MethodPost)
+	require.True(t, res.Reset)
+	require.Equal(t, map[string]float64{"test_inspect_calls": 3, "test_inspect_open": 1}, res.Metrics)
+
+	// Counters are relative to the last reset, gauges are not.
+	counter.Add(2)
+	res = snapshot(http.MethodGet)
+	require.False(t, res.Reset)
+	require.Equal(t, map[string]float64{"test_inspect_calls": 2, "test_inspect_open": 1}, res.Metrics)
+
+	// The route is not served to unauthenticated clients.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/metrics", nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,152 @@
+package rpc
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsSink creates the metrics of the Inspector in a metrics backend, so
+// that they can be recorded elsewhere than in Prometheus, for instance with
+// the StatsD or OpenTelemetry providers of go-kit. The names of the metrics
+// are given without namespace nor subsystem, which the sink adds as it sees
+// fit.
+type MetricsSink interface {
+	NewCounter(name, help string, labels []string) metrics.Counter
+	NewGauge(name, help string, labels []string) metrics.Gauge
+	NewHistogram(name, help string, buckets []float64, labels []string) metrics.Histogram
+}
+
+// PrometheusSink creates the metrics in the default Prometheus registry, under
+// Namespace and MetricsSubsystem, as PrometheusMetrics does.
+type PrometheusSink struct {
+	Namespace string
+}
+
+var _ MetricsSink = PrometheusSink{}
+
+// NewCounter implements MetricsSink.
+func (s PrometheusSink) NewCounter(name, help string, labels []string) metrics.Counter {
+	return prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: s.Namespace,
+		Subsystem: MetricsSubsystem,
+		Name:      name,
+		Help:      help,
+	}, labels)
+}
+
+// NewGauge implements MetricsSink.
+func (s PrometheusSink) NewGauge(name, help string, labels []string) metrics.Gauge {
+	return prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		Namespace: s.Namespace,
+		Subsystem: MetricsSubsystem,
+		Name:      name,
+		Help:      help,
+	}, labels)
+}
+
+// NewHistogram implements MetricsSink.
+func (s PrometheusSink) NewHistogram(name, help string, buckets []float64, labels []string) metrics.Histogram {
+	return prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: s.Namespace,
+		Subsystem: MetricsSubsystem,
+		Name:      name,
+		Help:      help,
+		Buckets:   buckets,
+	}, labels)
+}
+
+// SinkMetrics returns the metrics of the Inspector created by sink. The
+// metrics are the same as those of PrometheusMetrics.
+func SinkMetrics(sink MetricsSink) *Metrics {
+	durationBuckets := stdprometheus.ExponentialBucketsRange(0.0001, 10, 11)
+	return &Metrics{
+		IndexerBreakerOpen: sink.NewGauge("indexer_breaker_open",
+			"Whether the circuit breaker of the indexer is open (1) or closed (0).", nil),
+		IndexerTimeouts: sink.NewCounter("indexer_timeouts",
+			"Number of indexer queries that timed out.", nil),
+		IndexerRejectedCalls: sink.NewCounter("indexer_rejected_calls",
+			"Number of indexer calls rejected while the circuit breaker was open.", nil),
+		StoreReadDurationSeconds: sink.NewHistogram("store_read_duration_seconds",
+			"Duration of the reads of the block store and indexers, in seconds, by operation: "+
+				"block, meta, commit, tx or search.", durationBuckets, []string{"operation"}),
+		Requests: sink.NewCounter("requests",
+			"Number of HTTP requests served by the RPC servers, by status code.", []string{"code"}),
+		RequestDurationSeconds: sink.NewHistogram("request_duration_seconds",
+			"Duration of the HTTP requests served by the RPC servers, in seconds.", durationBuckets, nil),
+		ResponseBytes: sink.NewCounter("response_bytes",
+			"Number of bytes of the HTTP responses written by the RPC servers.", nil),
+		WebsocketSlowClientsClosed: sink.NewCounter("websocket_slow_clients_closed",
+			"Number of websocket connections closed because their send buffer was full.", nil),
+	}
+}
+
+// metricsHandler records the requests served by h in m. Websocket connections
+// are not recorded.
+func metricsHandler(h http.Handler, m *Metrics) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		mw := &metricsResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(mw, r)
+		if mw.hijacked {
+			return
+		}
+		m.Requests.With("code", strconv.Itoa(mw.status)).Add(1)
+		m.RequestDurationSeconds.Observe(time.Since(start).Seconds())
+		m.ResponseBytes.Add(float64(mw.written))
+	})
+}
+
+// metricsResponseWriter records the status and size of a response.
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	written     int
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (w *metricsResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *metricsResponseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.written += n
+	return n, err
+}
+
+// Flush implements http.Flusher.
+func (w *metricsResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, which is required by the websocket handler.
+func (w *metricsResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	w.hijacked = true
+	return hj.Hijack()
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by
+// http.ResponseController.
+func (w *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,72 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/stretchr/testify/require"
+)
+
+// testSink is a MetricsSink recording the sums of the values added to its
+// counters, and the number of values observed by its histograms, by name and
+// label values.
+type testSink map[string]float64
+
+type testMetric struct {
+	sink testSink
+	name string
+	lvs  []string
+}
+
+func (m testMetric) key() string { return strings.Join(append([]string{m.name}, m.lvs...), ",") }
+
+func (m testMetric) with(lvs ...string) testMetric {
+	return testMetric{sink: m.sink, name: m.name, lvs: append(append([]string{}, m.lvs...), lvs...)}
+}
+
+type testCounter struct{ testMetric }
+
+func (c testCounter) With(lvs ...string) metrics.Counter { return testCounter{c.with(lvs...)} }
+func (c testCounter) Add(delta float64)                  { c.sink[c.key()] += delta }
+
+type testHistogram struct{ testMetric }
+
+func (h testHistogram) With(lvs ...string) metrics.Histogram { return testHistogram{h.with(lvs...)} }
+func (h testHistogram) Observe(float64)                      { h.sink[h.key()]++ }
+
+func (s testSink) NewCounter(name, _ string, _ []string) metrics.Counter {
+	return testCounter{testMetric{sink: s, name: name}}
+}
+
+func (s testSink) NewGauge(string, string, []string) metrics.Gauge { return discard.NewGauge() }
+
+func (s testSink) NewHistogram(name, _ string, _ []float64, _ []string) metrics.Histogram {
+	return testHistogram{testMetric{sink: s, name: name}}
+}
+
+func TestMetricsSink(t *testing.T) {
+	sink := testSink{}
+	srv := &Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte("ok")) //nolint: errcheck
+		}),
+		Metrics: SinkMetrics(sink),
+	}
+	h := srv.handler()
+	for _, path := range []string{"/", "/", "/missing"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	require.Equal(t, float64(2), sink["requests,code,200"])
+	require.Equal(t, float64(1), sink["requests,code,404"])
+	require.Equal(t, float64(3), sink["request_duration_seconds"])
+	require.Equal(t, float64(2*len("ok")+len("404 page not found\n")), sink["response_bytes"])
+}
@@ -0,0 +1,266 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// routeHandler calls a route with the arguments decoded from a request and
+// returns its result.
+type routeHandler func(ctx *rpctypes.Context, args []reflect.Value) (interface{}, error)
+
+// routeMiddleware wraps the handler of the route with the given name.
+type routeMiddleware func(route string, next routeHandler) routeHandler
+
+var (
+	errorType       = reflect.TypeOf((*error)(nil)).Elem()
+	routeResultType = reflect.TypeOf(routeResult{})
+)
+
+// wrapRoute returns a function with the same arguments as the route function
+// f, which calls f through the route middlewares of the environment. The first
+// middleware is the outermost one. The function returns the result of f as a
+// routeResult, encoded as the response is.
+func (env *environment) wrapRoute(route string, f interface{}) interface{} {
+	fv := reflect.ValueOf(f)
+	ft := fv.Type()
+	h := routeHandler(func(ctx *rpctypes.Context, args []reflect.Value) (interface{}, error) {
+		returns := fv.Call(append([]reflect.Value{reflect.ValueOf(ctx)}, args...))
+		if err, _ := returns[1].Interface().(error); err != nil {
+			return nil, err
+		}
+		return returns[0].Interface(), nil
+	})
+	for i := len(env.routeMiddlewares) - 1; i >= 0; i-- {
+		h = env.routeMiddlewares[i](route, h)
+	}
+
+	in := make([]reflect.Type, ft.NumIn())
+	for i := range in {
+		in[i] = ft.In(i)
+	}
+	protobufTimestamps := env.Config.TimestampFormat == TimestampFormatProtobuf
+	ft = reflect.FuncOf(in, []reflect.Type{routeResultType, errorType}, ft.IsVariadic())
+	return reflect.MakeFunc(ft, func(in []reflect.Value) []reflect.Value {
+		ctx, _ := in[0].Interface().(*rpctypes.Context)
+		result, err := h(ctx, in[1:])
+		if err != nil {
+			return []reflect.Value{reflect.Zero(routeResultType), reflect.ValueOf(&err).Elem()}
+		}
+		return []reflect.Value{
+			reflect.ValueOf(routeResult{
+				value:              result,
+				protobufTimestamps: protobufTimestamps,
+				state:              stateFromContext(ctx),
+			}),
+			reflect.Zero(errorType),
+		}
+	}).Interface()
+}
+
+// routeResult is the result of a route, as returned to the JSON-RPC server.
+// It is encoded with the timestamps in the format of the environment, or
+// deferred to be encoded as the response is written if the request state
+// streams the results.
+type routeResult struct {
+	value              interface{}
+	protobufTimestamps bool
+	state              *requestState
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r routeResult) MarshalJSON() ([]byte, error) {
+	if r.state != nil && r.state.streamResults {
+		return r.state.deferResult(r), nil
+	}
+	if r.protobufTimestamps {
+		return marshalProtobufTimestamps(r.value)
+	}
+	return cmtjson.Marshal(r.value)
+}
+
+// requestState holds the information the route handlers pass back to the HTTP
+// handler serving the request.
+type requestState struct {
+	retryAfter time.Duration
+	// eventSink is the name of the event sink which served the searches of
+	// the request, if several are set.
+	eventSink string
+	// onDone is called once the response has been written.
+	onDone []func()
+
+	// streamResults defers the encoding of the results of the routes to
+	// the writing of the response, which replaces the tokens returned by
+	// deferResult with the results.
+	streamResults bool
+	// tokenPrefix starts the tokens standing for the deferred results in
+	// the response. It holds a random nonce, so that the strings of the
+	// results are not taken for tokens.
+	tokenPrefix     string
+	deferredResults []routeResult
+}
+
+// initTokens generates the prefix of the tokens of the request, and returns
+// false if it cannot be generated.
+func (s *requestState) initTokens() bool {
+	if s.tokenPrefix != "" {
+		return true
+	}
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		return false
+	}
+	s.tokenPrefix = "inspect-" + hex.EncodeToString(nonce) + "-"
+	return true
+}
+
+// resultTokenPrefix returns the prefix of the tokens of the deferred results,
+// which end with their index in deferredResults.
+func (s *requestState) resultTokenPrefix() string {
+	return s.tokenPrefix + "result-"
+}
+
+// deferResult defers the encoding of result, and returns the JSON string
+// token which stands for it in the response until then.
+func (s *requestState) deferResult(result routeResult) []byte {
+	s.deferredResults = append(s.deferredResults, result)
+	return []byte(`"` + s.resultTokenPrefix() + strconv.Itoa(len(s.deferredResults)-1) + `"`)
+}
+
+// stateEffects are the changes made by a route call to the state of its
+// request. The route cache and the coalescing of requests record them with
+// the results they share, and replay them on the requests served with them.
+type stateEffects struct {
+	retryAfter time.Duration
+	eventSink  string
+}
+
+// callRecordingEffects calls next with a request state of its own, and
+// returns the effects of the call on it, which are not applied to the state
+// of the request of ctx.
+func callRecordingEffects(
+	ctx *rpctypes.Context,
+	args []reflect.Value,
+	next routeHandler,
+) (interface{}, stateEffects, error) {
+	if stateFromContext(ctx) == nil {
+		result, err := next(ctx, args)
+		return result, stateEffects{}, err
+	}
+	state := &requestState{}
+	callCtx := *ctx
+	callCtx.HTTPReq = ctx.HTTPReq.WithContext(context.WithValue(ctx.HTTPReq.Context(), requestStateKey{}, state))
+	result, err := next(&callCtx, args)
+	return result, stateEffects{retryAfter: state.retryAfter, eventSink: state.eventSink}, err
+}
+
+// apply applies the effects to the state of the request of ctx.
+func (e stateEffects) apply(ctx *rpctypes.Context) {
+	state := stateFromContext(ctx)
+	if state == nil {
+		return
+	}
+	if e.retryAfter > 0 {
+		state.retryAfter = e.retryAfter
+	}
+	if e.eventSink != "" {
+		state.eventSink = e.eventSink
+	}
+}
+
+type requestStateKey struct{}
+
+// stateFromContext returns the state of the HTTP request serving ctx, or nil
+// if ctx is not served over HTTP by the Inspector handler.
+func stateFromContext(ctx *rpctypes.Context) *requestState {
+	if ctx == nil || ctx.HTTPReq == nil {
+		return nil
+	}
+	return requestStateFrom(ctx.HTTPReq.Context())
+}
+
+// requestStateFrom returns the state of the HTTP request whose context is ctx,
+// or nil if the request is not served by the Inspector handler.
+func requestStateFrom(ctx context.Context) *requestState {
+	state, _ := ctx.Value(requestStateKey{}).(*requestState)
+	return state
+}
+
+// requestStateHandler attaches a requestState to every request and applies it
+// to the response once the route handlers have run.
+func requestStateHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := &requestState{}
+		defer func() {
+			for _, f := range state.onDone {
+				f()
+			}
+		}()
+		r = r.WithContext(context.WithValue(r.Context(), requestStateKey{}, state))
+		h.ServeHTTP(&stateResponseWriter{ResponseWriter: w, state: state}, r)
+	})
+}
+
+// stateResponseWriter applies the requestState of a request to the response
+// headers before they are written.
+type stateResponseWriter struct {
+	http.ResponseWriter
+	state       *requestState
+	wroteHeader bool
+}
+
+func (w *stateResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if w.state.eventSink != "" {
+			w.Header().Set(EventSinkHeader, w.state.eventSink)
+		}
+		if w.state.retryAfter > 0 {
+			w.Header().Set("Retry-After", retryAfterSeconds(w.state.retryAfter))
+			if status >= http.StatusInternalServerError {
+				status = http.StatusServiceUnavailable
+			}
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *stateResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (w *stateResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, which is required by the websocket handler.
+func (w *stateResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	return hj.Hijack()
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by
+// http.ResponseController.
+func (w *stateResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
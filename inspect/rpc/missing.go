@@ -0,0 +1,73 @@
+package rpc
+
+import (
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// defaultMaxHeightsScan is the default maximum number of heights scanned by
+// the missing_heights route.
+const defaultMaxHeightsScan = 10_000
+
+// MaxHeightsScan sets the maximum number of heights whose block meta is read
+// by a call to the missing_heights route. It defaults to 10000.
+func MaxHeightsScan(count int64) RoutesOption {
+	return func(env *environment) {
+		env.maxHeightsScan = count
+	}
+}
+
+// HeightRange is a range of heights, from MinHeight to MaxHeight included.
+type HeightRange struct {
+	MinHeight int64 `json:"min_height"`
+	MaxHeight int64 `json:"max_height"`
+}
+
+// ResultMissingHeights is the result of the missing_heights route.
+type ResultMissingHeights struct {
+	Base       int64 `json:"base"`
+	LastHeight int64 `json:"last_height"`
+	// Scanned is the range of heights scanned, which is limited to the
+	// maximum number of heights scanned per call.
+	Scanned      HeightRange   `json:"scanned"`
+	MissingCount int64         `json:"missing_count"`
+	Missing      []HeightRange `json:"missing"`
+}
+
+// MissingHeights returns the ranges of heights of
+// minHeight <= height <= maxHeight missing from the block store, that is,
+// whose block meta is not stored, in ascending order.
+//
+// The range is resolved as in the blockchain route, but limited to the most
+// recent heights up to the maximum set with MaxHeightsScan. Older heights are
+// scanned by calling the route again with a maxHeight below the scanned range.
+func (env *environment) MissingHeights(
+	ctx *rpctypes.Context,
+	minHeight, maxHeight int64,
+) (*ResultMissingHeights, error) {
+	minHeight, maxHeight, err := env.heightRangeSpan(minHeight, maxHeight, env.maxHeightsScan)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ResultMissingHeights{
+		Base:       env.BlockStore.Base(),
+		LastHeight: env.BlockStore.Height(),
+		Scanned:    HeightRange{MinHeight: minHeight, MaxHeight: maxHeight},
+		Missing:    make([]HeightRange, 0),
+	}
+	for height := minHeight; height <= maxHeight; height++ {
+		if err := requestContext(ctx).Err(); err != nil {
+			return nil, err
+		}
+		if env.BlockStore.LoadBlockMeta(height) != nil {
+			continue
+		}
+		res.MissingCount++
+		if n := len(res.Missing); n > 0 && res.Missing[n-1].MaxHeight == height-1 {
+			res.Missing[n-1].MaxHeight = height
+		} else {
+			res.Missing = append(res.Missing, HeightRange{MinHeight: height, MaxHeight: height})
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,37 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestMissingHeights(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(10))
+	for height := int64(1); height <= 10; height++ {
+		switch height {
+		case 3, 4, 5, 8:
+			blockStoreMock.On("LoadBlockMeta", height).Return(nil)
+		default:
+			blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{})
+		}
+	}
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{}, MaxHeightsScan(7))
+
+	res, err := env.MissingHeights(nil, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, HeightRange{MinHeight: 4, MaxHeight: 10}, res.Scanned)
+	require.Equal(t, int64(3), res.MissingCount)
+	require.Equal(t, []HeightRange{{MinHeight: 4, MaxHeight: 5}, {MinHeight: 8, MaxHeight: 8}}, res.Missing)
+
+	// The older heights are scanned below the scanned range.
+	res, err = env.MissingHeights(nil, 0, 3)
+	require.NoError(t, err)
+	require.Equal(t, HeightRange{MinHeight: 1, MaxHeight: 3}, res.Scanned)
+	require.Equal(t, []HeightRange{{MinHeight: 3, MaxHeight: 3}}, res.Missing)
+}
@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ClientCertPolicy sets the checks run on the certificates of the clients of
+// a server requiring mutual TLS, in addition to the verification of their
+// chain to the client CAs, see Server.ClientCAs.
+type ClientCertPolicy struct {
+	// StrictKeyUsage rejects the client certificates which do not list the
+	// client authentication extended key usage. crypto/x509 accepts
+	// certificates with no extended key usage at all for any usage. The
+	// certificates with a key usage extension must also allow digital
+	// signatures.
+	StrictKeyUsage bool
+
+	// CRLs are the revocation lists checked for the certificates of the
+	// chains, but for their root. A list is used for the certificates issued
+	// by its issuer, once its signature is verified with the issuer
+	// certificate of the chain; the lists are not refreshed.
+	CRLs []*x509.RevocationList
+
+	// Verify, if set, is run last on the verified chain, the client
+	// certificate first, for the checks done outside of the server, such as
+	// OCSP queries. The certificate is rejected if it returns an error.
+	Verify func(chain []*x509.Certificate) error
+}
+
+// clientTLSConfig sets config to require the clients to present a
+// certificate chaining to clientCAs and passing the checks of policy. The
+// rejected certificates are logged with the reason of their rejection.
+func (srv *Server) clientTLSConfig(config *tls.Config) {
+	config.ClientCAs = srv.ClientCAs
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	config.VerifyConnection = func(cs tls.ConnectionState) error {
+		if len(cs.VerifiedChains) == 0 {
+			// Resumed sessions were verified when established.
+			return nil
+		}
+		err := srv.ClientCertPolicy.verify(cs.VerifiedChains[0])
+		if err != nil {
+			srv.Logger.Info("Rejected TLS client certificate",
+				"subject", cs.VerifiedChains[0][0].Subject.String(), "reason", err)
+		}
+		return err
+	}
+}
+
+// verify runs the checks of the policy on a verified certificate chain.
+func (p ClientCertPolicy) verify(chain []*x509.Certificate) error {
+	if p.StrictKeyUsage {
+		if err := verifyClientKeyUsage(chain[0]); err != nil {
+			return err
+		}
+	}
+	for i := 0; i+1 < len(chain); i++ {
+		if err := p.verifyNotRevoked(chain[i], chain[i+1]); err != nil {
+			return err
+		}
+	}
+	if p.Verify != nil {
+		return p.Verify(chain)
+	}
+	return nil
+}
+
+func verifyClientKeyUsage(cert *x509.Certificate) error {
+	if !slices.Contains(cert.ExtKeyUsage, x509.ExtKeyUsageClientAuth) {
+		return errors.New("certificate does not allow client authentication")
+	}
+	if cert.KeyUsage != 0 && cert.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+		return errors.New("certificate does not allow digital signatures")
+	}
+	return nil
+}
+
+// verifyNotRevoked returns an error if cert is listed by one of the CRLs of
+// its issuer.
+func (p ClientCertPolicy) verifyNotRevoked(cert, issuer *x509.Certificate) error {
+	for _, crl := range p.CRLs {
+		if !bytes.Equal(crl.RawIssuer, cert.RawIssuer) || crl.CheckSignatureFrom(issuer) != nil {
+			continue
+		}
+		for _, revoked := range crl.RevokedCertificateEntries {
+			if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return fmt.Errorf("certificate %s of %q is revoked", cert.SerialNumber, cert.Subject.String())
+			}
+		}
+	}
+	return nil
+}
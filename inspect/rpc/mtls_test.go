@@ -0,0 +1,179 @@
+package rpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/libs/log"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCert(t *testing.T, template *x509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key}
+}
+
+func TestClientCertPolicy(t *testing.T) {
+	ca := newTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}, nil)
+	client := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "client"},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+	noEKU := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "no-eku"},
+	}, ca)
+	serverOnly := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(4),
+		Subject:      pkix.Name{CommonName: "server"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	encipherOnly := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(5),
+		Subject:      pkix.Name{CommonName: "encipher"},
+		KeyUsage:     x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	crlDER, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-time.Hour),
+		NextUpdate: time.Now().Add(time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{
+			{SerialNumber: big.NewInt(2), RevocationTime: time.Now()},
+		},
+	}, ca.cert, ca.key)
+	require.NoError(t, err)
+	crl, err := x509.ParseRevocationList(crlDER)
+	require.NoError(t, err)
+
+	chain := func(c *testCert) []*x509.Certificate { return []*x509.Certificate{c.cert, ca.cert} }
+
+	// Without checks, any verified chain is accepted.
+	require.NoError(t, ClientCertPolicy{}.verify(chain(noEKU)))
+
+	strict := ClientCertPolicy{StrictKeyUsage: true}
+	require.NoError(t, strict.verify(chain(client)))
+	require.ErrorContains(t, strict.verify(chain(noEKU)), "client authentication")
+	require.ErrorContains(t, strict.verify(chain(serverOnly)), "client authentication")
+	require.ErrorContains(t, strict.verify(chain(encipherOnly)), "digital signatures")
+
+	revoking := ClientCertPolicy{CRLs: []*x509.RevocationList{crl}}
+	require.ErrorContains(t, revoking.verify(chain(client)), "revoked")
+	require.NoError(t, revoking.verify(chain(noEKU)))
+
+	// A list which is not signed by the issuer of the chain is ignored.
+	otherCA := newTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}, nil)
+	require.NoError(t, revoking.verify([]*x509.Certificate{client.cert, otherCA.cert}))
+
+	hooked := ClientCertPolicy{Verify: func(chain []*x509.Certificate) error {
+		if chain[0].Subject.CommonName == "no-eku" {
+			return errors.New("ocsp: revoked")
+		}
+		return nil
+	}}
+	require.NoError(t, hooked.verify(chain(client)))
+	require.ErrorContains(t, hooked.verify(chain(noEKU)), "ocsp")
+}
+
+func TestClientTLSConfig(t *testing.T) {
+	ca := newTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	server := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		DNSNames:     []string{"inspect"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	srv := &Server{
+		Logger:           log.NewNopLogger(),
+		ClientCAs:        pool,
+		ClientCertPolicy: ClientCertPolicy{StrictKeyUsage: true},
+	}
+	serverConfig := &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{server.cert.Raw}, PrivateKey: server.key}},
+		MinVersion:   tls.VersionTLS12,
+	}
+	srv.clientTLSConfig(serverConfig)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	handshake := func(client *testCert) error {
+		clientConfig := &tls.Config{
+			RootCAs:    pool,
+			ServerName: "inspect",
+			MinVersion: tls.VersionTLS12,
+		}
+		if client != nil {
+			clientConfig.Certificates = []tls.Certificate{{Certificate: [][]byte{client.cert.Raw}, PrivateKey: client.key}}
+		}
+		go func() {
+			conn, err := tls.Dial("tcp", l.Addr().String(), clientConfig)
+			if err == nil {
+				conn.Close()
+			}
+		}()
+		conn, err := l.Accept()
+		require.NoError(t, err)
+		defer conn.Close()
+		return tls.Server(conn, serverConfig).Handshake()
+	}
+
+	require.Error(t, handshake(nil))
+	require.NoError(t, handshake(newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)))
+	require.ErrorContains(t, handshake(newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(4),
+	}, ca)), "client authentication")
+}
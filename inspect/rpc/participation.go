@@ -0,0 +1,71 @@
+package rpc
+
+import (
+	"github.com/cometbft/cometbft/libs/bits"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+// Participation is the set of validators which voted in the commit of a
+// block, as returned by the participation route.
+type Participation struct {
+	Height    int64 `json:"height"`
+	Round     int32 `json:"round"`
+	Canonical bool  `json:"canonical"`
+	// Signers has a bit for every validator of the set at Height, in
+	// validator set order, which is set if its vote is in the commit, for the
+	// block or for nil, as the slashing of the Cosmos SDK counts it.
+	Signers *bits.BitArray `json:"signers"`
+	Signed  int            `json:"signed"`
+}
+
+// ResultParticipation is the result of the participation route.
+type ResultParticipation struct {
+	LastHeight    int64           `json:"last_height"`
+	Participation []Participation `json:"participation"`
+}
+
+// Participation returns the validators which voted in the commits of the
+// blocks for minHeight <= height <= maxHeight, in ascending order, as bit
+// arrays rather than the signatures of the commits. The range is resolved as
+// in the blockchain route. As in the commit route, the commit of the latest
+// block is the non-canonical seen commit. The heights whose commit is missing
+// are skipped.
+func (env *environment) Participation(
+	_ *rpctypes.Context,
+	minHeight, maxHeight int64,
+) (*ResultParticipation, error) {
+	minHeight, maxHeight, err := env.heightRange(minHeight, maxHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	participation := loadHeights(env, minHeight, maxHeight, func(height int64) (Participation, bool) {
+		commit, canonical := env.loadCommit(height)
+		if commit == nil {
+			return Participation{}, false
+		}
+		return commitParticipation(commit, canonical), true
+	})
+
+	return &ResultParticipation{
+		LastHeight:    env.BlockStore.Height(),
+		Participation: participation,
+	}, nil
+}
+
+func commitParticipation(commit *types.Commit, canonical bool) Participation {
+	p := Participation{
+		Height:    commit.Height,
+		Round:     commit.Round,
+		Canonical: canonical,
+		Signers:   bits.NewBitArray(len(commit.Signatures)),
+	}
+	for i, sig := range commit.Signatures {
+		if sig.BlockIDFlag != types.BlockIDFlagAbsent {
+			p.Signers.SetIndex(i, true)
+			p.Signed++
+		}
+	}
+	return p
+}
@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestParticipation(t *testing.T) {
+	commit := func(height int64, flags ...types.BlockIDFlag) *types.Commit {
+		sigs := make([]types.CommitSig, len(flags))
+		for i, flag := range flags {
+			sigs[i] = types.CommitSig{BlockIDFlag: flag}
+		}
+		return &types.Commit{Height: height, Signatures: sigs}
+	}
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(4))
+	blockStoreMock.On("LoadBlockCommit", int64(1)).Return(nil)
+	blockStoreMock.On("LoadBlockCommit", int64(2)).Return(
+		commit(2, types.BlockIDFlagCommit, types.BlockIDFlagAbsent, types.BlockIDFlagNil))
+	blockStoreMock.On("LoadBlockCommit", int64(3)).Return(
+		commit(3, types.BlockIDFlagCommit, types.BlockIDFlagCommit, types.BlockIDFlagCommit))
+	blockStoreMock.On("LoadSeenCommit", int64(4)).Return(
+		commit(4, types.BlockIDFlagAbsent, types.BlockIDFlagCommit, types.BlockIDFlagCommit))
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{}, MaxRangeSpan(3))
+
+	res, err := env.Participation(nil, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), res.LastHeight)
+	require.Len(t, res.Participation, 3)
+
+	type participation struct {
+		height    int64
+		canonical bool
+		signers   string
+		signed    int
+	}
+	got := make([]participation, len(res.Participation))
+	for i, p := range res.Participation {
+		b, err := p.Signers.MarshalJSON()
+		require.NoError(t, err)
+		got[i] = participation{p.Height, p.Canonical, string(b), p.Signed}
+	}
+	require.Equal(t, []participation{
+		{2, true, `"x_x"`, 2},
+		{3, true, `"xxx"`, 3},
+		{4, false, `"_xx"`, 2},
+	}, got)
+
+	// The heights whose commit is missing are skipped.
+	res, err = env.Participation(nil, 1, 2)
+	require.NoError(t, err)
+	require.Len(t, res.Participation, 1)
+	require.Equal(t, int64(2), res.Participation[0].Height)
+}
@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// defaultPrettyIndent is the indent of pretty-printed responses when none is
+// set.
+const defaultPrettyIndent = "  "
+
+// PrettyJSON lets clients request indented JSON responses, for reading by
+// hand, with the pretty=true query parameter. Each level of the responses is
+// indented with indent, or two spaces if indent is empty. Only the
+// presentation of the responses is changed. Responses are never indented by
+// default.
+func PrettyJSON(indent string) HandlerOption {
+	return func(opts *handlerOptions) {
+		if indent == "" {
+			indent = defaultPrettyIndent
+		}
+		opts.prettyIndent = indent
+	}
+}
+
+// prettyHandler indents the JSON responses of h to the requests with the
+// pretty=true query parameter. All other requests are passed through to h
+// unchanged.
+func prettyHandler(h http.Handler, indent string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pretty, _ := strconv.ParseBool(r.URL.Query().Get("pretty"))
+		if !pretty || r.Header.Get("Upgrade") != "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &bufferedResponseWriter{header: make(http.Header), status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+
+		body := rec.body.Bytes()
+		if isJSON(rec.header.Get("Content-Type")) {
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, body, "", indent); err == nil {
+				body = indented.Bytes()
+				rec.header.Del("Content-Length")
+			}
+		}
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.status)
+		w.Write(body) //nolint: errcheck
+	})
+}
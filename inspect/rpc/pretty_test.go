@@ -0,0 +1,45 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	indexermocks "github.com/cometbft/cometbft/state/indexer/mocks"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
+)
+
+func TestPrettyJSON(t *testing.T) {
+	cfg := config.TestRPCConfig()
+	logger := log.NewNopLogger()
+	routes := Routes(*cfg, &statemocks.Store{}, &statemocks.BlockStore{},
+		&txindexmocks.TxIndexer{}, &indexermocks.BlockIndexer{}, logger)
+
+	serve := func(h http.Handler, target string) []byte {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec.Body.Bytes()
+	}
+
+	h := Handler(cfg, routes, logger, PrettyJSON("\t"))
+	plain := serve(h, "/health")
+	require.NotContains(t, string(plain), "\n")
+	pretty := serve(h, "/health?pretty=true")
+	require.Contains(t, string(pretty), "\n\t\"jsonrpc\"")
+
+	var compacted bytes.Buffer
+	require.NoError(t, json.Compact(&compacted, pretty))
+	require.Equal(t, plain, compacted.Bytes())
+
+	// Without the option, the parameter is ignored.
+	h = Handler(cfg, routes, logger)
+	require.Equal(t, plain, serve(h, "/health?pretty=true"))
+}
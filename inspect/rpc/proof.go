@@ -0,0 +1,58 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+// ResultVerifyProof is the result of the verify_proof route.
+type ResultVerifyProof struct {
+	Height int64 `json:"height"`
+	// DataHash is the root against which the proof was verified: the hash of
+	// the transactions in the stored header at Height.
+	DataHash bytes.HexBytes `json:"data_hash"`
+	Valid    bool           `json:"valid"`
+	// Error is the reason the proof is not valid.
+	Error string `json:"error,omitempty"`
+}
+
+// VerifyProof verifies a proof of inclusion of a transaction, as returned by
+// the tx route with prove set, against the hash of the transactions in the
+// stored header at the given height, or at the latest height if no height is
+// given. If leaf is not empty, it is the transaction whose inclusion is
+// verified instead of the data of the proof.
+//
+// An invalid proof is not an error: the result reports why the proof does not
+// verify.
+func (env *environment) VerifyProof(
+	_ *rpctypes.Context,
+	heightPtr *int64,
+	proof types.TxProof,
+	leaf []byte,
+) (*ResultVerifyProof, error) {
+	height, err := env.getHeight(env.BlockStore.Height(), heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	blockMeta := env.BlockStore.LoadBlockMeta(height)
+	if blockMeta == nil {
+		return nil, fmt.Errorf("block meta not found for height %d", height)
+	}
+	if len(leaf) > 0 {
+		proof.Data = leaf
+	}
+
+	res := &ResultVerifyProof{
+		Height:   height,
+		DataHash: blockMeta.Header.DataHash,
+		Valid:    true,
+	}
+	if err := proof.Validate(blockMeta.Header.DataHash); err != nil {
+		res.Valid = false
+		res.Error = err.Error()
+	}
+	return res, nil
+}
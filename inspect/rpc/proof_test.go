@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestVerifyProof(t *testing.T) {
+	txs := types.Txs{types.Tx("tx0"), types.Tx("tx1"), types.Tx("tx2")}
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(3))
+	blockStoreMock.On("LoadBlockMeta", int64(2)).Return(&types.BlockMeta{
+		Header: types.Header{Height: 2, DataHash: txs.Hash()},
+	})
+	blockStoreMock.On("LoadBlockMeta", int64(3)).Return(&types.BlockMeta{
+		Header: types.Header{Height: 3, DataHash: types.Txs{}.Hash()},
+	})
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{})
+	proof := txs.Proof(1)
+
+	height := int64(2)
+	res, err := env.VerifyProof(nil, &height, proof, nil)
+	require.NoError(t, err)
+	require.True(t, res.Valid)
+	require.EqualValues(t, txs.Hash(), res.DataHash)
+
+	// The leaf replaces the data of the proof.
+	res, err = env.VerifyProof(nil, &height, proof, txs[2])
+	require.NoError(t, err)
+	require.False(t, res.Valid)
+	require.NotEmpty(t, res.Error)
+
+	// The proof is for another block.
+	res, err = env.VerifyProof(nil, nil, proof, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), res.Height)
+	require.False(t, res.Valid)
+
+	t.Run("jsonrpc", func(t *testing.T) {
+		proofJSON, err := cmtjson.Marshal(proof)
+		require.NoError(t, err)
+		h := newTestHandler(blockStoreMock, &statemocks.Store{})
+		rpcRes := callJSONRPC(t, h, "verify_proof", fmt.Sprintf(`{"height":"2","proof":%s}`, proofJSON))
+		require.Nil(t, rpcRes.Error)
+		var result ResultVerifyProof
+		require.NoError(t, cmtjson.Unmarshal(rpcRes.Result, &result))
+		require.True(t, result.Valid)
+	})
+}
@@ -0,0 +1,189 @@
+package rpc
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/cometbft/cometbft/libs/pubsub/query/syntax"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// codeInvalidParams is the JSON-RPC error code returned for invalid method
+// parameters.
+const codeInvalidParams = -32602
+
+// queryOperators maps the names of the operators of the query language, as
+// listed in the configuration, to their tokens.
+var queryOperators = map[string]syntax.Token{
+	"=":        syntax.TEq,
+	"<":        syntax.TLt,
+	"<=":       syntax.TLeq,
+	">":        syntax.TGt,
+	">=":       syntax.TGeq,
+	"CONTAINS": syntax.TContains,
+	"EXISTS":   syntax.TExists,
+}
+
+// queryOperatorCosts are the costs of the conditions of the queries by
+// operator, in proportion to the share of the index the indexers scan to match
+// them: a single key for equality, a range of keys for comparisons, and every
+// key of the attribute for EXISTS and CONTAINS, the latter also matching the
+// values.
+var queryOperatorCosts = map[syntax.Token]int{
+	syntax.TEq:       1,
+	syntax.TLt:       2,
+	syntax.TLeq:      2,
+	syntax.TGt:       2,
+	syntax.TGeq:      2,
+	syntax.TExists:   3,
+	syntax.TContains: 4,
+}
+
+// queryRoutes are the routes taking a query as their first argument.
+var queryRoutes = map[string]bool{
+	"tx_search":    true,
+	"block_search": true,
+}
+
+// ValidateQueryOperators returns an error if any of the operators is not an
+// operator of the query language.
+func ValidateQueryOperators(operators []string) error {
+	var unknown []string
+	for _, op := range operators {
+		if _, ok := queryOperators[strings.ToUpper(op)]; !ok {
+			unknown = append(unknown, op)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown query operators: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// queryOperatorMiddleware rejects the queries of the search routes using
+// operators other than the allowed ones, before running them.
+func queryOperatorMiddleware(allowed []string) routeMiddleware {
+	check := queryOperatorChecker(allowed)
+	return func(route string, next routeHandler) routeHandler {
+		if !queryRoutes[route] {
+			return next
+		}
+		return func(ctx *rpctypes.Context, args []reflect.Value) (interface{}, error) {
+			if len(args) > 0 {
+				q, _ := args[0].Interface().(string)
+				if err := check(q); err != nil {
+					return nil, &rpctypes.RPCError{
+						Code:    codeInvalidParams,
+						Message: "Invalid params",
+						Data:    err.Error(),
+					}
+				}
+			}
+			return next(ctx, args)
+		}
+	}
+}
+
+// queryOperatorChecker returns a function returning an error for the queries
+// using operators other than the allowed ones. Queries which do not parse are
+// left to the caller to report.
+func queryOperatorChecker(allowed []string) func(q string) error {
+	allowedTokens := make(map[syntax.Token]bool, len(allowed))
+	names := make([]string, 0, len(allowed))
+	for _, op := range allowed {
+		if tok, ok := queryOperators[strings.ToUpper(op)]; ok {
+			allowedTokens[tok] = true
+			names = append(names, strings.ToUpper(op))
+		}
+	}
+	sort.Strings(names)
+
+	return func(q string) error {
+		conditions, err := syntax.Parse(q)
+		if err != nil {
+			return nil
+		}
+		for _, cond := range conditions {
+			if !allowedTokens[cond.Op] {
+				return fmt.Errorf("%s is not allowed in queries; allowed operators: %s",
+					cond.Op, strings.Join(names, " "))
+			}
+		}
+		return nil
+	}
+}
+
+// ScoreQuery returns the complexity score of the query q: the sum of the costs
+// of its conditions, which are 1 for =, 2 for <, <=, > and >=, 3 for EXISTS and
+// 4 for CONTAINS. It returns an error if q does not parse.
+func ScoreQuery(q string) (int, error) {
+	conditions, err := syntax.Parse(q)
+	if err != nil {
+		return 0, err
+	}
+	score := 0
+	for _, cond := range conditions {
+		score += queryOperatorCosts[cond.Op]
+	}
+	return score, nil
+}
+
+// MaxQueryComplexity sets the maximum complexity score, as returned by
+// ScoreQuery, of the queries of tx_search and block_search. Queries scoring
+// above it are rejected before running them. The query_complexity route lets
+// clients score their queries beforehand. A value of 0, the default, disables
+// the limit.
+func MaxQueryComplexity(score int) RoutesOption {
+	return func(env *environment) {
+		env.maxQueryComplexity = score
+	}
+}
+
+// ResultQueryComplexity is the response of the query_complexity route.
+type ResultQueryComplexity struct {
+	Score int `json:"score"`
+	// MaxScore is the maximum score of the queries accepted by the search
+	// routes, or 0 if unlimited.
+	MaxScore int  `json:"max_score"`
+	Accepted bool `json:"accepted"`
+}
+
+// QueryComplexity returns the complexity score of the query, the maximum
+// score accepted by the search routes and whether the query is accepted.
+func (env *environment) QueryComplexity(_ *rpctypes.Context, query string) (*ResultQueryComplexity, error) {
+	score, err := ScoreQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultQueryComplexity{
+		Score:    score,
+		MaxScore: env.maxQueryComplexity,
+		Accepted: env.maxQueryComplexity == 0 || score <= env.maxQueryComplexity,
+	}, nil
+}
+
+// queryComplexityMiddleware rejects the queries of the search routes scoring
+// above maxScore, before running them.
+func queryComplexityMiddleware(maxScore int) routeMiddleware {
+	return func(route string, next routeHandler) routeHandler {
+		if !queryRoutes[route] {
+			return next
+		}
+		return func(ctx *rpctypes.Context, args []reflect.Value) (interface{}, error) {
+			if len(args) > 0 {
+				q, _ := args[0].Interface().(string)
+				// Queries which do not parse are left to the route to report.
+				if score, err := ScoreQuery(q); err == nil && score > maxScore {
+					return nil, &rpctypes.RPCError{
+						Code:    codeInvalidParams,
+						Message: "Invalid params",
+						Data:    fmt.Sprintf("query complexity score %d exceeds the maximum of %d", score, maxScore),
+					}
+				}
+			}
+			return next(ctx, args)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/config"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/cometbft/cometbft/libs/log"
+	indexermocks "github.com/cometbft/cometbft/state/indexer/mocks"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
+)
+
+func TestAllowedQueryOperators(t *testing.T) {
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	txIndexerMock.On("Search", mock.Anything, mock.Anything).Return([]*abcitypes.TxResult{}, nil)
+	blockIndexerMock := &indexermocks.BlockIndexer{}
+	blockIndexerMock.On("Search", mock.Anything, mock.Anything).Return([]int64{}, nil)
+	cfg := config.TestRPCConfig()
+	cfg.AllowedQueryOperators = []string{"=", "exists"}
+	logger := log.NewNopLogger()
+	routes := Routes(*cfg, &statemocks.Store{}, &statemocks.BlockStore{}, txIndexerMock, blockIndexerMock, logger)
+	h := Handler(cfg, routes, logger)
+
+	res := callJSONRPC(t, h, "tx_search", `{"query":"tx.height = 5 AND transfer.sender EXISTS"}`)
+	require.Nil(t, res.Error)
+	res = callJSONRPC(t, h, "block_search", `{"query":"block.height = 5"}`)
+	require.Nil(t, res.Error)
+
+	res = callJSONRPC(t, h, "tx_search", `{"query":"tx.height = 5 AND transfer.sender CONTAINS 'a'"}`)
+	require.NotNil(t, res.Error)
+	require.Equal(t, codeInvalidParams, res.Error.Code)
+	require.Contains(t, res.Error.Data, "CONTAINS operator is not allowed")
+	res = callJSONRPC(t, h, "block_search", `{"query":"block.height > 5"}`)
+	require.NotNil(t, res.Error)
+	require.Equal(t, codeInvalidParams, res.Error.Code)
+	txIndexerMock.AssertNumberOfCalls(t, "Search", 1)
+	blockIndexerMock.AssertNumberOfCalls(t, "Search", 1)
+}
+
+func TestValidateQueryOperators(t *testing.T) {
+	require.NoError(t, ValidateQueryOperators(nil))
+	require.NoError(t, ValidateQueryOperators([]string{"=", "<=", "contains"}))
+	require.ErrorContains(t, ValidateQueryOperators([]string{"=", "LIKE", "!="}), "LIKE, !=")
+}
+
+func TestMaxQueryComplexity(t *testing.T) {
+	score, err := ScoreQuery("tx.height = 5 AND tx.height > 1 AND transfer.sender EXISTS AND transfer.memo CONTAINS 'a'")
+	require.NoError(t, err)
+	require.Equal(t, 10, score)
+	_, err = ScoreQuery("tx.height ==")
+	require.Error(t, err)
+
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	txIndexerMock.On("Search", mock.Anything, mock.Anything).Return([]*abcitypes.TxResult{}, nil)
+	cfg := config.TestRPCConfig()
+	logger := log.NewNopLogger()
+	routes := Routes(*cfg, &statemocks.Store{}, &statemocks.BlockStore{}, txIndexerMock, &indexermocks.BlockIndexer{},
+		logger, MaxQueryComplexity(5))
+	h := Handler(cfg, routes, logger)
+
+	res := callJSONRPC(t, h, "tx_search", `{"query":"tx.height > 5 AND tx.height < 10"}`)
+	require.Nil(t, res.Error)
+	res = callJSONRPC(t, h, "tx_search", `{"query":"tx.height > 5 AND transfer.sender CONTAINS 'a'"}`)
+	require.NotNil(t, res.Error)
+	require.Equal(t, codeInvalidParams, res.Error.Code)
+	require.Equal(t, "query complexity score 6 exceeds the maximum of 5", res.Error.Data)
+	txIndexerMock.AssertNumberOfCalls(t, "Search", 1)
+
+	res = callJSONRPC(t, h, "query_complexity", `{"query":"tx.height > 5 AND transfer.sender CONTAINS 'a'"}`)
+	require.Nil(t, res.Error)
+	var result ResultQueryComplexity
+	require.NoError(t, cmtjson.Unmarshal(res.Result, &result))
+	require.Equal(t, ResultQueryComplexity{Score: 6, MaxScore: 5, Accepted: false}, result)
+}
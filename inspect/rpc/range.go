@@ -0,0 +1,224 @@
+package rpc
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	cmtmath "github.com/cometbft/cometbft/libs/math"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+// defaultMaxRangeSpan is the default maximum number of heights returned by
+// the routes operating on a range of heights.
+const defaultMaxRangeSpan = 100
+
+// MaxRangeSpan sets the maximum number of heights returned by the routes
+// operating on a range of heights, such as apphash_range. A span of 0 or less
+// keeps the default of 100 heights.
+func MaxRangeSpan(span int64) RoutesOption {
+	return func(env *environment) {
+		if span > 0 {
+			env.maxRangeSpan = span
+		}
+	}
+}
+
+// RangeReadConcurrency sets the number of heights read from the block store
+// at once by the block_metas, apphash_range, validator_hashes_range,
+// tx_counts_range, block_sizes_range, versions_range, header_hashes,
+// block_id_range, participation and commit_rounds routes, which otherwise read
+// the heights one at a time. The heights are still returned in ascending
+// order. Other range routes, such as block_interval_stats and missing_heights,
+// always read the heights one at a time. Parallel reads speed up the routes on
+// stores serving concurrent reads well, such as those on SSDs.
+func RangeReadConcurrency(n int) RoutesOption {
+	return func(env *environment) {
+		env.rangeReadConcurrency = n
+	}
+}
+
+// loadHeights calls load for minHeight <= height <= maxHeight, with at most
+// env.rangeReadConcurrency calls running at once, and returns the values for
+// which load returns true, in ascending order of height.
+func loadHeights[T any](env *environment, minHeight, maxHeight int64, load func(height int64) (T, bool)) []T {
+	n := int(maxHeight - minHeight + 1)
+	values := make([]T, n)
+	found := make([]bool, n)
+	workers := min(env.rangeReadConcurrency, n)
+	if workers <= 1 {
+		for i := range values {
+			values[i], found[i] = load(minHeight + int64(i))
+		}
+	} else {
+		var (
+			next atomic.Int64
+			wg   sync.WaitGroup
+		)
+		wg.Add(workers)
+		for w := 0; w < workers; w++ {
+			go func() {
+				defer wg.Done()
+				for i := next.Add(1) - 1; i < int64(n); i = next.Add(1) - 1 {
+					values[i], found[i] = load(minHeight + i)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	loaded := values[:0]
+	for i, value := range values {
+		if found[i] {
+			loaded = append(loaded, value)
+		}
+	}
+	return loaded
+}
+
+// blockMetaRange resolves minHeight and maxHeight as heightRange does, and
+// returns the values projected by project from the block metas of the range,
+// read with loadHeights, in ascending order of height. Heights missing from
+// the block store are skipped.
+func blockMetaRange[T any](
+	env *environment,
+	minHeight, maxHeight int64,
+	project func(height int64, blockMeta *types.BlockMeta) T,
+) ([]T, error) {
+	minHeight, maxHeight, err := env.heightRange(minHeight, maxHeight)
+	if err != nil {
+		return nil, err
+	}
+	return loadHeights(env, minHeight, maxHeight, func(height int64) (T, bool) {
+		blockMeta := env.BlockStore.LoadBlockMeta(height)
+		if blockMeta == nil {
+			var zero T
+			return zero, false
+		}
+		return project(height, blockMeta), true
+	}), nil
+}
+
+// heightRange resolves minHeight and maxHeight to the range of heights
+// available in the block store. As with the blockchain route, a minHeight of
+// 0 defaults to the base, a maxHeight of 0 defaults to the latest height and
+// the range is limited to the most recent maxRangeSpan heights.
+func (env *environment) heightRange(minHeight, maxHeight int64) (int64, int64, error) {
+	return env.heightRangeSpan(minHeight, maxHeight, env.maxRangeSpan)
+}
+
+// heightRangeSpan resolves minHeight and maxHeight as heightRange does, with
+// the range limited to the most recent span heights.
+func (env *environment) heightRangeSpan(minHeight, maxHeight, span int64) (int64, int64, error) {
+	if minHeight < 0 || maxHeight < 0 {
+		return minHeight, maxHeight, fmt.Errorf("heights must be non-negative")
+	}
+
+	base, height := env.BlockStore.Base(), env.BlockStore.Height()
+	if minHeight == 0 {
+		minHeight = 1
+	}
+	if maxHeight == 0 {
+		maxHeight = height
+	}
+	maxHeight = cmtmath.MinInt64(height, maxHeight)
+	minHeight = cmtmath.MaxInt64(base, minHeight)
+	minHeight = cmtmath.MaxInt64(minHeight, maxHeight-span+1)
+
+	if minHeight > maxHeight {
+		return minHeight, maxHeight, fmt.Errorf("min height %d can't be greater than max height %d",
+			minHeight, maxHeight)
+	}
+	return minHeight, maxHeight, nil
+}
+
+const (
+	// codeHeightAboveLatest is the JSON-RPC error code returned for heights
+	// above the latest height.
+	codeHeightAboveLatest = -32002
+	// codeHeightBelowBase is the JSON-RPC error code returned for heights
+	// below the base of the block store, which were pruned.
+	codeHeightBelowBase = -32003
+)
+
+// heightArgRoutes maps the routes taking an optional height argument to the
+// latest height they accept. The height argument is the first argument of all
+// of these routes.
+var heightArgRoutes = map[string]func(env *environment) int64{
+	"block":                      func(env *environment) int64 { return env.BlockStore.Height() },
+	"block_id":                   func(env *environment) int64 { return env.BlockStore.Height() },
+	"block_txs":                  func(env *environment) int64 { return env.BlockStore.Height() },
+	"block_results":              func(env *environment) int64 { return env.BlockStore.Height() },
+	"block_time_context":         func(env *environment) int64 { return env.BlockStore.Height() },
+	"block_with_commit":          func(env *environment) int64 { return env.BlockStore.Height() },
+	"commit":                     func(env *environment) int64 { return env.BlockStore.Height() },
+	"commit_signers":             func(env *environment) int64 { return env.BlockStore.Height() },
+	"decoded_events":             func(env *environment) int64 { return env.BlockStore.Height() },
+	"events":                     func(env *environment) int64 { return env.BlockStore.Height() },
+	"evidence":                   func(env *environment) int64 { return env.BlockStore.Height() },
+	"header":                     func(env *environment) int64 { return env.BlockStore.Height() },
+	"header_commit_proof":        func(env *environment) int64 { return env.BlockStore.Height() },
+	"header_verification_bundle": func(env *environment) int64 { return env.BlockStore.Height() },
+	"last_commit_in_block":       func(env *environment) int64 { return env.BlockStore.Height() },
+	"verify_proof":               func(env *environment) int64 { return env.BlockStore.Height() },
+	// As in the node, the validators and consensus params are known for the
+	// height after the latest block.
+	"validators":               func(env *environment) int64 { return env.BlockStore.Height() + 1 },
+	"validators_with_priority": func(env *environment) int64 { return env.BlockStore.Height() + 1 },
+	"consensus_params":         func(env *environment) int64 { return env.BlockStore.Height() + 1 },
+	"voting_power":             func(env *environment) int64 { return env.BlockStore.Height() + 1 },
+}
+
+// heightMiddleware checks the height argument of the routes taking one before
+// calling them, so that heights above the latest height and below the base are
+// reported with distinct error codes.
+func heightMiddleware(env *environment) routeMiddleware {
+	return func(route string, next routeHandler) routeHandler {
+		latestHeight, ok := heightArgRoutes[route]
+		if !ok {
+			return next
+		}
+		return func(ctx *rpctypes.Context, args []reflect.Value) (interface{}, error) {
+			if len(args) > 0 {
+				heightPtr, _ := args[0].Interface().(*int64)
+				if _, err := env.getHeight(latestHeight(env), heightPtr); err != nil {
+					return nil, err
+				}
+			}
+			return next(ctx, args)
+		}
+	}
+}
+
+// getHeight returns the height pointed to by heightPtr, or latestHeight if
+// heightPtr is nil. As in the node's routes, the height must be positive, at
+// most latestHeight and at least the base of the block store. Heights above
+// latestHeight or below the base are reported with the codeHeightAboveLatest
+// and codeHeightBelowBase error codes.
+func (env *environment) getHeight(latestHeight int64, heightPtr *int64) (int64, error) {
+	if heightPtr == nil {
+		return latestHeight, nil
+	}
+	height := *heightPtr
+	if height <= 0 {
+		return 0, fmt.Errorf("height must be greater than 0, but got %d", height)
+	}
+	if height > latestHeight {
+		return 0, &rpctypes.RPCError{
+			Code:    codeHeightAboveLatest,
+			Message: "Height above latest",
+			Data: fmt.Sprintf("height %d must be less than or equal to the current blockchain height %d",
+				height, latestHeight),
+		}
+	}
+	if base := env.BlockStore.Base(); height < base {
+		return 0, &rpctypes.RPCError{
+			Code:    codeHeightBelowBase,
+			Message: "Height below base",
+			Data:    fmt.Sprintf("height %d is not available, lowest height is %d", height, base),
+		}
+	}
+	return height, nil
+}
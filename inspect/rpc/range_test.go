@@ -0,0 +1,48 @@
+package rpc
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+)
+
+func TestLoadHeights(t *testing.T) {
+	for _, concurrency := range []int{0, 1, 4, 100} {
+		env := &environment{rangeReadConcurrency: concurrency}
+		var running, maxRunning atomic.Int64
+		loaded := loadHeights(env, 3, 22, func(height int64) (int64, bool) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			// The later heights complete first.
+			time.Sleep(time.Duration(22-height) * time.Millisecond)
+			return height, height%5 != 0
+		})
+
+		require.Equal(t, []int64{3, 4, 6, 7, 8, 9, 11, 12, 13, 14, 16, 17, 18, 19, 21, 22}, loaded)
+		require.LessOrEqual(t, maxRunning.Load(), int64(max(concurrency, 1)))
+	}
+}
+
+func TestMaxRangeSpan(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(500))
+
+	for span, expected := range map[int64]int64{-1: 401, 0: 401, 10: 491} {
+		env := newTestEnvironment(blockStoreMock, &statemocks.Store{}, MaxRangeSpan(span))
+		minHeight, maxHeight, err := env.heightRange(0, 0)
+		require.NoError(t, err)
+		require.Equal(t, expected, minHeight)
+		require.Equal(t, int64(500), maxHeight)
+	}
+}
@@ -0,0 +1,78 @@
+package rpc
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// OctetStreamMediaType is the media type of the responses of the commit_raw
+// route.
+const OctetStreamMediaType = "application/octet-stream"
+
+// RawCommitStore is a block store which loads the commits as stored, such as
+// store.BlockStore.
+type RawCommitStore interface {
+	Base() int64
+	Height() int64
+	// LoadBlockCommitBytes returns the Protobuf encoding of the commit for
+	// the block at height, or nil if it is not found.
+	LoadBlockCommitBytes(height int64) []byte
+}
+
+// RawCommits serves the commits stored in bs under /commit_raw, as the bytes
+// of their Protobuf encoding read from the store, without decoding them.
+//
+// The route takes an optional height parameter in its URL, such as
+// /commit_raw?height=5, and responds with OctetStreamMediaType. It defaults to
+// the commit of the block before the latest one: only the canonical commits
+// are served, and the commit of the latest block is only included in the
+// next one.
+func RawCommits(bs RawCommitStore) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.rawCommits = bs
+	}
+}
+
+// rawCommitHandler serves the commit_raw route on bs.
+func rawCommitHandler(bs RawCommitStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		latest := bs.Height() - 1
+		height := latest
+		// Accept the height quoted, as in the URI requests, or not.
+		if param := strings.Trim(r.URL.Query().Get("height"), `"`); param != "" {
+			var err error
+			height, err = strconv.ParseInt(param, 10, 64)
+			if err != nil || height <= 0 {
+				http.Error(w, fmt.Sprintf("invalid height %q", param), http.StatusBadRequest)
+				return
+			}
+		}
+		if height > latest {
+			http.Error(w, fmt.Sprintf("height %d must be less than or equal to the height of the latest commit %d",
+				height, latest), http.StatusNotFound)
+			return
+		}
+		if base := bs.Base(); height < base {
+			http.Error(w, fmt.Sprintf("height %d is not available, lowest height is %d", height, base),
+				http.StatusNotFound)
+			return
+		}
+
+		bz := bs.LoadBlockCommitBytes(height)
+		if bz == nil {
+			http.Error(w, fmt.Sprintf("commit at height %d not found", height), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", OctetStreamMediaType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(bz)))
+		w.Write(bz) //nolint: errcheck
+	})
+}
@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/rpc/core"
+)
+
+// rawCommitStore is a RawCommitStore holding the commits of heights 2 to 4.
+type rawCommitStore map[int64][]byte
+
+func (rawCommitStore) Base() int64   { return 2 }
+func (rawCommitStore) Height() int64 { return 5 }
+
+func (s rawCommitStore) LoadBlockCommitBytes(height int64) []byte { return s[height] }
+
+func TestRawCommits(t *testing.T) {
+	bs := rawCommitStore{2: []byte{2}, 4: []byte{4, 4}}
+	h := Handler(config.TestRPCConfig(), core.RoutesMap{}, log.NewNopLogger(), RawCommits(bs))
+	get := func(url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		return rec
+	}
+
+	// The route defaults to the latest canonical commit.
+	rec := get("/commit_raw")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, OctetStreamMediaType, rec.Header().Get("Content-Type"))
+	require.Equal(t, []byte{4, 4}, rec.Body.Bytes())
+
+	rec = get(`/commit_raw?height="2"`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, []byte{2}, rec.Body.Bytes())
+
+	for url, code := range map[string]int{
+		"/commit_raw?height=3":   http.StatusNotFound,
+		"/commit_raw?height=5":   http.StatusNotFound,
+		"/commit_raw?height=1":   http.StatusNotFound,
+		"/commit_raw?height=0":   http.StatusBadRequest,
+		"/commit_raw?height=abc": http.StatusBadRequest,
+	} {
+		require.Equal(t, code, get(url).Code, url)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/commit_raw", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
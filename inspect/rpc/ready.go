@@ -0,0 +1,52 @@
+package rpc
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/rpc/jsonrpc/server"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// notReadyRetryAfter is the delay after which clients are asked to retry
+// requests rejected because the server is not ready.
+const notReadyRetryAfter = time.Second
+
+// SetReady marks the server ready to serve requests, if RequireReady is set.
+// It is safe to call concurrently with the requests being served.
+func (srv *Server) SetReady() {
+	srv.ready.Store(true)
+}
+
+// isReady reports whether the server is ready to serve requests, running the
+// readiness probe if it is not ready yet.
+func (srv *Server) isReady() bool {
+	if srv.ready.Load() {
+		return true
+	}
+	if srv.ReadinessProbe == nil || srv.ReadinessProbe() != nil {
+		return false
+	}
+	srv.SetReady()
+	return true
+}
+
+// readinessHandler answers the requests to h with a 503 status until srv is
+// ready, except for the requests to the health route, which prove that the
+// server is alive.
+func readinessHandler(h http.Handler, srv *Server, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" || srv.isReady() {
+			h.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Retry-After", retryAfterSeconds(notReadyRetryAfter))
+		res := rpctypes.NewRPCErrorResponse(nil, codeServiceUnavailable, "Service temporarily unavailable",
+			fmt.Sprintf("server is starting; retry after %s seconds", retryAfterSeconds(notReadyRetryAfter)))
+		if err := server.WriteRPCResponseHTTPError(w, http.StatusServiceUnavailable, res); err != nil {
+			logger.Error("failed to write response", "err", err)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/libs/log"
+)
+
+func TestRequireReady(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	serve := func(srv *Server, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("SetReady", func(t *testing.T) {
+		srv := &Server{Handler: okHandler, Logger: log.NewNopLogger(), RequireReady: true}
+		rec := serve(srv, "/status")
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		require.Equal(t, "1", rec.Header().Get("Retry-After"))
+		require.Equal(t, http.StatusOK, serve(srv, "/health").Code)
+
+		srv.SetReady()
+		require.Equal(t, http.StatusOK, serve(srv, "/status").Code)
+	})
+
+	t.Run("ReadinessProbe", func(t *testing.T) {
+		probeErr := errors.New("stores not open")
+		srv := &Server{
+			Handler:        okHandler,
+			Logger:         log.NewNopLogger(),
+			RequireReady:   true,
+			ReadinessProbe: func() error { return probeErr },
+		}
+		require.Equal(t, http.StatusServiceUnavailable, serve(srv, "/status").Code)
+
+		probeErr = nil
+		require.Equal(t, http.StatusOK, serve(srv, "/status").Code)
+		probeErr = errors.New("not probed once ready")
+		require.Equal(t, http.StatusOK, serve(srv, "/status").Code)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		srv := &Server{Handler: okHandler, Logger: log.NewNopLogger()}
+		require.Equal(t, http.StatusOK, serve(srv, "/status").Code)
+	})
+}
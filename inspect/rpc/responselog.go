@@ -0,0 +1,139 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/cometbft/cometbft/libs/log"
+)
+
+// maxLoggedResponseBytes is the size beyond which the logged response bodies
+// are truncated.
+const maxLoggedResponseBytes = 4096
+
+// loggedRequestHeaders are the request headers logged along with the sampled
+// responses. Only headers known not to carry credentials are logged, so that
+// headers such as Authorization or SignatureHeader never are.
+var loggedRequestHeaders = []string{"Accept", "Content-Type", "User-Agent", "TE"}
+
+// LogSampledResponses logs the responses of one in oneIn requests at debug
+// level, with their status, their body truncated to 4 KiB, the scheme with
+// which the client reached the Inspector, see RequestScheme, and a few request
+// headers which do not carry credentials. If errorsOnly is set, only the
+// responses with an error status or a JSON-RPC error are sampled. Responses
+// are logged before being encoded by the response codecs; websocket
+// connections are not logged. Disabled by default.
+func LogSampledResponses(oneIn int, errorsOnly bool) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.responseLog = &responseSampler{oneIn: uint64(oneIn), errorsOnly: errorsOnly}
+	}
+}
+
+// responseSampler selects the responses to log.
+type responseSampler struct {
+	oneIn      uint64
+	errorsOnly bool
+	count      atomic.Uint64
+}
+
+// sample returns whether the next candidate response is logged.
+func (s *responseSampler) sample() bool {
+	return s.oneIn <= 1 || s.count.Add(1)%s.oneIn == 0
+}
+
+// responseLogHandler logs the responses of h selected by sampler.
+func responseLogHandler(h http.Handler, sampler *responseSampler, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" || (!sampler.errorsOnly && !sampler.sample()) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		lw := &loggingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(lw, r)
+
+		if sampler.errorsOnly && (!isErrorResponse(lw.status, lw.body.Bytes(), lw.truncated) || !sampler.sample()) {
+			return
+		}
+		keyvals := []interface{}{
+			"method", r.Method,
+			"scheme", RequestScheme(r.Context()),
+			"path", r.URL.Path,
+			"status", lw.status,
+		}
+		for _, header := range loggedRequestHeaders {
+			if value := r.Header.Get(header); value != "" {
+				keyvals = append(keyvals, header, value)
+			}
+		}
+		keyvals = append(keyvals, "body", lw.body.String(), "truncated", lw.truncated)
+		logger.Debug("Sampled response", keyvals...)
+	})
+}
+
+// isErrorResponse returns whether a response has an error status or is a
+// JSON-RPC response, or batch of responses, with an error. Truncated bodies
+// are only checked for the error member of a single response.
+func isErrorResponse(status int, body []byte, truncated bool) bool {
+	if status >= http.StatusBadRequest {
+		return true
+	}
+	if truncated {
+		return bytes.Contains(body, []byte(`"error":`))
+	}
+	var res struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &res) == nil {
+		return len(res.Error) > 0 && string(res.Error) != "null"
+	}
+	var batch []struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &batch) == nil {
+		for _, res := range batch {
+			if len(res.Error) > 0 && string(res.Error) != "null" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loggingResponseWriter keeps the status and the beginning of the body of a
+// response written to the underlying writer.
+type loggingResponseWriter struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *loggingResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *loggingResponseWriter) Write(b []byte) (int, error) {
+	if room := maxLoggedResponseBytes - w.body.Len(); len(b) > room {
+		w.body.Write(b[:room])
+		w.truncated = true
+	} else {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (w *loggingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by
+// http.ResponseController.
+func (w *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,96 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/libs/log"
+)
+
+// debugRecorder is a logger recording the key-value pairs of its debug
+// messages.
+type debugRecorder struct {
+	log.Logger
+	entries []map[string]interface{}
+}
+
+func (l *debugRecorder) Debug(_ string, keyvals ...interface{}) {
+	entry := make(map[string]interface{})
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		entry[keyvals[i].(string)] = keyvals[i+1]
+	}
+	l.entries = append(l.entries, entry)
+}
+
+func TestResponseLogHandler(t *testing.T) {
+	body := `{"jsonrpc":"2.0","id":1,"result":{}}`
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/error":
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32603}}`)) //nolint: errcheck
+		case "/large":
+			w.Write([]byte(strings.Repeat("a", maxLoggedResponseBytes+1))) //nolint: errcheck
+		default:
+			w.Write([]byte(body)) //nolint: errcheck
+		}
+	})
+	serve := func(h http.Handler, path string) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("User-Agent", "test")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	logger := &debugRecorder{Logger: log.NewNopLogger()}
+	sampled := responseLogHandler(h, &responseSampler{oneIn: 3}, logger)
+	for i := 0; i < 6; i++ {
+		serve(sampled, "/status")
+	}
+	require.Len(t, logger.entries, 2)
+	entry := logger.entries[0]
+	require.Equal(t, body, entry["body"])
+	require.Equal(t, http.StatusOK, entry["status"])
+	require.Equal(t, "test", entry["User-Agent"])
+	require.Equal(t, "http", entry["scheme"])
+	require.NotContains(t, entry, "Authorization")
+
+	logger.entries = nil
+	serve(responseLogHandler(h, &responseSampler{oneIn: 1}, logger), "/large")
+	require.Len(t, logger.entries, 1)
+	require.Len(t, logger.entries[0]["body"], maxLoggedResponseBytes)
+	require.Equal(t, true, logger.entries[0]["truncated"])
+
+	// Only the responses with an error are sampled with errorsOnly.
+	logger.entries = nil
+	errorsOnly := responseLogHandler(h, &responseSampler{oneIn: 1, errorsOnly: true}, logger)
+	serve(errorsOnly, "/status")
+	serve(errorsOnly, "/error")
+	require.Len(t, logger.entries, 1)
+	require.Equal(t, "/error", logger.entries[0]["path"])
+
+	// The scheme is the one forwarded by trusted proxies.
+	logger.entries = nil
+	trusted := []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")}
+	req := httptest.NewRequest(http.MethodGet, "/status", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	clientIPHandler(responseLogHandler(h, &responseSampler{oneIn: 1}, logger), trusted).
+		ServeHTTP(httptest.NewRecorder(), req)
+	require.Len(t, logger.entries, 1)
+	require.Equal(t, "https", logger.entries[0]["scheme"])
+}
+
+func TestIsErrorResponse(t *testing.T) {
+	require.True(t, isErrorResponse(http.StatusBadRequest, nil, false))
+	require.False(t, isErrorResponse(http.StatusOK, []byte(`{"result":{},"error":null}`), false))
+	require.True(t, isErrorResponse(http.StatusOK, []byte(`{"error":{"code":-32603}}`), false))
+	require.True(t, isErrorResponse(http.StatusOK, []byte(`[{"result":{}},{"error":{"code":-32603}}]`), false))
+	require.False(t, isErrorResponse(http.StatusOK, []byte(`[{"result":{}}]`), false))
+	require.True(t, isErrorResponse(http.StatusOK, []byte(`{"error":{"code":`), true))
+}
@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// RetryStoreReads retries the routes failing with errors for which
+// isTransient returns true, whether returned or raised by the stores, up to
+// retries times. The first retry happens after backoff, and the delay doubles
+// before every further retry, so a request is delayed by at most
+// backoff * (2^retries - 1). Other errors are returned immediately.
+//
+// StoreUnavailableFuncForBackend returns a classifier suitable for the
+// database backend of the stores. Errors still failing after the last retry
+// are reported as set by StoreUnavailable.
+func RetryStoreReads(isTransient StoreUnavailableFunc, retries int, backoff time.Duration) RoutesOption {
+	return func(env *environment) {
+		env.isTransient = isTransient
+		env.readRetries = retries
+		env.readBackoff = backoff
+	}
+}
+
+// retryMiddleware calls the routes again while they fail with transient
+// errors, up to retries times.
+func retryMiddleware(isTransient StoreUnavailableFunc, retries int, backoff time.Duration) routeMiddleware {
+	return func(_ string, next routeHandler) routeHandler {
+		return func(ctx *rpctypes.Context, args []reflect.Value) (interface{}, error) {
+			delay := backoff
+			for attempt := 0; ; attempt++ {
+				result, err := callRecoveringTransient(next, isTransient, ctx, args)
+				if err == nil || attempt == retries || !isTransient(err) {
+					return result, err
+				}
+				if !sleepContext(requestContext(ctx), delay) {
+					return nil, err
+				}
+				delay *= 2
+			}
+		}
+	}
+}
+
+// callRecoveringTransient calls next, returning the transient errors it panics
+// with as errors.
+func callRecoveringTransient(
+	next routeHandler,
+	isTransient StoreUnavailableFunc,
+	ctx *rpctypes.Context,
+	args []reflect.Value,
+) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			rErr, ok := r.(error)
+			if !ok || !isTransient(rErr) {
+				panic(r)
+			}
+			result, err = nil, rErr
+		}
+	}()
+	return next(ctx, args)
+}
+
+func requestContext(ctx *rpctypes.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx.Context()
+}
+
+// sleepContext waits for d, returning false if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
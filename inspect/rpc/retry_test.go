@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestRetryStoreReads(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Height").Return(int64(2))
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("LoadBlockMeta", int64(1)).Run(func(mock.Arguments) {
+		panic(fmt.Errorf("reading block meta: %w", syscall.EAGAIN))
+	}).Twice()
+	blockStoreMock.On("LoadBlockMeta", int64(1)).Return(&types.BlockMeta{Header: types.Header{Height: 1}})
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("LoadFinalizeBlockResponse", int64(1)).
+		Return((*abcitypes.ResponseFinalizeBlock)(nil), fmt.Errorf("reading results: %w", syscall.EBUSY))
+	stateStoreMock.On("LoadFinalizeBlockResponse", int64(2)).
+		Return((*abcitypes.ResponseFinalizeBlock)(nil), fmt.Errorf("corrupted results"))
+	h := newTestHandler(blockStoreMock, stateStoreMock,
+		StoreUnavailable(StoreUnavailableFuncForBackend("goleveldb"), time.Second),
+		RetryStoreReads(StoreUnavailableFuncForBackend("goleveldb"), 2, time.Millisecond))
+
+	// Succeeds on the last retry.
+	res := callJSONRPC(t, h, "header", `{"height":"1"}`)
+	require.Nil(t, res.Error)
+	blockStoreMock.AssertNumberOfCalls(t, "LoadBlockMeta", 3)
+
+	// Still failing after the last retry.
+	res = callJSONRPC(t, h, "block_results", `{"height":"1"}`)
+	require.NotNil(t, res.Error)
+	require.Equal(t, codeServiceUnavailable, res.Error.Code)
+	stateStoreMock.AssertNumberOfCalls(t, "LoadFinalizeBlockResponse", 3)
+
+	// Not retried.
+	res = callJSONRPC(t, h, "block_results", `{"height":"2"}`)
+	require.NotNil(t, res.Error)
+	require.NotEqual(t, codeServiceUnavailable, res.Error.Code)
+	stateStoreMock.AssertNumberOfCalls(t, "LoadFinalizeBlockResponse", 4)
+}
@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"fmt"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// defaultElevatedRound is the round from which the commit_rounds route flags
+// the heights, when no round is given: any height which needed a round change
+// is flagged.
+const defaultElevatedRound = 1
+
+// CommitRound is the round at which the block at a height was committed. The
+// block took Round+1 rounds to commit: the proposers of the Round rounds
+// before failed to have a block committed.
+type CommitRound struct {
+	Height    int64 `json:"height"`
+	Round     int32 `json:"round"`
+	Canonical bool  `json:"canonical"`
+	// Elevated is true if Round is at least the elevated round of the
+	// request.
+	Elevated bool `json:"elevated"`
+}
+
+// ResultCommitRounds is the result of the commit_rounds route.
+type ResultCommitRounds struct {
+	LastHeight    int64 `json:"last_height"`
+	ElevatedRound int32 `json:"elevated_round"`
+	// Elevated is the number of heights of the range which were committed at
+	// the elevated round or later, and MaxRound the latest round at which a
+	// height of the range was committed.
+	Elevated     int           `json:"elevated"`
+	MaxRound     int32         `json:"max_round"`
+	CommitRounds []CommitRound `json:"commit_rounds"`
+}
+
+// CommitRounds returns the rounds at which the blocks for
+// minHeight <= height <= maxHeight were committed, in ascending order, read
+// from their commits, and flags those committed at elevatedRound or later. An
+// elevatedRound of 0 defaults to 1, flagging every round change. The range is
+// resolved as in the blockchain route. As in the commit route, the commit of
+// the latest block is the non-canonical seen commit. The heights whose commit
+// is missing are skipped.
+func (env *environment) CommitRounds(
+	_ *rpctypes.Context,
+	minHeight, maxHeight int64,
+	elevatedRound int32,
+) (*ResultCommitRounds, error) {
+	if elevatedRound < 0 {
+		return nil, fmt.Errorf("elevated round must be non-negative, but got %d", elevatedRound)
+	}
+	if elevatedRound == 0 {
+		elevatedRound = defaultElevatedRound
+	}
+	minHeight, maxHeight, err := env.heightRange(minHeight, maxHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ResultCommitRounds{
+		LastHeight:    env.BlockStore.Height(),
+		ElevatedRound: elevatedRound,
+	}
+	res.CommitRounds = loadHeights(env, minHeight, maxHeight, func(height int64) (CommitRound, bool) {
+		commit, canonical := env.loadCommit(height)
+		if commit == nil {
+			return CommitRound{}, false
+		}
+		return CommitRound{
+			Height:    height,
+			Round:     commit.Round,
+			Canonical: canonical,
+			Elevated:  commit.Round >= elevatedRound,
+		}, true
+	})
+	for _, round := range res.CommitRounds {
+		if round.Elevated {
+			res.Elevated++
+		}
+		res.MaxRound = max(res.MaxRound, round.Round)
+	}
+	return res, nil
+}
@@ -0,0 +1,42 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestCommitRounds(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(4))
+	blockStoreMock.On("LoadBlockCommit", int64(1)).Return(nil)
+	blockStoreMock.On("LoadBlockCommit", int64(2)).Return(&types.Commit{Height: 2, Round: 0})
+	blockStoreMock.On("LoadBlockCommit", int64(3)).Return(&types.Commit{Height: 3, Round: 3})
+	blockStoreMock.On("LoadSeenCommit", int64(4)).Return(&types.Commit{Height: 4, Round: 1})
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{})
+
+	res, err := env.CommitRounds(nil, 0, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, &ResultCommitRounds{
+		LastHeight:    4,
+		ElevatedRound: 1,
+		Elevated:      2,
+		MaxRound:      3,
+		CommitRounds: []CommitRound{
+			{Height: 2, Round: 0, Canonical: true},
+			{Height: 3, Round: 3, Canonical: true, Elevated: true},
+			{Height: 4, Round: 1, Elevated: true},
+		},
+	}, res)
+
+	res, err = env.CommitRounds(nil, 0, 0, 2)
+	require.NoError(t, err)
+	require.Equal(t, 1, res.Elevated)
+
+	_, err = env.CommitRounds(nil, 0, 0, -1)
+	require.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/rpc/core"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// defaultRouteCacheCapacity is the number of responses cached by the route
+// caches whose capacity is not set.
+const defaultRouteCacheCapacity = 1000
+
+// ValidateRouteCaches returns an error if any of the routes of caches is not
+// served by the Inspector.
+func ValidateRouteCaches(caches map[string]config.RouteCacheConfig) error {
+	routes := (&environment{Environment: &core.Environment{}}).routes()
+	var unknown []string
+	for route := range caches {
+		if _, ok := routes[route]; !ok {
+			unknown = append(unknown, route)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown routes in route_caches: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// routeCachesEnabled returns whether the cache of any route is enabled.
+func routeCachesEnabled(caches map[string]config.RouteCacheConfig) bool {
+	for _, cache := range caches {
+		if cache.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// routeCacheMiddleware caches the results of the routes whose cache is
+// enabled in caches, by the arguments of the calls. Errors are not cached.
+// The caches without a TTL use defaultTTL. Cached results are shared by the
+// requests they serve, which only encode them, with the effects of the call
+// which read them on the state of its request, such as the event sink which
+// served it.
+func routeCacheMiddleware(caches map[string]config.RouteCacheConfig, defaultTTL time.Duration) routeMiddleware {
+	return func(route string, next routeHandler) routeHandler {
+		cfg := caches[route]
+		if !cfg.Enabled {
+			return next
+		}
+		capacity := cfg.Capacity
+		if capacity == 0 {
+			capacity = defaultRouteCacheCapacity
+		}
+		ttl := cfg.TTL
+		if ttl == 0 {
+			ttl = defaultTTL
+		}
+		cache := newLRUCache[string, sharedResult](capacity, ttl)
+
+		return func(ctx *rpctypes.Context, args []reflect.Value) (interface{}, error) {
+			key, err := routeCacheKey(args)
+			if err != nil {
+				return next(ctx, args)
+			}
+			if cached, ok := cache.get(key); ok {
+				cached.effects.apply(ctx)
+				return cached.value, nil
+			}
+			result, effects, err := callRecordingEffects(ctx, args, next)
+			effects.apply(ctx)
+			if err != nil {
+				return nil, err
+			}
+			cache.add(key, sharedResult{value: result, effects: effects})
+			return result, nil
+		}
+	}
+}
+
+// sharedResult is a result shared by several requests, with the effects of the
+// call which returned it on the state of its request.
+type sharedResult struct {
+	value   interface{}
+	effects stateEffects
+}
+
+// routeCacheKey returns the key identifying the arguments of a call in the
+// cache of its route.
+func routeCacheKey(args []reflect.Value) (string, error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Interface()
+	}
+	key, err := json.Marshal(values)
+	return string(key), err
+}
@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	indexermocks "github.com/cometbft/cometbft/state/indexer/mocks"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestRouteCache(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(2))
+	for height := int64(1); height <= 2; height++ {
+		blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{Header: types.Header{Height: height}})
+	}
+	cfg := config.TestRPCConfig()
+	cfg.RouteCaches = map[string]config.RouteCacheConfig{
+		"header": {Enabled: true, Capacity: 1},
+		"block":  {Enabled: false},
+	}
+	logger := log.NewNopLogger()
+	routes := Routes(*cfg, &statemocks.Store{}, blockStoreMock,
+		&txindexmocks.TxIndexer{}, &indexermocks.BlockIndexer{}, logger)
+	h := Handler(cfg, routes, logger)
+
+	header := func(params string) {
+		res := callJSONRPC(t, h, "header", params)
+		require.Nil(t, res.Error)
+	}
+	header(`{"height":"1"}`)
+	header(`{"height":"1"}`)
+	blockStoreMock.AssertNumberOfCalls(t, "LoadBlockMeta", 1)
+
+	// The least recently used response is evicted.
+	header(`{"height":"2"}`)
+	header(`{"height":"1"}`)
+	blockStoreMock.AssertNumberOfCalls(t, "LoadBlockMeta", 3)
+
+	// Errors are not cached.
+	for i := 0; i < 2; i++ {
+		res := callJSONRPC(t, h, "header", `{"height":"3"}`)
+		require.NotNil(t, res.Error)
+	}
+
+	env := newEnvironment(*cfg, &statemocks.Store{}, blockStoreMock,
+		&txindexmocks.TxIndexer{}, &indexermocks.BlockIndexer{}, logger)
+	require.Contains(t, env.features(), "route_cache")
+}
+
+func TestValidateRouteCaches(t *testing.T) {
+	require.NoError(t, ValidateRouteCaches(nil))
+	require.NoError(t, ValidateRouteCaches(map[string]config.RouteCacheConfig{"block": {Enabled: true}}))
+	err := ValidateRouteCaches(map[string]config.RouteCacheConfig{
+		"block":    {Enabled: true},
+		"blocks":   {Enabled: true},
+		"net_info": {},
+	})
+	require.EqualError(t, err, "unknown routes in route_caches: blocks, net_info")
+}
+
+func TestRouteCacheEffects(t *testing.T) {
+	calls := 0
+	next := func(ctx *rpctypes.Context, _ []reflect.Value) (interface{}, error) {
+		calls++
+		stateFromContext(ctx).eventSink = "psql"
+		return "result", nil
+	}
+	caches := map[string]config.RouteCacheConfig{"tx_search": {Enabled: true}}
+	h := routeCacheMiddleware(caches, time.Minute)("tx_search", next)
+	args := []reflect.Value{reflect.ValueOf("tx.height=1")}
+
+	// The effects of the call which read a result are replayed on the
+	// requests served from the cache.
+	for i := 0; i < 2; i++ {
+		state := &requestState{}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(context.WithValue(req.Context(), requestStateKey{}, state))
+		result, err := h(&rpctypes.Context{HTTPReq: req}, args)
+		require.NoError(t, err)
+		require.Equal(t, "result", result)
+		require.Equal(t, "psql", state.eventSink)
+	}
+	require.Equal(t, 1, calls)
+}
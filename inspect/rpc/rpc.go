@@ -2,10 +2,18 @@ package rpc
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"net/http"
+	"net/netip"
+	"runtime"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/cors"
+	"golang.org/x/sync/semaphore"
 
 	"github.com/cometbft/cometbft/config"
 	"github.com/cometbft/cometbft/libs/log"
@@ -22,64 +30,579 @@ type Server struct {
 	Handler http.Handler
 	Logger  log.Logger
 	Config  *config.RPCConfig
+
+	// EnableUI serves a minimal explorer UI under /ui/ alongside the RPC
+	// routes. It is disabled by default.
+	EnableUI bool
+
+	// EnableH2C serves HTTP/2 over cleartext connections, in addition to
+	// HTTP/1.1, on servers started with ListenAndServe. Websocket connections
+	// require HTTP/1.1 and are refused on HTTP/2 connections.
+	EnableH2C bool
+
+	// Label identifies the server when running several of them. If set, it is
+	// sent to clients in the LabelHeader header of every response.
+	Label string
+
+	// ListenBacklog sets the length of the queue of pending connections of
+	// the listener, if positive. Otherwise, the default of the system is used,
+	// which is net.core.somaxconn on Linux. It is only supported on Linux and
+	// macOS, where the length is capped by the system.
+	ListenBacklog int
+
+	// ReusePort sets SO_REUSEPORT on TCP listeners, so that several processes
+	// may listen on the same address and have the incoming connections
+	// balanced between them. It is only supported on Linux and macOS; macOS
+	// does not balance the connections.
+	ReusePort bool
+
+	// MaxTLSHandshakes is the maximum number of TLS handshakes run at once
+	// by ListenAndServeTLS, which protects the CPUs from floods of new
+	// connections. Connections beyond the limit wait for a handshake to
+	// complete, and are closed if they are not established within the read
+	// timeout of the server. It defaults to the number of CPUs if zero, and
+	// the limit is disabled if negative.
+	MaxTLSHandshakes int
+
+	// ClientCAs enables mutual TLS on ListenAndServeTLS, if set: the clients
+	// must present a certificate chaining to one of the CAs, which passes the
+	// checks of ClientCertPolicy.
+	ClientCAs        *x509.CertPool
+	ClientCertPolicy ClientCertPolicy
+
+	// Metrics records the requests served by the server, if set. They are
+	// usually the metrics of the routes served by Handler, see WithMetrics
+	// and SinkMetrics.
+	Metrics *Metrics
+
+	// RequireReady makes the server answer requests with a 503 status until
+	// it is marked ready, by SetReady or by a successful ReadinessProbe, for
+	// embedders which start serving before their stores are open. The
+	// requests to the health route are served regardless.
+	RequireReady bool
+
+	// ReadinessProbe, if set, is run by the requests received while the
+	// server is not ready, which is marked ready once the probe returns nil.
+	// It must be safe for concurrent use.
+	ReadinessProbe func() error
+
+	ready atomic.Bool
+}
+
+// LabelHeader is the response header carrying the label of the server.
+const LabelHeader = "X-Inspect-Chain"
+
+// RoutesOption sets an optional parameter on the Inspector routes.
+type RoutesOption func(*environment)
+
+// HandlerOption sets an optional parameter on the Inspector handler.
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	authenticator Authenticator
+	metricsAdmin  *metricsAdmin
+	chunkSize     int
+	canonicalJSON bool
+	prettyIndent  string
+
+	trustedProxies []netip.Prefix
+
+	wsIdleTimeout   time.Duration
+	wsReadLimitText *string
+	wsSendBuffer    int
+
+	metrics *Metrics
+
+	txSearchStream         *TxSearchStream
+	streamSearchTimeout    time.Duration
+	streamSearchMaxResults int
+	blockMetasStream       state.BlockStore
+	rawCommits             RawCommitStore
+
+	timestampHeader  string
+	maxTimestampSkew time.Duration
+
+	fairQueue *fairQueue
+
+	codecs []ResponseCodec
+
+	maxJSONDepth int
+
+	chainID *string
+
+	requireJSONContentType bool
+
+	statsTrailers bool
+
+	shortCircuitHead bool
+
+	responseLog *responseSampler
+}
+
+// WebsocketIdleTimeout closes the websocket connections which have not sent a
+// request or received a response in the given duration. Connections which
+// stop answering pings are closed regardless, after the read timeout of the
+// websocket server. Disabled by default.
+func WebsocketIdleTimeout(d time.Duration) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.wsIdleTimeout = d
+	}
+}
+
+// WebsocketReadLimitReason sets the reason of the close message, with status
+// 1009 (message too big), sent to the websocket clients whose messages exceed
+// the MaxBodyBytes limit of the RPC config, subscription queries included. By
+// default the reason states the limit. An empty reason closes the connections
+// without one.
+func WebsocketReadLimitReason(reason string) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.wsReadLimitText = &reason
+	}
+}
+
+// WebsocketSendBuffer bounds the number of responses queued for each
+// websocket connection to size. The connections of clients which do not read
+// their responses fast enough to keep the queue below size are closed, with a
+// close message with status 1011, rather than holding up the server. The
+// closed connections are counted in the WebsocketSlowClientsClosed metric.
+// Disabled by default, in which case the handling of further requests waits
+// for the queue to drain.
+func WebsocketSendBuffer(size int) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.wsSendBuffer = size
+	}
+}
+
+// HandlerMetrics records the metrics of the handler, such as those of the
+// websocket connections, in metrics. They are usually the metrics of the
+// routes, see WithMetrics.
+func HandlerMetrics(metrics *Metrics) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.metrics = metrics
+	}
+}
+
+// environment extends the node's RPC environment with the state needed by the
+// Inspector-specific routes.
+type environment struct {
+	*core.Environment
+
+	maxBlockAge    time.Duration
+	maxRangeSpan   int64
+	maxHeightsScan int64
+	maxTxsLookup   int
+
+	maxBlockMetasSpan    int64
+	rangeReadConcurrency int
+
+	genesisInfo *ResultGenesisInfo
+
+	maxCommitSignatures *int
+	maxBlockEvents      *int
+
+	maxInFlightBytes int64
+
+	maxConcurrentSearches int
+	maxQueryComplexity    int
+	maxSearchPage         int
+
+	eventSinks []EventSink
+
+	// missingCapabilities are the capabilities which the stores lack.
+	missingCapabilities map[storeCapability]bool
+	// paramsStore is the state store if it reports the heights at which the
+	// consensus params were set, or nil.
+	paramsStore consensusParamsStore
+
+	validatorSetCacheSize int
+	cacheTTL              time.Duration
+	coalesceRequests      bool
+
+	indexerTimeout     time.Duration
+	breakerMaxFailures int
+	breakerCooldown    time.Duration
+
+	metrics        *Metrics
+	metricsEnabled bool
+
+	isStoreUnavailable StoreUnavailableFunc
+	retryAfter         time.Duration
+
+	isTransient StoreUnavailableFunc
+	readRetries int
+	readBackoff time.Duration
+
+	cursorKey []byte
+	cursorTTL time.Duration
+
+	// routeMiddlewares wrap the function of every route, in order.
+	routeMiddlewares []routeMiddleware
+	// searchMiddlewares are those of the route middlewares guarding the
+	// searches, which also guard the streaming search route.
+	searchMiddlewares []routeMiddleware
+}
+
+// Routes returns the set of routes used by the Inspector server. The routes
+// reading data which the stores are known not to hold, such as tx_search when
+// transactions are not indexed, are left out and logged.
+func Routes(cfg config.RPCConfig, s state.Store, bs state.BlockStore, txidx txindex.TxIndexer, blkidx indexer.BlockIndexer, logger log.Logger, options ...RoutesOption) core.RoutesMap { //nolint: lll
+	env := newEnvironment(cfg, s, bs, txidx, blkidx, logger, options...)
+	routes := env.routes()
+	for _, method := range cfg.DisabledRPCMethods {
+		delete(routes, method)
+	}
+	removeUnsupportedRoutes(routes, env.missingCapabilities, logger)
+	routesMap := make(core.RoutesMap, len(routes))
+	for name, r := range routes {
+		routesMap[name] = server.NewRPCFunc(env.wrapRoute(name, r.f), r.args)
+	}
+	return routesMap
+}
+
+// servesRoute returns whether the route name is among those served by Routes,
+// rather than disabled or unsupported by the stores.
+func (env *environment) servesRoute(name string) bool {
+	for _, method := range env.Config.DisabledRPCMethods {
+		if method == name {
+			return false
+		}
+	}
+	capability, ok := routeCapabilities[name]
+	return !ok || !env.missingCapabilities[capability]
 }
 
-// Routes returns the set of routes used by the Inspector server.
-func Routes(cfg config.RPCConfig, s state.Store, bs state.BlockStore, txidx txindex.TxIndexer, blkidx indexer.BlockIndexer, logger log.Logger) core.RoutesMap { //nolint: lll
-	env := &core.Environment{
-		Config:           cfg,
-		BlockIndexer:     blkidx,
-		TxIndexer:        txidx,
-		StateStore:       s,
-		BlockStore:       bs,
-		ConsensusReactor: waitSyncCheckerImpl{},
-		Logger:           logger,
-	}
-	return core.RoutesMap{
-		"blockchain":       server.NewRPCFunc(env.BlockchainInfo, "minHeight,maxHeight"),
-		"consensus_params": server.NewRPCFunc(env.ConsensusParams, "height"),
-		"block":            server.NewRPCFunc(env.Block, "height"),
-		"block_by_hash":    server.NewRPCFunc(env.BlockByHash, "hash"),
-		"block_results":    server.NewRPCFunc(env.BlockResults, "height"),
-		"commit":           server.NewRPCFunc(env.Commit, "height"),
-		"header":           server.NewRPCFunc(env.Header, "height"),
-		"header_by_hash":   server.NewRPCFunc(env.HeaderByHash, "hash"),
-		"validators":       server.NewRPCFunc(env.Validators, "height,page,per_page"),
-		"tx":               server.NewRPCFunc(env.Tx, "hash,prove"),
-		"tx_search":        server.NewRPCFunc(env.TxSearch, "query,prove,page,per_page,order_by"),
-		"block_search":     server.NewRPCFunc(env.BlockSearch, "query,page,per_page,order_by"),
+// ValidateDisabledMethods returns an error if any of the methods is not served
+// by the Inspector.
+func ValidateDisabledMethods(methods []string) error {
+	routes := (&environment{Environment: &core.Environment{}}).routes()
+	var unknown []string
+	for _, method := range methods {
+		if _, ok := routes[method]; !ok {
+			unknown = append(unknown, method)
+		}
 	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown disabled RPC methods: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// routes returns the routes of the Inspector served by env.
+func (env *environment) routes() map[string]route {
+	return map[string]route{
+		"health":                     {env.Health, "deep"},
+		"status":                     {env.Status, ""},
+		"version":                    {env.Version, ""},
+		"blockchain":                 {env.BlockchainInfo, "minHeight,maxHeight"},
+		"block_metas":                {env.BlockMetas, "minHeight,maxHeight,fields,cursor"},
+		"consensus_params":           {env.ConsensusParams, "height"},
+		"genesis":                    {env.Genesis, ""},
+		"genesis_chunked":            {env.GenesisChunked, "chunk"},
+		"genesis_info":               {env.GenesisInfo, ""},
+		"consensus_params_diff":      {env.ConsensusParamsDiff, "fromHeight,toHeight"},
+		"block":                      {env.Block, "height"},
+		"block_by_hash":              {env.BlockByHash, "hash"},
+		"block_id":                   {env.BlockID, "height"},
+		"block_id_range":             {env.BlockIDRange, "minHeight,maxHeight"},
+		"block_txs":                  {env.BlockTxs, "height,page,per_page"},
+		"block_results":              {env.blockResults, "height"},
+		"block_with_commit":          {env.BlockWithCommit, "height"},
+		"commit":                     {env.commit, "height"},
+		"commit_signers":             {env.CommitSigners, "height"},
+		"last_commit":                {env.LastCommit, ""},
+		"last_commit_in_block":       {env.LastCommitInBlock, "height"},
+		"events":                     {env.Events, "height,type,page,per_page"},
+		"decoded_events":             {env.DecodedEvents, "height"},
+		"evidence":                   {env.Evidence, "height"},
+		"header":                     {env.Header, "height"},
+		"header_by_hash":             {env.HeaderByHash, "hash"},
+		"header_commit_proof":        {env.HeaderCommitProof, "height"},
+		"header_verification_bundle": {env.HeaderVerificationBundle, "height"},
+		"latest_headers":             {env.LatestHeaders, "count"},
+		"state":                      {env.State, "height"},
+		"validators":                 {env.Validators, "height,page,per_page"},
+		"validator_updates_range":    {env.ValidatorUpdatesRange, "minHeight,maxHeight"},
+		"validator_hashes_range":     {env.ValidatorHashesRange, "minHeight,maxHeight"},
+		"validator_set_diff":         {env.ValidatorSetDiff, "fromHeight,toHeight"},
+		"validators_with_priority":   {env.ValidatorsWithPriority, "height"},
+		"voting_power":               {env.VotingPower, "height"},
+		"voting_power_range":         {env.VotingPowerRange, "minHeight,maxHeight"},
+		"tx_counts_range":            {env.TxCountsRange, "minHeight,maxHeight"},
+		"block_sizes_range":          {env.BlockSizesRange, "minHeight,maxHeight"},
+		"versions_range":             {env.VersionsRange, "minHeight,maxHeight"},
+		"header_hashes":              {env.HeaderHashes, "minHeight,maxHeight"},
+		"missing_heights":            {env.MissingHeights, "minHeight,maxHeight"},
+		"participation":              {env.Participation, "minHeight,maxHeight"},
+		"commit_rounds":              {env.CommitRounds, "minHeight,maxHeight,elevatedRound"},
+		"tx":                         {env.Tx, "hash,prove"},
+		"tx_proof_full":              {env.TxProofFull, "hash"},
+		"txs":                        {env.Txs, "hashes,prove"},
+		"tx_locate":                  {env.TxLocate, "hash"},
+		"tx_search":                  {env.TxSearch, "query,prove,page,per_page,order_by"},
+		"block_search":               {env.BlockSearch, "query,page,per_page,order_by"},
+		"query_complexity":           {env.QueryComplexity, "query"},
+		"apphash_range":              {env.AppHashRange, "minHeight,maxHeight"},
+		"verify_proof":               {env.VerifyProof, "height,proof,leaf"},
+		"block_interval_stats":       {env.BlockIntervalStats, "minHeight,maxHeight"},
+		"block_time_context":         {env.BlockTimeContext, "height"},
+	}
+}
+
+// route is the function serving a route along with the names of its
+// arguments, as expected by server.NewRPCFunc.
+type route struct {
+	f    interface{}
+	args string
+}
+
+func newEnvironment(cfg config.RPCConfig, s state.Store, bs state.BlockStore, txidx txindex.TxIndexer, blkidx indexer.BlockIndexer, logger log.Logger, options ...RoutesOption) *environment { //nolint: lll
+	env := &environment{
+		Environment: &core.Environment{
+			Config:           cfg,
+			BlockIndexer:     blkidx,
+			TxIndexer:        txidx,
+			StateStore:       s,
+			BlockStore:       bs,
+			ConsensusReactor: waitSyncCheckerImpl{},
+			Logger:           logger,
+		},
+		maxRangeSpan:      defaultMaxRangeSpan,
+		maxHeightsScan:    defaultMaxHeightsScan,
+		maxTxsLookup:      defaultMaxTxsLookup,
+		maxBlockMetasSpan: defaultMaxBlockMetasSpan,
+		metrics:           NopMetrics(),
+
+		maxConcurrentSearches: runtime.NumCPU(),
+	}
+	for _, option := range options {
+		option(env)
+	}
+	if env.GenDoc != nil {
+		if err := env.initGenesis(); err != nil {
+			logger.Error("Not serving the genesis document", "err", err)
+			env.GenDoc = nil
+		}
+	}
+	if err := env.initCursorKey(); err != nil {
+		// Without a key, cursors are signed with an empty one, which clients
+		// could forge.
+		panic(fmt.Sprintf("generating the cursor key: %v", err))
+	}
+	env.missingCapabilities = env.probeStores()
+	env.paramsStore, _ = env.StateStore.(consensusParamsStore)
+	if env.metricsEnabled {
+		env.timeStoreReads()
+	}
+	if env.validatorSetCacheSize > 0 {
+		env.StateStore = cachedValidatorsStore{
+			Store:      env.StateStore,
+			blockStore: env.BlockStore,
+			cache:      newValidatorSetCache(env.validatorSetCacheSize, env.cacheTTL),
+		}
+	}
+	env.guardIndexers()
+	env.useEventSinks()
+	// The stats middleware is the outermost one, so that the results served
+	// from the caches are accounted for.
+	env.routeMiddlewares = append(env.routeMiddlewares, statsMiddleware)
+	if env.breakerMaxFailures > 0 {
+		env.routeMiddlewares = append(env.routeMiddlewares, unavailableMiddleware)
+		env.searchMiddlewares = append(env.searchMiddlewares, unavailableMiddleware)
+	}
+	if env.isStoreUnavailable != nil {
+		env.routeMiddlewares = append(env.routeMiddlewares,
+			storeUnavailableMiddleware(env.isStoreUnavailable, env.retryAfter))
+	}
+	if env.isTransient != nil && env.readRetries > 0 {
+		env.routeMiddlewares = append(env.routeMiddlewares,
+			retryMiddleware(env.isTransient, env.readRetries, env.readBackoff))
+	}
+	// Each response is reserved its size, whether its result is read or
+	// shared by the route cache or the coalescing of requests. The route
+	// middlewares below these run once for the requests sharing a result.
+	if env.maxInFlightBytes > 0 {
+		env.routeMiddlewares = append(env.routeMiddlewares,
+			responseBudgetMiddleware(env, semaphore.NewWeighted(env.maxInFlightBytes), env.maxInFlightBytes))
+	}
+	if routeCachesEnabled(cfg.RouteCaches) {
+		env.routeMiddlewares = append(env.routeMiddlewares, routeCacheMiddleware(cfg.RouteCaches, env.cacheTTL))
+	}
+	if env.coalesceRequests {
+		env.routeMiddlewares = append(env.routeMiddlewares, coalesceMiddleware())
+	}
+	env.routeMiddlewares = append(env.routeMiddlewares, heightMiddleware(env))
+	if len(cfg.AllowedQueryOperators) > 0 {
+		m := queryOperatorMiddleware(cfg.AllowedQueryOperators)
+		env.routeMiddlewares = append(env.routeMiddlewares, m)
+		env.searchMiddlewares = append(env.searchMiddlewares, m)
+	}
+	if env.maxQueryComplexity > 0 {
+		m := queryComplexityMiddleware(env.maxQueryComplexity)
+		env.routeMiddlewares = append(env.routeMiddlewares, m)
+		env.searchMiddlewares = append(env.searchMiddlewares, m)
+	}
+	if env.maxSearchPage > 0 {
+		env.routeMiddlewares = append(env.routeMiddlewares, searchPageMiddleware(env.maxSearchPage))
+	}
+	if env.maxConcurrentSearches > 0 {
+		// The searches of the streaming search route count towards the
+		// same limit.
+		m := searchLimitMiddleware(env.maxConcurrentSearches)
+		env.routeMiddlewares = append(env.routeMiddlewares, m)
+		env.searchMiddlewares = append(env.searchMiddlewares, m)
+	}
+	return env
 }
 
 // Handler returns the http.Handler configured for use with an Inspector server. Handler
 // registers the routes on the http.Handler and also registers the websocket handler
 // and the CORS handler if specified by the configuration options.
-func Handler(rpcConfig *config.RPCConfig, routes core.RoutesMap, logger log.Logger) http.Handler {
+func Handler(
+	rpcConfig *config.RPCConfig,
+	routes core.RoutesMap,
+	logger log.Logger,
+	options ...HandlerOption,
+) http.Handler {
+	opts := handlerOptions{maxJSONDepth: defaultMaxJSONDepth}
+	for _, option := range options {
+		option(&opts)
+	}
+
 	mux := http.NewServeMux()
 	wmLogger := logger.With("protocol", "websocket")
-	wm := server.NewWebsocketManager(routes,
-		server.ReadLimit(rpcConfig.MaxBodyBytes))
+	readLimitReason := fmt.Sprintf("message exceeds the limit of %d bytes", rpcConfig.MaxBodyBytes)
+	if opts.wsReadLimitText != nil {
+		readLimitReason = *opts.wsReadLimitText
+	}
+	wsOptions := []server.WSConnectionOption{
+		server.ReadLimit(rpcConfig.MaxBodyBytes),
+		server.ReadLimitReason(readLimitReason),
+		server.IdleTimeout(opts.wsIdleTimeout),
+	}
+	if opts.wsSendBuffer > 0 {
+		metrics := opts.metrics
+		if metrics == nil {
+			metrics = NopMetrics()
+		}
+		wsOptions = append(wsOptions,
+			server.WriteChanCapacity(opts.wsSendBuffer),
+			server.CloseSlowClients(func(string) { metrics.WebsocketSlowClientsClosed.Add(1) }))
+	}
+	wm := server.NewWebsocketManager(routes, wsOptions...)
 	wm.SetLogger(wmLogger)
 	mux.HandleFunc("/websocket", wm.WebsocketHandler)
 
 	server.RegisterRPCFuncs(mux, routes, logger)
-	var rootHandler http.Handler = mux
+	if opts.txSearchStream != nil {
+		switch env := opts.txSearchStream.env; {
+		case env == nil:
+			logger.Error("Not serving the streaming search route: the routes do not serve it")
+		case !env.servesRoute("tx_search"):
+			logger.Info("Not serving the streaming search route: tx_search is not served")
+		default:
+			mux.Handle("/tx_search_stream", txSearchStreamHandler(env,
+				opts.streamSearchTimeout, opts.streamSearchMaxResults))
+		}
+	}
+	if opts.blockMetasStream != nil {
+		mux.Handle("/block_metas_stream", blockMetasStreamHandler(opts.blockMetasStream,
+			rpcConfig.TimestampFormat, logger))
+	}
+	if opts.rawCommits != nil {
+		mux.Handle("/commit_raw", rawCommitHandler(opts.rawCommits))
+	}
+	if opts.metricsAdmin != nil {
+		if opts.authenticator != nil {
+			mux.Handle("/admin/metrics", opts.metricsAdmin)
+		} else {
+			logger.Error("Not serving the metrics admin route without an authenticator")
+		}
+	}
+	var h http.Handler = mux
+	if opts.chunkSize > 0 {
+		h = resultStreamHandler(h, opts.chunkSize)
+	}
+	rootHandler := compactHandler(requestStateHandler(h))
+	if opts.maxJSONDepth > 0 {
+		rootHandler = jsonDepthHandler(rootHandler, opts.maxJSONDepth, logger)
+	}
+	if opts.canonicalJSON {
+		rootHandler = canonicalHandler(rootHandler)
+	}
+	if opts.prettyIndent != "" {
+		rootHandler = prettyHandler(rootHandler, opts.prettyIndent)
+	}
+	if opts.responseLog != nil {
+		rootHandler = responseLogHandler(rootHandler, opts.responseLog, logger)
+	}
+	registry := newCodecRegistry(opts.codecs)
+	rootHandler = codecHandler(rootHandler, registry)
+	// The responses are chunked once rewritten by the handlers above.
+	if opts.chunkSize > 0 {
+		rootHandler = chunkedHandler(rootHandler, opts.chunkSize)
+	}
+	if opts.shortCircuitHead {
+		rootHandler = headHandler(rootHandler, routes, registry)
+	}
+	if opts.statsTrailers {
+		rootHandler = statsTrailerHandler(rootHandler)
+	}
+	if opts.fairQueue != nil {
+		rootHandler = fairQueueHandler(rootHandler, opts.fairQueue, logger)
+	}
+	if opts.authenticator != nil {
+		rootHandler = authHandler(opts.authenticator, rootHandler, logger)
+	}
+	if opts.timestampHeader != "" {
+		rootHandler = timestampHandler(rootHandler, opts.timestampHeader, opts.maxTimestampSkew, time.Now, logger)
+	}
+	if opts.chainID != nil {
+		rootHandler = chainIDHandler(rootHandler, *opts.chainID, logger)
+	}
+	if opts.requireJSONContentType {
+		rootHandler = contentTypeHandler(rootHandler, logger)
+	}
+	rootHandler = clientIPHandler(rootHandler, opts.trustedProxies)
 	if rpcConfig.IsCorsEnabled() {
-		rootHandler = addCORSHandler(rpcConfig, mux)
+		rootHandler = addCORSHandler(rpcConfig, rootHandler, logger)
 	}
 	return rootHandler
 }
 
-func addCORSHandler(rpcConfig *config.RPCConfig, h http.Handler) http.Handler {
-	corsMiddleware := cors.New(cors.Options{
+func addCORSHandler(rpcConfig *config.RPCConfig, h http.Handler, logger log.Logger) http.Handler {
+	allowedMethods := corsAllowedMethods(rpcConfig.CORSAllowedMethods)
+	logger.Info("CORS enabled", "allowed_origins", rpcConfig.CORSAllowedOrigins, "allowed_methods", allowedMethods)
+	corsOptions := cors.Options{
 		AllowedOrigins: rpcConfig.CORSAllowedOrigins,
-		AllowedMethods: rpcConfig.CORSAllowedMethods,
+		AllowedMethods: allowedMethods,
 		AllowedHeaders: rpcConfig.CORSAllowedHeaders,
-	})
+	}
+	if hasOriginPatterns(rpcConfig.CORSAllowedOrigins) {
+		matcher, err := newOriginMatcher(rpcConfig.CORSAllowedOrigins)
+		if err != nil {
+			// Fail closed rather than serve the origins which are valid: the
+			// configuration is not what the operator meant.
+			logger.Error("Refusing all CORS origins: invalid CORS origins", "err", err)
+			matcher = &originMatcher{}
+		}
+		corsOptions.AllowOriginFunc = matcher.allowed
+	}
+	corsMiddleware := cors.New(corsOptions)
 	h = corsMiddleware.Handler(h)
 	return h
 }
 
+// corsAllowedMethods returns the configured CORS methods, with OPTIONS added
+// if missing so that browsers can send preflight requests.
+func corsAllowedMethods(methods []string) []string {
+	for _, method := range methods {
+		if strings.EqualFold(method, http.MethodOptions) {
+			return methods
+		}
+	}
+	return append(append(make([]string, 0, len(methods)+1), methods...), http.MethodOptions)
+}
+
 type waitSyncCheckerImpl struct{}
 
 func (waitSyncCheckerImpl) WaitSync() bool {
@@ -89,7 +612,7 @@ func (waitSyncCheckerImpl) WaitSync() bool {
 // ListenAndServe listens on the address specified in srv.Addr and handles any
 // incoming requests over HTTP using the Inspector rpc handler specified on the server.
 func (srv *Server) ListenAndServe(ctx context.Context) error {
-	listener, err := server.Listen(srv.Addr, srv.Config.MaxOpenConnections)
+	listener, err := srv.listen(ctx)
 	if err != nil {
 		return err
 	}
@@ -97,21 +620,87 @@ func (srv *Server) ListenAndServe(ctx context.Context) error {
 		<-ctx.Done()
 		listener.Close()
 	}()
-	return server.Serve(listener, srv.Handler, srv.Logger, serverRPCConfig(srv.Config))
+	h := srv.handler()
+	if srv.EnableH2C {
+		h = h2cHandler(h)
+	}
+	return server.Serve(listener, h, srv.Logger, serverRPCConfig(srv.Config))
+}
+
+// maxTLSHandshakes returns the maximum number of TLS handshakes run at once,
+// or a negative value if they are not limited.
+func (srv *Server) maxTLSHandshakes() int {
+	if srv.MaxTLSHandshakes == 0 {
+		return runtime.NumCPU()
+	}
+	return srv.MaxTLSHandshakes
 }
 
 // ListenAndServeTLS listens on the address specified in srv.Addr. ListenAndServeTLS handles
 // incoming requests over HTTPS using the Inspector rpc handler specified on the server.
 func (srv *Server) ListenAndServeTLS(ctx context.Context, certFile, keyFile string) error {
-	listener, err := server.Listen(srv.Addr, srv.Config.MaxOpenConnections)
+	listener, err := srv.listen(ctx)
 	if err != nil {
 		return err
 	}
+	maxHandshakes := srv.maxTLSHandshakes()
+	ownConfig := maxHandshakes > 0 || srv.ClientCAs != nil
+	if ownConfig {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		// As in http.Server.ServeTLS, HTTP/2 is negotiated when supported by
+		// the client.
+		tlsConfig := &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			NextProtos:   []string{"h2", "http/1.1"},
+			MinVersion:   tls.VersionTLS12,
+		}
+		if srv.ClientCAs != nil {
+			srv.clientTLSConfig(tlsConfig)
+		}
+		if maxHandshakes > 0 {
+			listener = newHandshakeListener(listener, tlsConfig, maxHandshakes, serverRPCConfig(srv.Config).ReadTimeout)
+		} else {
+			listener = tls.NewListener(listener, tlsConfig)
+		}
+	}
 	go func() {
 		<-ctx.Done()
 		listener.Close()
 	}()
-	return server.ServeTLS(listener, srv.Handler, certFile, keyFile, srv.Logger, serverRPCConfig(srv.Config))
+	if ownConfig {
+		return server.Serve(listener, srv.handler(), srv.Logger, serverRPCConfig(srv.Config))
+	}
+	return server.ServeTLS(listener, srv.handler(), certFile, keyFile, srv.Logger, serverRPCConfig(srv.Config))
+}
+
+// handler returns the Handler of the server along with any of the optional
+// handlers enabled on the server.
+func (srv *Server) handler() http.Handler {
+	h := srv.Handler
+	if srv.EnableUI {
+		mux := http.NewServeMux()
+		mux.Handle("/ui/", uiHandler())
+		mux.Handle("/", h)
+		h = mux
+	}
+	if srv.RequireReady {
+		h = readinessHandler(h, srv, srv.Logger)
+	}
+	if srv.Metrics != nil {
+		h = metricsHandler(h, srv.Metrics)
+	}
+	if srv.Label != "" {
+		next := h
+		h = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(LabelHeader, srv.Label)
+			next.ServeHTTP(w, r)
+		})
+	}
+	return h
 }
 
 func serverRPCConfig(r *config.RPCConfig) *server.Config {
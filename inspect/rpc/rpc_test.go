@@ -0,0 +1,195 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/libs/pubsub/query"
+	"github.com/cometbft/cometbft/rpc/core"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/state"
+	indexermocks "github.com/cometbft/cometbft/state/indexer/mocks"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
+)
+
+func TestErrorCodes(t *testing.T) {
+	testHeight := int64(10)
+	testBase := int64(5)
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Height").Return(testHeight)
+	blockStoreMock.On("Base").Return(testBase)
+	h := newTestHandler(blockStoreMock, &statemocks.Store{})
+
+	testCases := []struct {
+		name   string
+		method string
+		params string
+		code   int
+		data   string
+	}{
+		{"method not found", "dump_consensus_state", `{}`, -32601, ""},
+		{"invalid params", "block", `{"height":"not a height"}`, -32602, "error converting json params"},
+		{"height above latest", "block", `{"height":"11"}`, -32002,
+			"height 11 must be less than or equal to the current blockchain height 10"},
+		{"height below base", "block", `{"height":"4"}`, -32003, "height 4 is not available, lowest height is 5"},
+		{"validators height above latest", "validators", `{"height":"12"}`, -32002,
+			"height 12 must be less than or equal to the current blockchain height 11"},
+		{"commit height below base", "commit", `{"height":"1"}`, -32003,
+			"height 1 is not available, lowest height is 5"},
+		{"non-positive height", "header", `{"height":"0"}`, -32603, "height must be greater than 0, but got 0"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			res := callJSONRPC(t, h, tc.method, tc.params)
+			require.NotNil(t, res.Error)
+			require.Equal(t, tc.code, res.Error.Code)
+			require.Contains(t, res.Error.Data, tc.data)
+		})
+	}
+}
+
+func TestWebsocketErrorCodes(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Height").Return(int64(10))
+	blockStoreMock.On("Base").Return(int64(5))
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("LoadFinalizeBlockResponse", int64(6)).
+		Return((*abcitypes.ResponseFinalizeBlock)(nil), fmt.Errorf("reading results: %w", syscall.EBUSY))
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	txIndexerMock.On("Search", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, _ *query.Query) []*abcitypes.TxResult {
+			<-ctx.Done()
+			return nil
+		},
+		func(ctx context.Context, _ *query.Query) error {
+			return ctx.Err()
+		})
+	key := []byte("cursor key")
+	cursor, err := newTestEnvironment(blockStoreMock, stateStoreMock, CursorKey(key)).encodeCursor(pageCursor{
+		Route:     "block_metas",
+		MinHeight: 5,
+		MaxHeight: 6,
+		Expires:   time.Now().Add(-time.Minute).Unix(),
+	})
+	require.NoError(t, err)
+
+	cfg := config.TestRPCConfig()
+	logger := log.NewNopLogger()
+	routes := Routes(*cfg, stateStoreMock, blockStoreMock, txIndexerMock, &indexermocks.BlockIndexer{}, logger,
+		StoreUnavailable(StoreUnavailableFuncForBackend("goleveldb"), time.Second),
+		IndexerTimeout(10*time.Millisecond), CursorKey(key))
+	srv := httptest.NewServer(Handler(cfg, routes, logger))
+	defer srv.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/websocket", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// The errors of the routes carry the same codes over websocket as over
+	// HTTP.
+	testCases := []struct {
+		method string
+		params string
+		code   int
+	}{
+		{"block_results", `{"height":"6"}`, codeServiceUnavailable},
+		{"block", `{"height":"11"}`, codeHeightAboveLatest},
+		{"block", `{"height":"4"}`, codeHeightBelowBase},
+		{"tx_search", `{"query":"tx.height=1"}`, codeSearchTimeout},
+		{"block_metas", `{"cursor":"` + cursor + `"}`, codeCursorExpired},
+		{"header", `{"height":"0"}`, -32603},
+	}
+	for _, tc := range testCases {
+		req := rpctypes.NewRPCRequest(rpctypes.JSONRPCIntID(1), tc.method, json.RawMessage(tc.params))
+		require.NoError(t, conn.WriteJSON(req))
+		var res rpctypes.RPCResponse
+		require.NoError(t, conn.ReadJSON(&res))
+		require.NotNil(t, res.Error, tc.method)
+		require.Equal(t, tc.code, res.Error.Code, "%s %s: %v", tc.method, tc.params, res.Error)
+	}
+}
+
+func TestDisabledMethods(t *testing.T) {
+	cfg := config.TestRPCConfig()
+	cfg.DisabledRPCMethods = []string{"block_results", "tx_search"}
+	routes := Routes(*cfg, &statemocks.Store{}, &statemocks.BlockStore{}, &txindexmocks.TxIndexer{},
+		&indexermocks.BlockIndexer{}, log.NewNopLogger())
+	require.NotContains(t, routes, "block_results")
+	require.NotContains(t, routes, "tx_search")
+	require.Contains(t, routes, "block")
+
+	res := callJSONRPC(t, Handler(cfg, routes, log.NewNopLogger()), "tx_search", `{"query":"tx.height=1"}`)
+	require.NotNil(t, res.Error)
+	require.Equal(t, -32601, res.Error.Code)
+
+	require.NoError(t, ValidateDisabledMethods(cfg.DisabledRPCMethods))
+	err := ValidateDisabledMethods([]string{"block", "dump_consensus_state"})
+	require.ErrorContains(t, err, "dump_consensus_state")
+}
+
+func TestCORSAllowedMethods(t *testing.T) {
+	cfg := config.TestRPCConfig()
+	cfg.CORSAllowedOrigins = []string{"*"}
+	cfg.CORSAllowedMethods = []string{http.MethodGet, http.MethodPost}
+	require.Equal(t, []string{http.MethodGet, http.MethodPost, http.MethodOptions},
+		corsAllowedMethods(cfg.CORSAllowedMethods))
+	require.Equal(t, []string{"options"}, corsAllowedMethods([]string{"options"}))
+
+	h := Handler(cfg, core.RoutesMap{}, log.NewNopLogger())
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("Origin", "http://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	require.Len(t, cfg.CORSAllowedMethods, 2)
+}
+
+// newTestEnvironment returns the environment of the Inspector routes built
+// from the given stores. Both indexers are mocks without any expectations.
+func newTestEnvironment(bs *statemocks.BlockStore, ss state.Store, options ...RoutesOption) *environment {
+	cfg := config.TestRPCConfig()
+	return newEnvironment(*cfg, ss, bs, &txindexmocks.TxIndexer{}, &indexermocks.BlockIndexer{},
+		log.NewNopLogger(), options...)
+}
+
+// newTestHandler returns the Inspector handler serving the routes built from
+// the given stores. Both indexers are mocks without any expectations.
+func newTestHandler(bs *statemocks.BlockStore, ss *statemocks.Store, options ...RoutesOption) http.Handler {
+	cfg := config.TestRPCConfig()
+	logger := log.NewNopLogger()
+	routes := Routes(*cfg, ss, bs, &txindexmocks.TxIndexer{}, &indexermocks.BlockIndexer{}, logger, options...)
+	return Handler(cfg, routes, logger)
+}
+
+// callJSONRPC sends a JSON-RPC request for method with the given JSON encoded
+// params to h and returns the decoded response.
+func callJSONRPC(t *testing.T, h http.Handler, method, params string) rpctypes.RPCResponse {
+	t.Helper()
+	req := rpctypes.NewRPCRequest(rpctypes.JSONRPCIntID(1), method, json.RawMessage(params))
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+
+	var res rpctypes.RPCResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	return res
+}
@@ -0,0 +1,110 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// codeSearchTimeout is the JSON-RPC error code returned for the searches
+// canceled because they exceeded the search timeout.
+const codeSearchTimeout = -32004
+
+// searchTimeoutError returns the error of the searches canceled after
+// timeout.
+func searchTimeoutError(timeout time.Duration) error {
+	return &rpctypes.RPCError{
+		Code:    codeSearchTimeout,
+		Message: "Search timed out",
+		Data:    searchTimeoutMessage(timeout),
+	}
+}
+
+func searchTimeoutMessage(timeout time.Duration) string {
+	return fmt.Sprintf("search exceeded the timeout of %s; narrow the query, for instance with a height range",
+		timeout)
+}
+
+// searchRetryAfter is the delay after which clients are asked to retry
+// searches rejected because too many searches are running.
+const searchRetryAfter = time.Second
+
+// errTooManySearches is returned by the search routes when the maximum number
+// of concurrent searches is reached.
+var errTooManySearches = errors.New("too many concurrent searches")
+
+// MaxConcurrentSearches sets the maximum number of tx_search and block_search
+// calls running at once. Searches beyond the limit are rejected immediately
+// with a service unavailable error, so that a burst of searches does not
+// starve the other routes. It defaults to the number of CPUs. A value of 0
+// disables the limit.
+func MaxConcurrentSearches(n int) RoutesOption {
+	return func(env *environment) {
+		env.maxConcurrentSearches = n
+	}
+}
+
+// searchLimitMiddleware rejects the calls to the search routes when the
+// number of searches running at once would exceed maxSearches.
+func searchLimitMiddleware(maxSearches int) routeMiddleware {
+	searches := semaphore.NewWeighted(int64(maxSearches))
+	return func(route string, next routeHandler) routeHandler {
+		if !queryRoutes[route] {
+			return next
+		}
+		return func(ctx *rpctypes.Context, args []reflect.Value) (interface{}, error) {
+			if !searches.TryAcquire(1) {
+				return nil, serviceUnavailableError(ctx, errTooManySearches, searchRetryAfter)
+			}
+			defer searches.Release(1)
+			return next(ctx, args)
+		}
+	}
+}
+
+// searchPageArgs maps the paginated search routes to the index of their page
+// argument.
+var searchPageArgs = map[string]int{
+	"tx_search":    2,
+	"block_search": 1,
+}
+
+// MaxSearchPage sets the maximum page number accepted by tx_search and
+// block_search. Requests for later pages are rejected with an error asking the
+// client to narrow its query instead, such as with a height range, so that
+// clients cannot page through large result sets one request at a time. A
+// value of 0, the default, disables the limit.
+func MaxSearchPage(page int) RoutesOption {
+	return func(env *environment) {
+		env.maxSearchPage = page
+	}
+}
+
+// searchPageMiddleware rejects the calls to the search routes for a page
+// beyond maxPage.
+func searchPageMiddleware(maxPage int) routeMiddleware {
+	return func(route string, next routeHandler) routeHandler {
+		index, ok := searchPageArgs[route]
+		if !ok {
+			return next
+		}
+		return func(ctx *rpctypes.Context, args []reflect.Value) (interface{}, error) {
+			if index < len(args) {
+				if page, _ := args[index].Interface().(*int); page != nil && *page > maxPage {
+					return nil, &rpctypes.RPCError{
+						Code:    codeInvalidParams,
+						Message: "Invalid params",
+						Data: fmt.Sprintf("page %d exceeds the maximum of %d; narrow the query instead, "+
+							"for instance with a height range", *page, maxPage),
+					}
+				}
+			}
+			return next(ctx, args)
+		}
+	}
+}
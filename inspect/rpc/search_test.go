@@ -0,0 +1,67 @@
+package rpc
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+)
+
+func TestMaxConcurrentSearches(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var blocking routeHandler = func(*rpctypes.Context, []reflect.Value) (interface{}, error) {
+		started <- struct{}{}
+		<-release
+		return nil, nil
+	}
+	var instant routeHandler = func(*rpctypes.Context, []reflect.Value) (interface{}, error) {
+		return nil, nil
+	}
+	middleware := searchLimitMiddleware(1)
+
+	done := make(chan error)
+	go func() {
+		_, err := middleware("tx_search", blocking)(nil, nil)
+		done <- err
+	}()
+	<-started
+
+	// The limit is shared by both search routes, but not by other routes.
+	_, err := middleware("block_search", instant)(nil, nil)
+	require.Error(t, err)
+	require.Equal(t, codeServiceUnavailable, err.(*rpctypes.RPCError).Code)
+	require.Contains(t, err.(*rpctypes.RPCError).Data, errTooManySearches.Error())
+	_, err = middleware("block", instant)(nil, nil)
+	require.NoError(t, err)
+
+	close(release)
+	require.NoError(t, <-done)
+	_, err = middleware("block_search", instant)(nil, nil)
+	require.NoError(t, err)
+}
+
+func TestMaxSearchPage(t *testing.T) {
+	h := newTestHandler(&statemocks.BlockStore{}, &statemocks.Store{}, MaxSearchPage(2))
+	for method, params := range map[string]string{
+		"tx_search":    `{"query":"tx.height=1","page":"3"}`,
+		"block_search": `{"query":"block.height=1","page":"3"}`,
+	} {
+		res := callJSONRPC(t, h, method, params)
+		require.NotNil(t, res.Error, method)
+		require.Equal(t, codeInvalidParams, res.Error.Code)
+		require.Contains(t, res.Error.Data, "page 3 exceeds the maximum of 2")
+	}
+
+	var next routeHandler = func(*rpctypes.Context, []reflect.Value) (interface{}, error) {
+		return nil, nil
+	}
+	page := 2
+	args := []reflect.Value{reflect.ValueOf(""), reflect.ValueOf(&page)}
+	_, err := searchPageMiddleware(2)("block_search", next)(nil, args)
+	require.NoError(t, err)
+	_, err = searchPageMiddleware(1)("block_search", next)(nil, args)
+	require.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cometbft/cometbft/crypto"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+)
+
+// CommitSigner is a signature of a commit joined with the validator at its
+// index in the validator set.
+type CommitSigner struct {
+	Index       int               `json:"index"`
+	Address     types.Address     `json:"address"`
+	BlockIDFlag types.BlockIDFlag `json:"block_id_flag"`
+	Timestamp   time.Time         `json:"timestamp"`
+	// PubKey and VotingPower are only set if the validator set at the height
+	// of the commit is available.
+	PubKey      crypto.PubKey `json:"pub_key,omitempty"`
+	VotingPower int64         `json:"voting_power,omitempty"`
+}
+
+// ResultCommitSigners is the result of the commit_signers route.
+type ResultCommitSigners struct {
+	Height    int64          `json:"height"`
+	Round     int32          `json:"round"`
+	BlockID   types.BlockID  `json:"block_id"`
+	Canonical bool           `json:"canonical"`
+	Signers   []CommitSigner `json:"signers"`
+	// ValidatorsAvailable is false if the validator set at the height of the
+	// commit is missing from the state store, for instance after pruning.
+	// The voting powers are then left unset.
+	ValidatorsAvailable bool  `json:"validators_available"`
+	SignedVotingPower   int64 `json:"signed_voting_power"`
+	TotalVotingPower    int64 `json:"total_voting_power"`
+}
+
+// CommitSigners returns the signatures of the commit at the given height, as
+// returned by the commit route, along with the validators that made them.
+// Signatures are in validator set order, including absent ones. The stores
+// do not hold validator monikers, so validators are identified by address
+// and public key.
+func (env *environment) CommitSigners(ctx *rpctypes.Context, heightPtr *int64) (*ResultCommitSigners, error) {
+	resultCommit, err := env.Commit(ctx, heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	if resultCommit == nil || resultCommit.Commit == nil {
+		return nil, fmt.Errorf("commit not found")
+	}
+	commit := resultCommit.Commit
+
+	// A missing validator set is not an error: the signatures are returned
+	// with the addresses recorded in the commit.
+	vals, err := env.StateStore.LoadValidators(commit.Height)
+	var errNoValSet state.ErrNoValSetForHeight
+	switch {
+	case errors.As(err, &errNoValSet):
+		vals = nil
+	case err != nil:
+		return nil, err
+	case vals.Size() != len(commit.Signatures):
+		return nil, fmt.Errorf("commit at height %d has %d signatures, but validator set has %d validators",
+			commit.Height, len(commit.Signatures), vals.Size())
+	}
+
+	res := &ResultCommitSigners{
+		Height:              commit.Height,
+		Round:               commit.Round,
+		BlockID:             commit.BlockID,
+		Canonical:           resultCommit.CanonicalCommit,
+		Signers:             make([]CommitSigner, len(commit.Signatures)),
+		ValidatorsAvailable: vals != nil,
+	}
+	for i, sig := range commit.Signatures {
+		signer := CommitSigner{
+			Index:       i,
+			Address:     sig.ValidatorAddress,
+			BlockIDFlag: sig.BlockIDFlag,
+			Timestamp:   sig.Timestamp,
+		}
+		if vals != nil {
+			val := vals.Validators[i]
+			signer.Address = val.Address
+			signer.PubKey = val.PubKey
+			signer.VotingPower = val.VotingPower
+			if sig.BlockIDFlag == types.BlockIDFlagCommit {
+				res.SignedVotingPower += val.VotingPower
+			}
+		}
+		res.Signers[i] = signer
+	}
+	if vals != nil {
+		res.TotalVotingPower = vals.TotalVotingPower()
+	}
+
+	return res, nil
+}
@@ -0,0 +1,67 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/state"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestCommitSigners(t *testing.T) {
+	height := int64(2)
+	vals, _ := types.RandValidatorSet(3, 10)
+	commit := &types.Commit{Height: height}
+	for i, val := range vals.Validators {
+		sig := types.CommitSig{
+			BlockIDFlag:      types.BlockIDFlagCommit,
+			ValidatorAddress: val.Address,
+			Timestamp:        time.Now(),
+		}
+		if i == 2 {
+			sig = types.NewCommitSigAbsent()
+		}
+		commit.Signatures = append(commit.Signatures, sig)
+	}
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(height)
+	blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{Header: types.Header{Height: height}})
+	blockStoreMock.On("LoadSeenCommit", height).Return(commit)
+
+	t.Run("validators available", func(t *testing.T) {
+		stateStoreMock := &statemocks.Store{}
+		stateStoreMock.On("LoadValidators", height).Return(vals, nil)
+		env := newTestEnvironment(blockStoreMock, stateStoreMock)
+
+		res, err := env.CommitSigners(nil, nil)
+		require.NoError(t, err)
+		require.True(t, res.ValidatorsAvailable)
+		require.False(t, res.Canonical)
+		require.Len(t, res.Signers, 3)
+		for i, signer := range res.Signers {
+			require.Equal(t, vals.Validators[i].Address, signer.Address)
+			require.Equal(t, int64(10), signer.VotingPower)
+		}
+		require.Equal(t, types.BlockIDFlagAbsent, res.Signers[2].BlockIDFlag)
+		require.Equal(t, int64(20), res.SignedVotingPower)
+		require.Equal(t, int64(30), res.TotalVotingPower)
+	})
+
+	t.Run("validators missing", func(t *testing.T) {
+		stateStoreMock := &statemocks.Store{}
+		stateStoreMock.On("LoadValidators", height).Return(nil, state.ErrNoValSetForHeight{Height: height})
+		env := newTestEnvironment(blockStoreMock, stateStoreMock)
+
+		res, err := env.CommitSigners(nil, &height)
+		require.NoError(t, err)
+		require.False(t, res.ValidatorsAvailable)
+		require.Len(t, res.Signers, 3)
+		require.Equal(t, vals.Validators[0].Address, res.Signers[0].Address)
+		require.Zero(t, res.Signers[0].VotingPower)
+		require.Zero(t, res.TotalVotingPower)
+	})
+}
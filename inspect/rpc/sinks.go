@@ -0,0 +1,154 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/pubsub/query"
+	"github.com/cometbft/cometbft/state/indexer"
+	"github.com/cometbft/cometbft/state/txindex"
+)
+
+// EventSinkHeader is the response header naming the event sink which served
+// the searches of a request, when several event sinks are set with
+// EventSinks.
+const EventSinkHeader = "X-Inspect-Event-Sink"
+
+// EventSink is a named pair of transaction and block indexers.
+type EventSink struct {
+	Name         string
+	TxIndexer    txindex.TxIndexer
+	BlockIndexer indexer.BlockIndexer
+}
+
+// EventSinks serves the routes reading the indexers from sinks, in the given
+// order of priority, instead of the indexers given to Routes. A read failing
+// on a sink is retried on the next one, and fails only if it fails on all of
+// them. The name of the sink which served the searches of a request is sent
+// in the EventSinkHeader header of the response. The timeout and circuit
+// breaker of the indexers apply to every sink separately.
+func EventSinks(sinks ...EventSink) RoutesOption {
+	return func(env *environment) {
+		env.eventSinks = sinks
+	}
+}
+
+// wrapIndexers replaces the indexers of env, or those of each of its event
+// sinks if any, with the indexers returned by wrap.
+func (env *environment) wrapIndexers(
+	wrap func(txindex.TxIndexer, indexer.BlockIndexer) (txindex.TxIndexer, indexer.BlockIndexer),
+) {
+	if len(env.eventSinks) == 0 {
+		env.TxIndexer, env.BlockIndexer = wrap(env.TxIndexer, env.BlockIndexer)
+		return
+	}
+	for i := range env.eventSinks {
+		sink := &env.eventSinks[i]
+		sink.TxIndexer, sink.BlockIndexer = wrap(sink.TxIndexer, sink.BlockIndexer)
+	}
+}
+
+// useEventSinks sets the indexers of env to read from its event sinks, if
+// any.
+func (env *environment) useEventSinks() {
+	if len(env.eventSinks) == 0 {
+		return
+	}
+	env.TxIndexer = fallbackTxIndexer{TxIndexer: env.eventSinks[0].TxIndexer, sinks: env.eventSinks}
+	env.BlockIndexer = fallbackBlockIndexer{BlockIndexer: env.eventSinks[0].BlockIndexer, sinks: env.eventSinks}
+}
+
+// eventSinkErrors are the errors of a read which failed on every event sink.
+type eventSinkErrors []error
+
+func (errs eventSinkErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return "all event sinks failed: " + strings.Join(msgs, "; ")
+}
+
+func (errs eventSinkErrors) Unwrap() []error { return errs }
+
+// readSinks calls read on the sinks in order until it succeeds, recording
+// the name of the sink which served it in the state of the request of ctx.
+// The reads stop when ctx is done.
+func readSinks(ctx context.Context, sinks []EventSink, read func(EventSink) error) error {
+	var errs eventSinkErrors
+	for _, sink := range sinks {
+		err := read(sink)
+		if err == nil {
+			if state := requestStateFrom(ctx); state != nil {
+				state.eventSink = sink.Name
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", sink.Name, err))
+	}
+	if len(errs) == 1 {
+		return errors.Unwrap(errs[0])
+	}
+	return errs
+}
+
+// fallbackTxIndexer is a TxIndexer reading from event sinks in order. Writes
+// go to the first sink, as they are not expected in the Inspector.
+type fallbackTxIndexer struct {
+	txindex.TxIndexer
+	sinks []EventSink
+}
+
+func (idx fallbackTxIndexer) Get(hash []byte) (res *abci.TxResult, err error) {
+	err = readSinks(context.Background(), idx.sinks, func(sink EventSink) error {
+		res, err = sink.TxIndexer.Get(hash)
+		return err
+	})
+	return res, err
+}
+
+func (idx fallbackTxIndexer) Search(ctx context.Context, q *query.Query) (res []*abci.TxResult, err error) {
+	err = readSinks(ctx, idx.sinks, func(sink EventSink) error {
+		res, err = sink.TxIndexer.Search(ctx, q)
+		return err
+	})
+	return res, err
+}
+
+// searchTxs implements txSearcher. The transactions are loaded from the sink
+// which served the search.
+func (idx fallbackTxIndexer) searchTxs(ctx context.Context, q *query.Query, desc bool) (it txIterator, err error) {
+	err = readSinks(ctx, idx.sinks, func(sink EventSink) error {
+		it, err = searchTxs(ctx, sink.TxIndexer, q, desc)
+		return err
+	})
+	return it, err
+}
+
+// fallbackBlockIndexer is a BlockIndexer reading from event sinks in order.
+type fallbackBlockIndexer struct {
+	indexer.BlockIndexer
+	sinks []EventSink
+}
+
+func (idx fallbackBlockIndexer) Has(height int64) (has bool, err error) {
+	err = readSinks(context.Background(), idx.sinks, func(sink EventSink) error {
+		has, err = sink.BlockIndexer.Has(height)
+		return err
+	})
+	return has, err
+}
+
+func (idx fallbackBlockIndexer) Search(ctx context.Context, q *query.Query) (res []int64, err error) {
+	err = readSinks(ctx, idx.sinks, func(sink EventSink) error {
+		res, err = sink.BlockIndexer.Search(ctx, q)
+		return err
+	})
+	return res, err
+}
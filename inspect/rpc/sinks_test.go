@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	indexermocks "github.com/cometbft/cometbft/state/indexer/mocks"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
+)
+
+func TestEventSinks(t *testing.T) {
+	primary := &txindexmocks.TxIndexer{}
+	primary.On("Search", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+	secondary := &txindexmocks.TxIndexer{}
+	secondary.On("Search", mock.Anything, mock.Anything).Return(
+		[]*abcitypes.TxResult{{Height: 1, Tx: []byte("tx")}}, nil).Once()
+	secondary.On("Search", mock.Anything, mock.Anything).Return(nil, errors.New("timeout"))
+
+	cfg := config.TestRPCConfig()
+	logger := log.NewNopLogger()
+	routes := Routes(*cfg, &statemocks.Store{}, &statemocks.BlockStore{}, &txindexmocks.TxIndexer{},
+		&indexermocks.BlockIndexer{}, logger, EventSinks(
+			EventSink{Name: "psql", TxIndexer: primary, BlockIndexer: &indexermocks.BlockIndexer{}},
+			EventSink{Name: "kv", TxIndexer: secondary, BlockIndexer: &indexermocks.BlockIndexer{}},
+		))
+	h := Handler(cfg, routes, logger)
+
+	search := func() *httptest.ResponseRecorder {
+		body := `{"jsonrpc":"2.0","id":1,"method":"tx_search","params":{"query":"tx.height = 1"}}`
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body))))
+		return rec
+	}
+
+	// The search falls back to the second sink.
+	rec := search()
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "kv", rec.Header().Get(EventSinkHeader))
+	require.Contains(t, rec.Body.String(), `"total_count":"1"`)
+
+	// The search fails once it fails on every sink.
+	rec = search()
+	require.Empty(t, rec.Header().Get(EventSinkHeader))
+	require.Contains(t, rec.Body.String(), "all event sinks failed: psql: connection refused; kv: timeout")
+	primary.AssertNumberOfCalls(t, "Search", 2)
+}
@@ -0,0 +1,277 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+)
+
+// ResultState is the result of the state route: the consensus state of the
+// node after committing the block at LastBlockHeight.
+//
+// The fields that cannot be retrieved from the stores are null, or empty for
+// hashes.
+type ResultState struct {
+	ChainID         string `json:"chain_id"`
+	InitialHeight   int64  `json:"initial_height"`
+	LastBlockHeight int64  `json:"last_block_height"`
+	// Stored is true if the state is the one stored by the node, which is
+	// only retained for the latest height. The state at other heights is
+	// rebuilt from the validator sets, consensus params and headers in the
+	// stores.
+	Stored bool `json:"stored"`
+
+	LastBlockID   *types.BlockID `json:"last_block_id"`
+	LastBlockTime *time.Time     `json:"last_block_time"`
+
+	NextValidators  *types.ValidatorSet    `json:"next_validators"`
+	Validators      *types.ValidatorSet    `json:"validators"`
+	LastValidators  *types.ValidatorSet    `json:"last_validators"`
+	ConsensusParams *types.ConsensusParams `json:"consensus_params"`
+
+	LastHeightValidatorsChanged      int64 `json:"last_height_validators_changed,omitempty"`
+	LastHeightConsensusParamsChanged int64 `json:"last_height_consensus_params_changed,omitempty"`
+
+	LastResultsHash bytes.HexBytes `json:"last_results_hash"`
+	AppHash         bytes.HexBytes `json:"app_hash"`
+}
+
+// State returns the consensus state after committing the block at the given
+// height, or the latest state stored if no height is given.
+func (env *environment) State(_ *rpctypes.Context, heightPtr *int64) (*ResultState, error) {
+	latest, err := env.StateStore.Load()
+	if err != nil {
+		return nil, err
+	}
+	if latest.IsEmpty() {
+		return nil, errors.New("no state stored")
+	}
+	if heightPtr == nil || *heightPtr == latest.LastBlockHeight {
+		return storedState(latest), nil
+	}
+	height, err := env.getHeight(latest.LastBlockHeight, heightPtr)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ResultState{
+		ChainID:         latest.ChainID,
+		InitialHeight:   latest.InitialHeight,
+		LastBlockHeight: height,
+	}
+	if blockMeta := env.BlockStore.LoadBlockMeta(height); blockMeta != nil {
+		res.LastBlockID = &blockMeta.BlockID
+		res.LastBlockTime = &blockMeta.Header.Time
+	}
+	// The hashes resulting from the execution of the block are in the header
+	// of the next block.
+	if blockMeta := env.BlockStore.LoadBlockMeta(height + 1); blockMeta != nil {
+		res.LastResultsHash = blockMeta.Header.LastResultsHash
+		res.AppHash = blockMeta.Header.AppHash
+	}
+	if res.LastValidators, err = env.loadValidators(height); err != nil {
+		return nil, err
+	}
+	if res.Validators, err = env.loadValidators(height + 1); err != nil {
+		return nil, err
+	}
+	if res.NextValidators, err = env.loadValidators(height + 2); err != nil {
+		return nil, err
+	}
+	params, _, err := env.loadConsensusParams(height + 1)
+	var errNoParams state.ErrNoConsensusParamsForHeight
+	switch {
+	case errors.As(err, &errNoParams):
+	case err != nil:
+		return nil, err
+	default:
+		res.ConsensusParams = &params
+	}
+
+	return res, nil
+}
+
+func storedState(s state.State) *ResultState {
+	return &ResultState{
+		ChainID:                          s.ChainID,
+		InitialHeight:                    s.InitialHeight,
+		LastBlockHeight:                  s.LastBlockHeight,
+		Stored:                           true,
+		LastBlockID:                      &s.LastBlockID,
+		LastBlockTime:                    &s.LastBlockTime,
+		NextValidators:                   s.NextValidators,
+		Validators:                       s.Validators,
+		LastValidators:                   s.LastValidators,
+		ConsensusParams:                  &s.ConsensusParams,
+		LastHeightValidatorsChanged:      s.LastHeightValidatorsChanged,
+		LastHeightConsensusParamsChanged: s.LastHeightConsensusParamsChanged,
+		LastResultsHash:                  s.LastResultsHash,
+		AppHash:                          s.AppHash,
+	}
+}
+
+// ResultConsensusParams is the result of the consensus_params route.
+type ResultConsensusParams struct {
+	BlockHeight     int64                 `json:"block_height"`
+	ConsensusParams types.ConsensusParams `json:"consensus_params"`
+	// LastHeightChanged is the height at which the params were set, or 0 if
+	// the state store does not report it.
+	LastHeightChanged int64 `json:"last_height_changed"`
+	// Inherited is true if the params were set below BlockHeight and
+	// carried over to it, rather than set at BlockHeight.
+	Inherited bool `json:"inherited"`
+}
+
+// consensusParamsStore is implemented by the state stores which report the
+// height at which the consensus params of a height were set, and the heights
+// without params with state.ErrNoConsensusParamsForHeight, such as the state
+// stores returned by state.NewStore.
+type consensusParamsStore interface {
+	LoadConsensusParamsAndChangeHeight(height int64) (types.ConsensusParams, int64, error)
+}
+
+// ConsensusParams returns the consensus params in effect at the given height,
+// or at the height after the latest block if no height is given.
+//
+// If no params are stored for the height, such as when the state store was
+// pruned, they resolve to the last params stored below it, down to the base
+// of the block store. An error is only returned if no such params are stored.
+func (env *environment) ConsensusParams(ctx *rpctypes.Context, heightPtr *int64) (*ResultConsensusParams, error) {
+	height, err := env.getHeight(env.BlockStore.Height()+1, heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	base := env.BlockStore.Base()
+	for h := height; h > 0 && h >= base; h-- {
+		if err := requestContext(ctx).Err(); err != nil {
+			return nil, err
+		}
+		params, changed, err := env.loadConsensusParams(h)
+		var errNoParams state.ErrNoConsensusParamsForHeight
+		if errors.As(err, &errNoParams) && errNoParams.Height == h {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		res := &ResultConsensusParams{
+			BlockHeight:       height,
+			ConsensusParams:   params,
+			LastHeightChanged: changed,
+			Inherited:         h < height,
+		}
+		if changed > 0 {
+			res.Inherited = changed < height
+		}
+		return res, nil
+	}
+	return nil, state.ErrNoConsensusParamsForHeight{Height: height}
+}
+
+// loadConsensusParams loads the consensus params stored for height, and the
+// height at which they were set if the state store reports it, or 0.
+func (env *environment) loadConsensusParams(height int64) (types.ConsensusParams, int64, error) {
+	if env.paramsStore != nil {
+		return env.paramsStore.LoadConsensusParamsAndChangeHeight(height)
+	}
+	params, err := env.StateStore.LoadConsensusParams(height)
+	return params, 0, err
+}
+
+// ConsensusParamChange is a consensus param whose value differs between two
+// heights. Param is the path of the param in the JSON encoding of the
+// consensus params, such as "block.max_bytes", and From and To are its JSON
+// encoded values.
+type ConsensusParamChange struct {
+	Param string          `json:"param"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
+
+// ResultConsensusParamsDiff is the result of the consensus_params_diff route.
+type ResultConsensusParamsDiff struct {
+	FromHeight int64 `json:"from_height"`
+	ToHeight   int64 `json:"to_height"`
+	// FromLastHeightChanged and ToLastHeightChanged are the heights at which
+	// the params in effect at FromHeight and ToHeight were set.
+	FromLastHeightChanged int64                  `json:"from_last_height_changed"`
+	ToLastHeightChanged   int64                  `json:"to_last_height_changed"`
+	Changes               []ConsensusParamChange `json:"changes"`
+}
+
+// ConsensusParamsDiff returns the consensus params whose values differ
+// between the params in effect at fromHeight and at toHeight, as resolved by
+// the consensus_params route, in the order of the consensus params.
+func (env *environment) ConsensusParamsDiff(
+	ctx *rpctypes.Context,
+	fromHeight, toHeight int64,
+) (*ResultConsensusParamsDiff, error) {
+	from, err := env.ConsensusParams(ctx, &fromHeight)
+	if err != nil {
+		return nil, fmt.Errorf("from height: %w", err)
+	}
+	to, err := env.ConsensusParams(ctx, &toHeight)
+	if err != nil {
+		return nil, fmt.Errorf("to height: %w", err)
+	}
+	changes, err := consensusParamChanges(from.ConsensusParams, to.ConsensusParams)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultConsensusParamsDiff{
+		FromHeight:            fromHeight,
+		ToHeight:              toHeight,
+		FromLastHeightChanged: from.LastHeightChanged,
+		ToLastHeightChanged:   to.LastHeightChanged,
+		Changes:               changes,
+	}, nil
+}
+
+// consensusParamChanges returns the params of the sections of the consensus
+// params which differ from from to to.
+func consensusParamChanges(from, to types.ConsensusParams) ([]ConsensusParamChange, error) {
+	changes := make([]ConsensusParamChange, 0)
+	fromSections, toSections := reflect.ValueOf(from), reflect.ValueOf(to)
+	for i := 0; i < fromSections.NumField(); i++ {
+		section := jsonFieldName(fromSections.Type().Field(i))
+		fromParams, toParams := fromSections.Field(i), toSections.Field(i)
+		for j := 0; j < fromParams.NumField(); j++ {
+			fromValue, toValue := fromParams.Field(j).Interface(), toParams.Field(j).Interface()
+			if reflect.DeepEqual(fromValue, toValue) {
+				continue
+			}
+			fromJSON, err := cmtjson.Marshal(fromValue)
+			if err != nil {
+				return nil, err
+			}
+			toJSON, err := cmtjson.Marshal(toValue)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, ConsensusParamChange{
+				Param: section + "." + jsonFieldName(fromParams.Type().Field(j)),
+				From:  fromJSON,
+				To:    toJSON,
+			})
+		}
+	}
+	return changes, nil
+}
+
+// jsonFieldName returns the name of field in the JSON encoding of its struct.
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
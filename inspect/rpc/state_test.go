@@ -0,0 +1,221 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	dbm "github.com/cometbft/cometbft-db"
+
+	"github.com/cometbft/cometbft/state"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestState(t *testing.T) {
+	vals, _ := types.RandValidatorSet(2, 10)
+	latest := state.State{
+		ChainID:         "test-chain",
+		InitialHeight:   1,
+		LastBlockHeight: 5,
+		Validators:      vals,
+		AppHash:         []byte{5},
+	}
+	params := types.DefaultConsensusParams()
+
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("LoadBlockMeta", int64(3)).Return(&types.BlockMeta{Header: types.Header{Height: 3}})
+	blockStoreMock.On("LoadBlockMeta", int64(4)).Return(&types.BlockMeta{
+		Header: types.Header{Height: 4, AppHash: []byte{3}, LastResultsHash: []byte{33}},
+	})
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("Load").Return(latest, nil)
+	stateStoreMock.On("LoadValidators", int64(3)).Return(vals, nil)
+	stateStoreMock.On("LoadValidators", int64(4)).Return(vals, nil)
+	stateStoreMock.On("LoadValidators", int64(5)).Return(nil, state.ErrNoValSetForHeight{Height: 5})
+	stateStoreMock.On("LoadConsensusParams", int64(4)).Return(*params, nil)
+	env := newTestEnvironment(blockStoreMock, stateStoreMock)
+
+	res, err := env.State(nil, nil)
+	require.NoError(t, err)
+	require.True(t, res.Stored)
+	require.Equal(t, int64(5), res.LastBlockHeight)
+	require.Equal(t, vals, res.Validators)
+
+	height := int64(3)
+	res, err = env.State(nil, &height)
+	require.NoError(t, err)
+	require.False(t, res.Stored)
+	require.Equal(t, "test-chain", res.ChainID)
+	require.Equal(t, int64(3), res.LastBlockHeight)
+	require.NotNil(t, res.LastBlockID)
+	require.Equal(t, vals, res.LastValidators)
+	require.Equal(t, vals, res.Validators)
+	require.Nil(t, res.NextValidators)
+	require.Equal(t, params, res.ConsensusParams)
+	require.EqualValues(t, []byte{3}, res.AppHash)
+	require.EqualValues(t, []byte{33}, res.LastResultsHash)
+
+	height = 6
+	_, err = env.State(nil, &height)
+	require.ErrorContains(t, err, "current blockchain height 5")
+}
+
+func TestStateMissingParams(t *testing.T) {
+	vals, _ := types.RandValidatorSet(2, 10)
+	stateStore := state.NewStore(dbm.NewMemDB(), state.StoreOptions{})
+	require.NoError(t, stateStore.Save(state.State{
+		ChainID:         "test-chain",
+		InitialHeight:   1,
+		LastBlockHeight: 5,
+		NextValidators:  vals,
+		Validators:      vals,
+		LastValidators:  vals,
+		ConsensusParams: *types.DefaultConsensusParams(),
+	}))
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("LoadBlockMeta", mock.Anything).Return(nil)
+	env := newTestEnvironment(blockStoreMock, stateStore)
+
+	// The state store holds no params for height 4, which the state reports
+	// as null rather than failing.
+	height := int64(3)
+	res, err := env.State(nil, &height)
+	require.NoError(t, err)
+	require.Nil(t, res.ConsensusParams)
+}
+
+// paramsStoreMock is a state store mock reporting the heights at which the
+// consensus params were set.
+type paramsStoreMock struct {
+	*statemocks.Store
+}
+
+func (m paramsStoreMock) LoadConsensusParamsAndChangeHeight(height int64) (types.ConsensusParams, int64, error) {
+	ret := m.Called(height)
+	return ret.Get(0).(types.ConsensusParams), ret.Get(1).(int64), ret.Error(2)
+}
+
+func TestConsensusParams(t *testing.T) {
+	params := types.DefaultConsensusParams()
+
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(5))
+	stateStoreMock := paramsStoreMock{&statemocks.Store{}}
+	stateStoreMock.On("LoadConsensusParamsAndChangeHeight", int64(6)).Return(*params, int64(2), nil)
+	stateStoreMock.On("LoadConsensusParamsAndChangeHeight", int64(2)).Return(*params, int64(2), nil)
+	for _, h := range []int64{1, 3, 4} {
+		stateStoreMock.On("LoadConsensusParamsAndChangeHeight", h).
+			Return(types.ConsensusParams{}, int64(0), state.ErrNoConsensusParamsForHeight{Height: h})
+	}
+	env := newTestEnvironment(blockStoreMock, stateStoreMock)
+
+	res, err := env.ConsensusParams(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(6), res.BlockHeight)
+	require.Equal(t, int64(2), res.LastHeightChanged)
+	require.True(t, res.Inherited)
+
+	// The params of pruned heights resolve to the last params stored below.
+	height := int64(4)
+	res, err = env.ConsensusParams(nil, &height)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), res.BlockHeight)
+	require.Equal(t, *params, res.ConsensusParams)
+	require.Equal(t, int64(2), res.LastHeightChanged)
+	require.True(t, res.Inherited)
+
+	height = 2
+	res, err = env.ConsensusParams(nil, &height)
+	require.NoError(t, err)
+	require.False(t, res.Inherited)
+
+	height = 1
+	_, err = env.ConsensusParams(nil, &height)
+	require.ErrorAs(t, err, &state.ErrNoConsensusParamsForHeight{})
+}
+
+func TestConsensusParamsBase(t *testing.T) {
+	params := types.DefaultConsensusParams()
+
+	// The walk stops at the base of the block store.
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(3))
+	blockStoreMock.On("Height").Return(int64(5))
+	stateStoreMock := paramsStoreMock{&statemocks.Store{}}
+	stateStoreMock.On("LoadConsensusParamsAndChangeHeight", int64(2)).Return(*params, int64(2), nil)
+	for _, h := range []int64{3, 4} {
+		stateStoreMock.On("LoadConsensusParamsAndChangeHeight", h).
+			Return(types.ConsensusParams{}, int64(0), state.ErrNoConsensusParamsForHeight{Height: h})
+	}
+	env := newTestEnvironment(blockStoreMock, stateStoreMock)
+
+	height := int64(4)
+	_, err := env.ConsensusParams(nil, &height)
+	require.ErrorAs(t, err, &state.ErrNoConsensusParamsForHeight{})
+	stateStoreMock.AssertNotCalled(t, "LoadConsensusParamsAndChangeHeight", int64(2))
+}
+
+func TestConsensusParamsWithoutChangeHeight(t *testing.T) {
+	params := types.DefaultConsensusParams()
+
+	// Without LoadConsensusParamsAndChangeHeight, the height at which the
+	// params were set is unknown.
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(5))
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("LoadConsensusParams", int64(3)).Return(*params, nil)
+	stateStoreMock.On("LoadConsensusParams", int64(4)).
+		Return(types.ConsensusParams{}, state.ErrNoConsensusParamsForHeight{Height: 4})
+	env := newTestEnvironment(blockStoreMock, stateStoreMock)
+
+	height := int64(4)
+	res, err := env.ConsensusParams(nil, &height)
+	require.NoError(t, err)
+	require.Equal(t, *params, res.ConsensusParams)
+	require.Zero(t, res.LastHeightChanged)
+	require.True(t, res.Inherited)
+
+	height = 3
+	res, err = env.ConsensusParams(nil, &height)
+	require.NoError(t, err)
+	require.False(t, res.Inherited)
+}
+
+func TestConsensusParamsDiff(t *testing.T) {
+	params := types.DefaultConsensusParams()
+	updated := *params
+	updated.Block.MaxBytes = 1024
+	updated.Validator.PubKeyTypes = []string{types.ABCIPubKeyTypeSecp256k1}
+
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(5))
+	stateStoreMock := paramsStoreMock{&statemocks.Store{}}
+	stateStoreMock.On("LoadConsensusParamsAndChangeHeight", int64(2)).Return(*params, int64(1), nil)
+	stateStoreMock.On("LoadConsensusParamsAndChangeHeight", int64(4)).Return(updated, int64(3), nil)
+	env := newTestEnvironment(blockStoreMock, stateStoreMock)
+
+	res, err := env.ConsensusParamsDiff(nil, 2, 4)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), res.FromLastHeightChanged)
+	require.Equal(t, int64(3), res.ToLastHeightChanged)
+	require.Len(t, res.Changes, 2)
+	require.Equal(t, "block.max_bytes", res.Changes[0].Param)
+	require.JSONEq(t, `"22020096"`, string(res.Changes[0].From))
+	require.JSONEq(t, `"1024"`, string(res.Changes[0].To))
+	require.Equal(t, "validator.pub_key_types", res.Changes[1].Param)
+	require.JSONEq(t, `["secp256k1"]`, string(res.Changes[1].To))
+
+	res, err = env.ConsensusParamsDiff(nil, 2, 2)
+	require.NoError(t, err)
+	require.Empty(t, res.Changes)
+
+	_, err = env.ConsensusParamsDiff(nil, 2, 7)
+	require.Error(t, err)
+}
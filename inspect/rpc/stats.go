@@ -0,0 +1,131 @@
+package rpc
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// ResultBlockIntervalStats is the result of the block_interval_stats route.
+// The durations are in nanoseconds, and are 0 if Count is 0.
+type ResultBlockIntervalStats struct {
+	MinHeight int64 `json:"min_height"`
+	MaxHeight int64 `json:"max_height"`
+	// Count is the number of intervals between the times of the headers of
+	// consecutive blocks in the range.
+	Count int           `json:"count"`
+	Min   time.Duration `json:"min"`
+	Max   time.Duration `json:"max"`
+	Avg   time.Duration `json:"avg"`
+	P99   time.Duration `json:"p99"`
+}
+
+// BlockIntervalStats returns statistics on the intervals between the times of
+// the headers of consecutive blocks, for minHeight <= height <= maxHeight. The
+// range is resolved as in the blockchain route. Intervals involving a block
+// missing from the block store are skipped.
+func (env *environment) BlockIntervalStats(
+	_ *rpctypes.Context,
+	minHeight, maxHeight int64,
+) (*ResultBlockIntervalStats, error) {
+	minHeight, maxHeight, err := env.heightRange(minHeight, maxHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	intervals := make([]time.Duration, 0, maxHeight-minHeight)
+	var prev *time.Time
+	for height := minHeight; height <= maxHeight; height++ {
+		blockMeta := env.BlockStore.LoadBlockMeta(height)
+		if blockMeta == nil {
+			prev = nil
+			continue
+		}
+		if prev != nil {
+			intervals = append(intervals, blockMeta.Header.Time.Sub(*prev))
+		}
+		prev = &blockMeta.Header.Time
+	}
+
+	res := &ResultBlockIntervalStats{
+		MinHeight: minHeight,
+		MaxHeight: maxHeight,
+		Count:     len(intervals),
+	}
+	if len(intervals) == 0 {
+		return res, nil
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	var sum time.Duration
+	for _, interval := range intervals {
+		sum += interval
+	}
+	res.Min = intervals[0]
+	res.Max = intervals[len(intervals)-1]
+	res.Avg = sum / time.Duration(len(intervals))
+	res.P99 = percentile(intervals, 99)
+	return res, nil
+}
+
+// percentile returns the p-th percentile of the sorted values, using the
+// nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// BlockTime is the time of the header of the block at a height.
+type BlockTime struct {
+	Height int64     `json:"height"`
+	Time   time.Time `json:"time"`
+}
+
+// ResultBlockTimeContext is the result of the block_time_context route.
+type ResultBlockTimeContext struct {
+	BlockTime
+	// Previous and Next are the times of the blocks before and after the
+	// block, or null if the block is at the base or the tip of the block
+	// store, or if they are missing from it.
+	Previous   *BlockTime `json:"previous"`
+	Next       *BlockTime `json:"next"`
+	LastHeight int64      `json:"last_height"`
+}
+
+// BlockTimeContext returns the time of the block at the given height, or of
+// the latest block if no height is given, along with the times of the blocks
+// before and after it, to locate events timed outside of the chain between
+// heights. Only the block metas are read.
+func (env *environment) BlockTimeContext(_ *rpctypes.Context, heightPtr *int64) (*ResultBlockTimeContext, error) {
+	base, latest := env.BlockStore.Base(), env.BlockStore.Height()
+	height, err := env.getHeight(latest, heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	blockTime := env.loadBlockTime(height)
+	if blockTime == nil {
+		return nil, fmt.Errorf("block meta not found for height %d", height)
+	}
+	res := &ResultBlockTimeContext{BlockTime: *blockTime, LastHeight: latest}
+	if height > base {
+		res.Previous = env.loadBlockTime(height - 1)
+	}
+	if height < latest {
+		res.Next = env.loadBlockTime(height + 1)
+	}
+	return res, nil
+}
+
+// loadBlockTime returns the time of the block at height, or nil if its meta
+// is missing from the block store.
+func (env *environment) loadBlockTime(height int64) *BlockTime {
+	blockMeta := env.BlockStore.LoadBlockMeta(height)
+	if blockMeta == nil {
+		return nil
+	}
+	return &BlockTime{Height: height, Time: blockMeta.Header.Time}
+}
@@ -0,0 +1,86 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestBlockIntervalStats(t *testing.T) {
+	start := time.Now()
+	// Block 4 is missing, so the intervals are 1s, 3s and 2s.
+	times := map[int64]time.Duration{1: 0, 2: time.Second, 3: 4 * time.Second, 5: 10 * time.Second, 6: 12 * time.Second}
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(6))
+	blockStoreMock.On("LoadBlockMeta", int64(4)).Return(nil)
+	for height, offset := range times {
+		blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{
+			Header: types.Header{Height: height, Time: start.Add(offset)},
+		})
+	}
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{})
+
+	res, err := env.BlockIntervalStats(nil, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, &ResultBlockIntervalStats{
+		MinHeight: 1,
+		MaxHeight: 6,
+		Count:     3,
+		Min:       time.Second,
+		Max:       3 * time.Second,
+		Avg:       2 * time.Second,
+		P99:       3 * time.Second,
+	}, res)
+
+	res, err = env.BlockIntervalStats(nil, 6, 6)
+	require.NoError(t, err)
+	require.Zero(t, res.Count)
+}
+
+func TestBlockTimeContext(t *testing.T) {
+	start := time.Now()
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(2))
+	blockStoreMock.On("Height").Return(int64(6))
+	blockStoreMock.On("LoadBlockMeta", int64(4)).Return(nil)
+	for _, height := range []int64{2, 3, 5, 6} {
+		blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{
+			Header: types.Header{Height: height, Time: start.Add(time.Duration(height) * time.Second)},
+		})
+	}
+	env := newTestEnvironment(blockStoreMock, &statemocks.Store{})
+	blockTime := func(height int64) *BlockTime {
+		return &BlockTime{Height: height, Time: start.Add(time.Duration(height) * time.Second)}
+	}
+
+	height := int64(3)
+	res, err := env.BlockTimeContext(nil, &height)
+	require.NoError(t, err)
+	require.Equal(t, &ResultBlockTimeContext{
+		BlockTime:  *blockTime(3),
+		Previous:   blockTime(2),
+		LastHeight: 6,
+	}, res)
+
+	// The base has no previous block, and the tip no next block.
+	height = 2
+	res, err = env.BlockTimeContext(nil, &height)
+	require.NoError(t, err)
+	require.Nil(t, res.Previous)
+	require.Equal(t, blockTime(3), res.Next)
+
+	res, err = env.BlockTimeContext(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, *blockTime(6), res.BlockTime)
+	require.Equal(t, blockTime(5), res.Previous)
+	require.Nil(t, res.Next)
+
+	height = 4
+	_, err = env.BlockTimeContext(nil, &height)
+	require.Error(t, err)
+}
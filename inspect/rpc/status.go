@@ -0,0 +1,95 @@
+package rpc
+
+import (
+	"fmt"
+	"time"
+
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+)
+
+// ResultStatus is the Inspector counterpart of the node's status result. It
+// only reports information that can be derived from the data stores.
+type ResultStatus struct {
+	SyncInfo ctypes.SyncInfo `json:"sync_info"`
+	// Stale is true if a maximum block age is configured and the latest
+	// stored block is older than it.
+	Stale bool `json:"stale"`
+}
+
+// MaxBlockAge sets the maximum age of the latest stored block. If the latest
+// block is older than maxAge, the status route reports the store as stale.
+// A value of 0 disables the check.
+func MaxBlockAge(maxAge time.Duration) RoutesOption {
+	return func(env *environment) {
+		env.maxBlockAge = maxAge
+	}
+}
+
+// Status returns the latest and earliest blocks available in the block store
+// and whether the latest block is older than the configured maximum age.
+func (env *environment) Status(*rpctypes.Context) (*ResultStatus, error) {
+	var syncInfo ctypes.SyncInfo
+
+	if earliestBlockMeta := env.BlockStore.LoadBaseMeta(); earliestBlockMeta != nil {
+		syncInfo.EarliestBlockHeight = earliestBlockMeta.Header.Height
+		syncInfo.EarliestAppHash = earliestBlockMeta.Header.AppHash
+		syncInfo.EarliestBlockHash = earliestBlockMeta.BlockID.Hash
+		syncInfo.EarliestBlockTime = earliestBlockMeta.Header.Time
+	}
+
+	var latestBlockMeta *types.BlockMeta
+	if latestHeight := env.BlockStore.Height(); latestHeight != 0 {
+		latestBlockMeta = env.BlockStore.LoadBlockMeta(latestHeight)
+		syncInfo.LatestBlockHeight = latestHeight
+	}
+	if latestBlockMeta != nil {
+		syncInfo.LatestBlockHash = latestBlockMeta.BlockID.Hash
+		syncInfo.LatestAppHash = latestBlockMeta.Header.AppHash
+		syncInfo.LatestBlockTime = latestBlockMeta.Header.Time
+	}
+
+	stale := env.maxBlockAge > 0 && latestBlockMeta != nil &&
+		time.Since(latestBlockMeta.Header.Time) > env.maxBlockAge
+
+	return &ResultStatus{
+		SyncInfo: syncInfo,
+		Stale:    stale,
+	}, nil
+}
+
+// ErrStaleBlockStore is returned by CheckBlockAge when the latest stored block
+// is older than the maximum allowed age.
+type ErrStaleBlockStore struct {
+	Height int64
+	Age    time.Duration
+	MaxAge time.Duration
+}
+
+func (e ErrStaleBlockStore) Error() string {
+	return fmt.Sprintf("latest block %d is %v old, which exceeds the maximum block age of %v",
+		e.Height, e.Age.Round(time.Second), e.MaxAge)
+}
+
+// CheckBlockAge returns an ErrStaleBlockStore if the latest block in bs is
+// older than maxAge. An empty block store and a maxAge of 0 are never
+// considered stale.
+func CheckBlockAge(bs state.BlockStore, maxAge time.Duration) error {
+	if maxAge <= 0 {
+		return nil
+	}
+	height := bs.Height()
+	if height == 0 {
+		return nil
+	}
+	blockMeta := bs.LoadBlockMeta(height)
+	if blockMeta == nil {
+		return nil
+	}
+	if age := time.Since(blockMeta.Header.Time); age > maxAge {
+		return ErrStaleBlockStore{Height: height, Age: age, MaxAge: maxAge}
+	}
+	return nil
+}
@@ -0,0 +1,384 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/cometbft/cometbft/libs/log"
+	cmtquery "github.com/cometbft/cometbft/libs/pubsub/query"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/state/txindex"
+	"github.com/cometbft/cometbft/state/txindex/kv"
+	"github.com/cometbft/cometbft/types"
+)
+
+const (
+	// NDJSONMediaType is the media type of the responses of the streaming
+	// search route: one JSON value per line.
+	NDJSONMediaType = "application/x-ndjson"
+
+	// streamFlushInterval is the number of results written by the streaming
+	// search route between flushes.
+	streamFlushInterval = 100
+
+	// maxStreamQueryLength is the maximum length of the queries of the
+	// streaming search route, as for tx_search.
+	maxStreamQueryLength = 512
+)
+
+// TxSearchStream is the streaming search route, which serves the transactions
+// matching a query under /tx_search_stream, without the pagination of
+// tx_search. It is set on the routes with WithTxSearchStream, to search their
+// indexers, and served by the handler with StreamTxSearch.
+//
+// The route takes the query and order_by parameters of tx_search in its URL,
+// such as /tx_search_stream?query="tx.height>5"&order_by=desc, and responds
+// with NDJSONMediaType: every line is a transaction encoded as in the
+// responses of tx_search, without proof. Results are written as they are
+// encoded and flushed periodically, and the route stops when the client goes
+// away. A transaction failing to load or encode once the response is started
+// ends it with a last line holding the error, as {"error":{"code":-32603,
+// "message":"Internal error","data":"..."}}, so that the clients can tell an
+// export cut short from a complete one.
+//
+// The results are not written before the search completes: as tx_search,
+// the route first matches the transactions on the whole index. With the KV
+// indexer, only the positions of the transactions are then held in memory,
+// and the transactions are loaded one at a time as they are written, rather
+// than all at once. The route thus bounds the memory of wide searches, not
+// their latency.
+//
+// The searches go through the same guards as those of tx_search: the allowed
+// query operators, MaxQueryComplexity, MaxConcurrentSearches, the circuit
+// breaker of IndexerCircuitBreaker and the EventSinks. The route is not served
+// if tx_search is not, such as when it is disabled.
+type TxSearchStream struct {
+	env *environment
+}
+
+// WithTxSearchStream sets the routes to serve the searches of stream on their
+// indexers.
+func WithTxSearchStream(stream *TxSearchStream) RoutesOption {
+	return func(env *environment) {
+		stream.env = env
+	}
+}
+
+// StreamTxSearch serves stream under /tx_search_stream. The routes given to
+// Handler must have been created with WithTxSearchStream(stream).
+func StreamTxSearch(stream *TxSearchStream) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.txSearchStream = stream
+	}
+}
+
+// StreamSearchTimeout sets the maximum duration of the searches of the
+// streaming search route, which is not limited by IndexerTimeout. Searches
+// taking longer are canceled and answered with a 504 status. The time spent
+// writing the results is not limited. A value of 0 disables the timeout.
+func StreamSearchTimeout(timeout time.Duration) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.streamSearchTimeout = timeout
+	}
+}
+
+// StreamSearchMaxResults sets the maximum number of transactions returned by
+// the streaming search route. The searches matching more transactions are
+// answered with a 422 status stating their number, rather than with a
+// truncated stream which could pass for a complete export; the clients then
+// narrow their query, for instance by height. By default the number of
+// transactions is not limited.
+func StreamSearchMaxResults(n int) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.streamSearchMaxResults = n
+	}
+}
+
+// txSearchStreamHandler serves the streaming search route on the indexers of
+// env, through its search middlewares, canceling the searches after timeout
+// and rejecting those with more than maxResults results, if positive.
+func txSearchStreamHandler(env *environment, timeout time.Duration, maxResults int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Accept the query quoted, as in the URI requests of tx_search, or not.
+		query := r.URL.Query().Get("query")
+		if len(query) >= 2 && strings.HasPrefix(query, `"`) && strings.HasSuffix(query, `"`) {
+			query = query[1 : len(query)-1]
+		}
+		if len(query) > maxStreamQueryLength {
+			http.Error(w, "maximum query length exceeded", http.StatusBadRequest)
+			return
+		}
+		q, err := cmtquery.New(query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		orderBy := strings.Trim(r.URL.Query().Get("order_by"), `"`)
+		if orderBy != "" && orderBy != "asc" && orderBy != "desc" {
+			http.Error(w, "expected order_by to be either `asc` or `desc` or empty", http.StatusBadRequest)
+			return
+		}
+
+		stream := routeHandler(func(*rpctypes.Context, []reflect.Value) (interface{}, error) {
+			return nil, streamTxs(w, r, env.TxIndexer, q, orderBy == "desc", timeout, maxResults, env.Logger)
+		})
+		for i := len(env.searchMiddlewares) - 1; i >= 0; i-- {
+			stream = env.searchMiddlewares[i]("tx_search", stream)
+		}
+		if _, err := stream(&rpctypes.Context{HTTPReq: r}, []reflect.Value{reflect.ValueOf(query)}); err != nil {
+			writeStreamError(w, err)
+		}
+	})
+}
+
+// streamTxs writes the transactions matching q on txidx to w. It returns the
+// errors of the search, before the response is written. The errors loading
+// or encoding the transactions once the response is started end it with a
+// streamErrorLine, and the errors writing it are logged.
+func streamTxs(
+	w http.ResponseWriter,
+	r *http.Request,
+	txidx txindex.TxIndexer,
+	q *cmtquery.Query,
+	desc bool,
+	timeout time.Duration,
+	maxResults int,
+	logger log.Logger,
+) error {
+	// The search stops when the request context is canceled.
+	ctx := r.Context()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	it, err := searchTxs(ctx, txidx, q, desc)
+	if err == nil {
+		// Indexers such as the KV one return the results found so far
+		// rather than an error once ctx is done.
+		err = ctx.Err()
+	}
+	if err != nil {
+		if r.Context().Err() != nil {
+			return nil
+		}
+		if timeout > 0 && errors.Is(err, context.DeadlineExceeded) {
+			return searchTimeoutError(timeout)
+		}
+		return err
+	}
+	if maxResults > 0 && it.Len() > maxResults {
+		http.Error(w, fmt.Sprintf("query matches %d transactions, more than the maximum of %d",
+			it.Len(), maxResults), http.StatusUnprocessableEntity)
+		return nil
+	}
+
+	nw := newNDJSONWriter(w)
+	for it.Next() {
+		if r.Context().Err() != nil {
+			return nil
+		}
+		res := it.Result()
+		line, err := cmtjson.Marshal(&ctypes.ResultTx{
+			Hash:     types.Tx(res.Tx).Hash(),
+			Height:   res.Height,
+			Index:    res.Index,
+			TxResult: res.Result,
+			Tx:       res.Tx,
+		})
+		if err != nil {
+			nw.close(fmt.Errorf("encoding transaction %X: %w", types.Tx(res.Tx).Hash(), err), logger)
+			return nil
+		}
+		if err := nw.writeLine(line); err != nil {
+			logger.Error("Failed to write streaming search response", "err", err)
+			return nil
+		}
+	}
+	nw.close(it.Err(), logger)
+	return nil
+}
+
+// ndjsonWriter writes the lines of the responses of the streaming routes,
+// flushing them to the connection every streamFlushInterval lines.
+type ndjsonWriter struct {
+	bw      *bufio.Writer
+	flusher http.Flusher
+	lines   int
+}
+
+// newNDJSONWriter starts an NDJSONMediaType response on w.
+func newNDJSONWriter(w http.ResponseWriter) *ndjsonWriter {
+	w.Header().Set("Content-Type", NDJSONMediaType)
+	flusher, _ := w.(http.Flusher)
+	return &ndjsonWriter{bw: bufio.NewWriter(w), flusher: flusher}
+}
+
+// writeLine writes line, followed by a newline.
+func (w *ndjsonWriter) writeLine(line []byte) error {
+	if _, err := w.bw.Write(line); err != nil {
+		return err
+	}
+	if err := w.bw.WriteByte('\n'); err != nil {
+		return err
+	}
+	w.lines++
+	if w.lines%streamFlushInterval == 0 {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *ndjsonWriter) flush() error {
+	if err := w.bw.Flush(); err != nil {
+		return err
+	}
+	if w.flusher != nil {
+		w.flusher.Flush()
+	}
+	return nil
+}
+
+// streamErrorLine is the last line of the responses of the streaming routes
+// which failed once their response was started. Its error is encoded as the
+// errors of the JSON-RPC responses, so that clients can tell a stream cut
+// short from a complete one.
+type streamErrorLine struct {
+	Error *rpctypes.RPCError `json:"error"`
+}
+
+// close ends the response, with a streamErrorLine holding err if not nil,
+// and flushes it. The errors writing the response are logged.
+func (w *ndjsonWriter) close(err error, logger log.Logger) {
+	if err != nil {
+		line, mErr := json.Marshal(streamErrorLine{Error: rpctypes.RPCErrorResponseFromError(nil, err).Error})
+		if mErr != nil {
+			logger.Error("Failed to encode streaming response error", "err", mErr)
+			return
+		}
+		if wErr := w.writeLine(line); wErr != nil {
+			logger.Error("Failed to write streaming response", "err", wErr)
+			return
+		}
+	}
+	if err := w.flush(); err != nil {
+		logger.Error("Failed to write streaming response", "err", err)
+	}
+}
+
+// writeStreamError answers a streaming search which failed with err, with the
+// status matching the code of err if it is an *rpctypes.RPCError.
+func writeStreamError(w http.ResponseWriter, err error) {
+	status, msg := http.StatusInternalServerError, err.Error()
+	var rpcErr *rpctypes.RPCError
+	if errors.As(err, &rpcErr) {
+		msg = rpcErr.Data
+		if msg == "" {
+			msg = rpcErr.Message
+		}
+		switch rpcErr.Code {
+		case codeInvalidParams:
+			status = http.StatusBadRequest
+		case codeServiceUnavailable:
+			status = http.StatusServiceUnavailable
+		case codeSearchTimeout:
+			status = http.StatusGatewayTimeout
+		}
+	}
+	http.Error(w, msg, status)
+}
+
+// txIterator iterates over the results of a transaction search, ordered by
+// height and index.
+type txIterator interface {
+	// Len returns the number of results.
+	Len() int
+	// Next advances to the next result, and returns false at the end of the
+	// results or on error.
+	Next() bool
+	Result() *abci.TxResult
+	Err() error
+}
+
+// iteratingTxIndexer is implemented by the transaction indexers iterating
+// over the results of their searches, such as the KV indexer.
+type iteratingTxIndexer interface {
+	SearchIterator(ctx context.Context, q *cmtquery.Query, desc bool) (*kv.TxIterator, error)
+}
+
+// txSearcher is implemented by the wrappers of the transaction indexers of
+// the environment, to search the indexers they wrap with searchTxs.
+type txSearcher interface {
+	searchTxs(ctx context.Context, q *cmtquery.Query, desc bool) (txIterator, error)
+}
+
+// searchTxs returns an iterator over the transactions matching q on txidx,
+// in descending order if desc is set. The transactions are loaded as the
+// iterator advances if txidx iterates over its results, or all at once
+// otherwise.
+func searchTxs(ctx context.Context, txidx txindex.TxIndexer, q *cmtquery.Query, desc bool) (txIterator, error) {
+	switch idx := txidx.(type) {
+	case txSearcher:
+		return idx.searchTxs(ctx, q, desc)
+	case iteratingTxIndexer:
+		it, err := idx.SearchIterator(ctx, q, desc)
+		if err != nil {
+			return nil, err
+		}
+		return it, nil
+	}
+	results, err := txidx.Search(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if desc {
+			i, j = j, i
+		}
+		if results[i].Height == results[j].Height {
+			return results[i].Index < results[j].Index
+		}
+		return results[i].Height < results[j].Height
+	})
+	return &sliceTxIterator{results: results}, nil
+}
+
+// sliceTxIterator iterates over the results of a search loaded at once.
+type sliceTxIterator struct {
+	results []*abci.TxResult
+	next    int
+	result  *abci.TxResult
+}
+
+func (it *sliceTxIterator) Len() int { return len(it.results) }
+
+func (it *sliceTxIterator) Next() bool {
+	if it.next >= len(it.results) {
+		it.result = nil
+		return false
+	}
+	it.result = it.results[it.next]
+	it.next++
+	return true
+}
+
+func (it *sliceTxIterator) Result() *abci.TxResult { return it.result }
+
+func (it *sliceTxIterator) Err() error { return nil }
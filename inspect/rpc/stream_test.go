@@ -0,0 +1,237 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	dbm "github.com/cometbft/cometbft-db"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/config"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/libs/pubsub/query"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	indexermocks "github.com/cometbft/cometbft/state/indexer/mocks"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/state/txindex"
+	"github.com/cometbft/cometbft/state/txindex/kv"
+	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
+)
+
+// newStreamHandler returns the handler serving the streaming search route on
+// txidx, with the routes created with options.
+func newStreamHandler(
+	cfg *config.RPCConfig,
+	txidx txindex.TxIndexer,
+	handlerOptions []HandlerOption,
+	options ...RoutesOption,
+) http.Handler {
+	stream := &TxSearchStream{}
+	logger := log.NewNopLogger()
+	routes := Routes(*cfg, &statemocks.Store{}, &statemocks.BlockStore{}, txidx, &indexermocks.BlockIndexer{},
+		logger, append(options, WithTxSearchStream(stream))...)
+	return Handler(cfg, routes, logger, append(handlerOptions, StreamTxSearch(stream))...)
+}
+
+func TestStreamTxSearch(t *testing.T) {
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	txIndexerMock.On("Search", mock.Anything, mock.Anything).Return([]*abcitypes.TxResult{
+		{Height: 2, Index: 0, Tx: []byte("c")},
+		{Height: 1, Index: 1, Tx: []byte("b")},
+		{Height: 1, Index: 0, Tx: []byte("a")},
+	}, nil)
+	cfg := config.TestRPCConfig()
+	cfg.AllowedQueryOperators = []string{"="}
+	h := newStreamHandler(cfg, txIndexerMock, nil)
+
+	search := func(query, orderBy string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		target := "/tx_search_stream?" + url.Values{"query": {query}, "order_by": {orderBy}}.Encode()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	rec := search(`"tx.height=1"`, "desc")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, NDJSONMediaType, rec.Header().Get("Content-Type"))
+	var txs []string
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var res ctypes.ResultTx
+		require.NoError(t, cmtjson.Unmarshal(scanner.Bytes(), &res))
+		txs = append(txs, string(res.Tx))
+	}
+	require.Equal(t, []string{"c", "b", "a"}, txs)
+
+	require.Equal(t, http.StatusBadRequest, search("tx.height>1", "").Code)
+	require.Equal(t, http.StatusBadRequest, search("tx.height=1", "random").Code)
+	require.Equal(t, http.StatusBadRequest, search("tx.height=", "").Code)
+}
+
+func TestStreamTxSearchTimeout(t *testing.T) {
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	txIndexerMock.On("Search", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, _ *query.Query) []*abcitypes.TxResult {
+			<-ctx.Done()
+			return nil
+		},
+		func(ctx context.Context, _ *query.Query) error {
+			return ctx.Err()
+		})
+	h := newStreamHandler(config.TestRPCConfig(), txIndexerMock,
+		[]HandlerOption{StreamSearchTimeout(10 * time.Millisecond)}, IndexerTimeout(time.Millisecond))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tx_search_stream?query=tx.height%3D1", nil))
+	require.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	require.Contains(t, rec.Body.String(), searchTimeoutMessage(10*time.Millisecond))
+}
+
+// slowDB is a database whose reads take a millisecond.
+type slowDB struct {
+	dbm.DB
+}
+
+func (db slowDB) Get(key []byte) ([]byte, error) {
+	time.Sleep(time.Millisecond)
+	return db.DB.Get(key)
+}
+
+func TestStreamTxSearchTimeoutKV(t *testing.T) {
+	db := dbm.NewMemDB()
+	txIndexer := kv.NewTxIndex(db)
+	for i := 0; i < 50; i++ {
+		require.NoError(t, txIndexer.Index(&abcitypes.TxResult{Height: 1, Index: uint32(i), Tx: []byte{byte(i)}}))
+	}
+	h := newStreamHandler(config.TestRPCConfig(), kv.NewTxIndex(slowDB{db}),
+		[]HandlerOption{StreamSearchTimeout(10 * time.Millisecond)})
+
+	// The search is reported as timed out rather than answered with the
+	// transactions found before the timeout.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tx_search_stream?query=tx.height%3D1", nil))
+	require.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	require.Contains(t, rec.Body.String(), searchTimeoutMessage(10*time.Millisecond))
+}
+
+// corruptDB is a database whose reads return corrupted values after the
+// first n.
+type corruptDB struct {
+	dbm.DB
+	n int
+}
+
+func (db *corruptDB) Get(key []byte) ([]byte, error) {
+	if db.n == 0 {
+		return []byte{0xff}, nil
+	}
+	db.n--
+	return db.DB.Get(key)
+}
+
+func TestStreamTxSearchLoadError(t *testing.T) {
+	db := dbm.NewMemDB()
+	txIndexer := kv.NewTxIndex(db)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, txIndexer.Index(&abcitypes.TxResult{Height: 1, Index: uint32(i), Tx: []byte{byte(i)}}))
+	}
+	// The search reads the three transactions, and the stream fails to
+	// load the second one.
+	h := newStreamHandler(config.TestRPCConfig(), kv.NewTxIndex(&corruptDB{DB: db, n: 4}), nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tx_search_stream?query=tx.height%3D1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+
+	// The stream ends with the error.
+	var last streamErrorLine
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &last))
+	require.NotNil(t, last.Error)
+	require.Equal(t, -32603, last.Error.Code)
+	require.Contains(t, last.Error.Data, "error reading TxResult")
+}
+
+func TestStreamTxSearchMaxResults(t *testing.T) {
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	txIndexerMock.On("Search", mock.Anything, mock.Anything).Return([]*abcitypes.TxResult{
+		{Height: 1, Index: 0, Tx: []byte("a")},
+		{Height: 1, Index: 1, Tx: []byte("b")},
+		{Height: 2, Index: 0, Tx: []byte("c")},
+	}, nil)
+	search := func(maxResults int) *httptest.ResponseRecorder {
+		h := newStreamHandler(config.TestRPCConfig(), txIndexerMock,
+			[]HandlerOption{StreamSearchMaxResults(maxResults)})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tx_search_stream?query=tx.height%3E0", nil))
+		return rec
+	}
+
+	rec := search(3)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, 3, bytes.Count(rec.Body.Bytes(), []byte("\n")))
+
+	rec = search(2)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	require.Contains(t, rec.Body.String(), "query matches 3 transactions, more than the maximum of 2")
+}
+
+func TestStreamTxSearchIterator(t *testing.T) {
+	txIndexer := kv.NewTxIndex(dbm.NewMemDB())
+	for _, tx := range []*abcitypes.TxResult{
+		{Height: 2, Index: 0, Tx: []byte("c")},
+		{Height: 1, Index: 1, Tx: []byte("b")},
+		{Height: 1, Index: 0, Tx: []byte("a")},
+	} {
+		require.NoError(t, txIndexer.Index(tx))
+	}
+	search := func(h http.Handler, query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		target := "/tx_search_stream?" + url.Values{"query": {query}, "order_by": {"desc"}}.Encode()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	// The transactions are loaded by the iterator of the indexer of the
+	// event sink serving the search.
+	h := newStreamHandler(config.TestRPCConfig(), txIndexer, nil, EventSinks(EventSink{
+		Name:         "kv",
+		TxIndexer:    txIndexer,
+		BlockIndexer: &indexermocks.BlockIndexer{},
+	}))
+	rec := search(h, "tx.height>0")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "kv", rec.Header().Get(EventSinkHeader))
+	var txs []string
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var res ctypes.ResultTx
+		require.NoError(t, cmtjson.Unmarshal(scanner.Bytes(), &res))
+		txs = append(txs, string(res.Tx))
+	}
+	require.Equal(t, []string{"c", "b", "a"}, txs)
+
+	// The searches go through the guards of tx_search.
+	h = newStreamHandler(config.TestRPCConfig(), txIndexer, nil, MaxQueryComplexity(1))
+	rec = search(h, "tx.height>0 AND tx.height<5")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "query complexity score")
+
+	cfg := config.TestRPCConfig()
+	cfg.DisabledRPCMethods = []string{"tx_search"}
+	h = newStreamHandler(cfg, txIndexer, nil)
+	require.Equal(t, http.StatusNotFound, search(h, "tx.height>0").Code)
+}
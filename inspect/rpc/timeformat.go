@@ -0,0 +1,73 @@
+package rpc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+)
+
+// The formats of the timestamps of the responses, set with the
+// timestamp_format option of the RPC configuration.
+const (
+	// TimestampFormatRFC3339 encodes the timestamps as RFC 3339 strings with
+	// nanoseconds, such as "2023-08-01T12:00:00.123456789Z". It is the
+	// default format, that of the node.
+	TimestampFormatRFC3339 = "rfc3339"
+	// TimestampFormatProtobuf encodes the timestamps as the objects of the
+	// fields of the google.protobuf.Timestamp messages, such as
+	// {"seconds":"1690891200","nanos":123456789}, with the seconds quoted as
+	// the other 64-bit integers of the responses.
+	TimestampFormatProtobuf = "protobuf"
+)
+
+// ValidateTimestampFormat returns an error if format is not a timestamp
+// format of the responses. An empty format is the default format.
+func ValidateTimestampFormat(format string) error {
+	switch format {
+	case "", TimestampFormatRFC3339, TimestampFormatProtobuf:
+		return nil
+	default:
+		return fmt.Errorf("unknown timestamp format %q, expected %q or %q",
+			format, TimestampFormatRFC3339, TimestampFormatProtobuf)
+	}
+}
+
+// jsonMarshaler encodes the results of the routes to JSON.
+type jsonMarshaler func(v interface{}) ([]byte, error)
+
+// timestampMarshaler returns the marshaler encoding the timestamps in format.
+func timestampMarshaler(format string) jsonMarshaler {
+	if format == TimestampFormatProtobuf {
+		return marshalProtobufTimestamps
+	}
+	return cmtjson.Marshal
+}
+
+// marshalProtobufTimestamps encodes v as cmtjson.Marshal does, except for the
+// time.Time values, which are encoded in the protobuf format.
+func marshalProtobufTimestamps(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := newJSONEncoder(&buf, true).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newJSONEncoder returns the cmtjson encoder writing to w, which encodes the
+// time.Time values in the protobuf format if protobufTimestamps is set.
+func newJSONEncoder(w io.Writer, protobufTimestamps bool) *cmtjson.Encoder {
+	enc := cmtjson.NewEncoder(w)
+	if protobufTimestamps {
+		enc.SetTimeMarshaler(marshalProtobufTimestamp)
+	}
+	return enc
+}
+
+// marshalProtobufTimestamp encodes t as the google.protobuf.Timestamp message
+// is, with the seconds quoted as the other 64-bit integers.
+func marshalProtobufTimestamp(t time.Time) ([]byte, error) {
+	return []byte(fmt.Sprintf(`{"seconds":"%d","nanos":%d}`, t.Unix(), t.Nanosecond())), nil
+}
@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/cometbft/cometbft/libs/log"
+	indexermocks "github.com/cometbft/cometbft/state/indexer/mocks"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestMarshalProtobufTimestamps(t *testing.T) {
+	ts := time.Date(2023, 8, 1, 12, 0, 0, 123456789, time.UTC)
+	type result struct {
+		Height    int64                `json:"height"`
+		Time      time.Time            `json:"time"`
+		Missing   time.Time            `json:"missing,omitempty"`
+		Pointer   *time.Time           `json:"pointer"`
+		Times     map[string]time.Time `json:"times"`
+		Evidence  []types.Evidence     `json:"evidence"`
+		PubKey    interface{}          `json:"pub_key"`
+		Attribute string               `json:"attribute"`
+		Raw       json.RawMessage      `json:"raw"`
+		hidden    time.Time
+	}
+	pubKey := ed25519.GenPrivKey().PubKey()
+	bz, err := marshalProtobufTimestamps(&result{
+		Height:    2,
+		Time:      ts,
+		Times:     map[string]time.Time{"b": time.Unix(2, 0), "a": time.Unix(1, 0)},
+		Evidence:  []types.Evidence{&types.DuplicateVoteEvidence{TotalVotingPower: 3, Timestamp: time.Unix(3, 0)}},
+		PubKey:    pubKey,
+		Attribute: "2023-08-01T12:00:00Z",
+		Raw:       json.RawMessage(`{"time":"2023-08-01T12:00:00Z"}`),
+		hidden:    ts,
+	})
+	require.NoError(t, err)
+
+	// Only the time.Time values are encoded differently from cmtjson.
+	pubKeyJSON, err := cmtjson.Marshal(struct{ K interface{} }{pubKey})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"height":"2","time":{"seconds":"1690891200","nanos":123456789},"pointer":null,`+
+		`"times":{"a":{"seconds":"1","nanos":0},"b":{"seconds":"2","nanos":0}},`+
+		`"evidence":[{"type":"tendermint/DuplicateVoteEvidence","value":{"vote_a":null,"vote_b":null,`+
+		`"TotalVotingPower":"3","ValidatorPower":"0","Timestamp":{"seconds":"3","nanos":0}}}],`+
+		`"pub_key":`+string(pubKeyJSON[len(`{"K":`):len(pubKeyJSON)-1])+`,`+
+		`"attribute":"2023-08-01T12:00:00Z","raw":{"time":"2023-08-01T12:00:00Z"}}`,
+		string(bz))
+
+	// The values without timestamps are encoded by cmtjson.
+	header := &types.Header{Height: 3, ChainID: "test-chain"}
+	bz, err = marshalProtobufTimestamps(&ResultValidatorHashesRange{})
+	require.NoError(t, err)
+	expected, err := cmtjson.Marshal(&ResultValidatorHashesRange{})
+	require.NoError(t, err)
+	require.Equal(t, expected, bz)
+	bz, err = marshalProtobufTimestamps(header)
+	require.NoError(t, err)
+	require.Contains(t, string(bz), `"time":{"seconds":"-62135596800","nanos":0}`)
+
+	_, err = marshalProtobufTimestamps(struct{ V interface{} }{struct{ T time.Time }{}})
+	require.Error(t, err)
+}
+
+func TestTimestampFormat(t *testing.T) {
+	require.NoError(t, ValidateTimestampFormat(""))
+	require.NoError(t, ValidateTimestampFormat(TimestampFormatRFC3339))
+	require.NoError(t, ValidateTimestampFormat(TimestampFormatProtobuf))
+	require.Error(t, ValidateTimestampFormat("unix"))
+
+	blockTime := time.Date(2023, 8, 1, 12, 0, 0, 5, time.UTC)
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(1))
+	blockStoreMock.On("LoadBlockMeta", int64(1)).Return(&types.BlockMeta{
+		Header: types.Header{Height: 1, ChainID: "test-chain", Time: blockTime},
+	})
+	serve := func(format, path string) string {
+		cfg := config.TestRPCConfig()
+		cfg.TimestampFormat = format
+		logger := log.NewNopLogger()
+		routes := Routes(*cfg, &statemocks.Store{}, blockStoreMock,
+			&txindexmocks.TxIndexer{}, &indexermocks.BlockIndexer{}, logger)
+		h := Handler(cfg, routes, logger, StreamBlockMetas(blockStoreMock))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec.Body.String()
+	}
+
+	require.Contains(t, serve("", "/header"), `"time":"2023-08-01T12:00:00.000000005Z"`)
+	require.Contains(t, serve(TimestampFormatRFC3339, "/header"), `"time":"2023-08-01T12:00:00.000000005Z"`)
+	require.Contains(t, serve(TimestampFormatProtobuf, "/header"), `"time":{"seconds":"1690891200","nanos":5}`)
+	require.Contains(t, serve(TimestampFormatProtobuf, `/block_metas?fields="time"`),
+		`"time":{"seconds":"1690891200","nanos":5}`)
+	require.Contains(t, serve(TimestampFormatProtobuf, "/block_metas_stream?fields=time"),
+		`"time":{"seconds":"1690891200","nanos":5}`)
+
+	// The results are encoded with the timestamps in the format over
+	// websocket connections as well.
+	cfg := config.TestRPCConfig()
+	cfg.TimestampFormat = TimestampFormatProtobuf
+	logger := log.NewNopLogger()
+	routes := Routes(*cfg, &statemocks.Store{}, blockStoreMock,
+		&txindexmocks.TxIndexer{}, &indexermocks.BlockIndexer{}, logger)
+	srv := httptest.NewServer(Handler(cfg, routes, logger))
+	defer srv.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/websocket", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage,
+		[]byte(`{"jsonrpc":"2.0","id":1,"method":"header","params":{}}`)))
+	_, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Contains(t, string(msg), `"time":{"seconds":"1690891200","nanos":5}`)
+}
@@ -0,0 +1,74 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/rpc/jsonrpc/server"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// TimestampHeader is the default request header carrying the time at which a
+// request was signed, for RequireTimestamp.
+const TimestampHeader = "X-Inspect-Timestamp"
+
+// RequireTimestamp rejects with a 401 status the requests whose header, or
+// TimestampHeader if empty, does not carry a timestamp within maxSkew of the
+// clock of the server, in either direction. The timestamp is a number of
+// seconds since the Unix epoch.
+//
+// This bounds the window in which a signed request may be replayed; the
+// signature itself, covering the timestamp, is to be checked by an
+// Authenticator. Requests are checked before being authenticated.
+func RequireTimestamp(header string, maxSkew time.Duration) HandlerOption {
+	if header == "" {
+		header = TimestampHeader
+	}
+	return func(opts *handlerOptions) {
+		opts.timestampHeader = header
+		opts.maxTimestampSkew = maxSkew
+	}
+}
+
+// timestampHandler rejects the requests whose timestamp header is missing or
+// more than maxSkew away from now.
+func timestampHandler(
+	h http.Handler,
+	header string,
+	maxSkew time.Duration,
+	now func() time.Time,
+	logger log.Logger,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := checkTimestamp(r.Header.Get(header), maxSkew, now()); err != nil {
+			res := rpctypes.RPCInvalidRequestError(nil, fmt.Errorf("%s: %w", header, err))
+			if wErr := server.WriteRPCResponseHTTPError(w, http.StatusUnauthorized, res); wErr != nil {
+				logger.Error("failed to write response", "err", wErr)
+			}
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func checkTimestamp(value string, maxSkew time.Duration, now time.Time) error {
+	if value == "" {
+		return errors.New("missing timestamp")
+	}
+	secs, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q", value)
+	}
+	skew := time.Unix(secs, 0).Sub(now)
+	switch {
+	case skew < -maxSkew:
+		return fmt.Errorf("stale timestamp, %v behind the server clock", -skew.Truncate(time.Second))
+	case skew > maxSkew:
+		return fmt.Errorf("future timestamp, %v ahead of the server clock", skew.Truncate(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/libs/log"
+)
+
+func TestRequireTimestamp(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	h := timestampHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), TimestampHeader, 30*time.Second, func() time.Time { return now }, log.NewNopLogger())
+
+	testCases := []struct {
+		name      string
+		timestamp string
+		status    int
+	}{
+		{"current", strconv.FormatInt(now.Unix(), 10), http.StatusOK},
+		{"within skew behind", strconv.FormatInt(now.Unix()-30, 10), http.StatusOK},
+		{"within skew ahead", strconv.FormatInt(now.Unix()+30, 10), http.StatusOK},
+		{"stale", strconv.FormatInt(now.Unix()-31, 10), http.StatusUnauthorized},
+		{"future", strconv.FormatInt(now.Unix()+31, 10), http.StatusUnauthorized},
+		{"missing", "", http.StatusUnauthorized},
+		{"invalid", now.Format(time.RFC3339), http.StatusUnauthorized},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/status", nil)
+			if tc.timestamp != "" {
+				req.Header.Set(TimestampHeader, tc.timestamp)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			require.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// handshakeListener is a TLS listener which completes the handshakes of the
+// connections it accepts before returning them, running at most a maximum
+// number of handshakes at once. The other connections wait for a handshake
+// slot, until the handshake timeout elapses: connections which are not
+// established within the timeout of being accepted are closed.
+type handshakeListener struct {
+	net.Listener
+	config  *tls.Config
+	timeout time.Duration
+
+	// slots holds a value for every handshake running.
+	slots chan struct{}
+	conns chan net.Conn
+	errs  chan error
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newHandshakeListener returns a TLS listener on inner running at most
+// maxHandshakes handshakes at once, each within timeout.
+func newHandshakeListener(inner net.Listener, config *tls.Config, maxHandshakes int, timeout time.Duration) net.Listener {
+	l := &handshakeListener{
+		Listener: inner,
+		config:   config,
+		timeout:  timeout,
+		slots:    make(chan struct{}, maxHandshakes),
+		conns:    make(chan net.Conn),
+		errs:     make(chan error),
+		done:     make(chan struct{}),
+	}
+	go l.acceptRoutine()
+	return l
+}
+
+// Accept returns the next connection whose handshake completed.
+func (l *handshakeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case err := <-l.errs:
+		return nil, err
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes the listener. The connections whose handshake is running are
+// closed once it completes.
+func (l *handshakeListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return l.Listener.Close()
+}
+
+// acceptRoutine accepts the connections of the inner listener and starts
+// their handshakes, until the inner listener is closed. Errors are passed on
+// to Accept, whose caller decides whether to accept again.
+func (l *handshakeListener) acceptRoutine() {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			select {
+			case l.errs <- err:
+			case <-l.done:
+				return
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		go l.handshake(conn)
+	}
+}
+
+// handshake waits for a handshake slot and runs the TLS handshake of conn,
+// before handing it to Accept.
+func (l *handshakeListener) handshake(conn net.Conn) {
+	deadline := time.Now().Add(l.timeout)
+	timer := time.NewTimer(l.timeout)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+	case <-timer.C:
+		conn.Close()
+		return
+	case <-l.done:
+		conn.Close()
+		return
+	}
+
+	tlsConn := tls.Server(conn, l.config)
+	err := tlsConn.SetDeadline(deadline)
+	if err == nil {
+		err = tlsConn.Handshake()
+	}
+	<-l.slots
+	if err == nil {
+		err = tlsConn.SetDeadline(time.Time{})
+	}
+	if err != nil {
+		tlsConn.Close()
+		return
+	}
+
+	select {
+	case l.conns <- tlsConn:
+	case <-l.done:
+		tlsConn.Close()
+	}
+}
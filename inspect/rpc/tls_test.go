@@ -0,0 +1,89 @@
+package rpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandshakeListener(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	config := &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	l := newHandshakeListener(inner, config, 1, 10*time.Second)
+	defer l.Close()
+	accepted := make(chan net.Conn)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				close(accepted)
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	// A client which never sends its hello holds the only handshake slot.
+	stalled, err := net.Dial("tcp", inner.Addr().String())
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	dialed := make(chan error, 1)
+	go func() {
+		conn, err := tls.Dial("tcp", inner.Addr().String(), &tls.Config{InsecureSkipVerify: true}) //nolint: gosec
+		if err == nil {
+			conn.Close()
+		}
+		dialed <- err
+	}()
+	select {
+	case <-accepted:
+		t.Fatal("connection accepted while the handshake slot is held")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Releasing the slot lets the waiting handshake run.
+	stalled.Close()
+	select {
+	case conn := <-accepted:
+		require.IsType(t, &tls.Conn{}, conn)
+		require.True(t, conn.(*tls.Conn).ConnectionState().HandshakeComplete)
+		conn.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not accepted")
+	}
+	require.NoError(t, <-dialed)
+
+	l.Close()
+	_, ok := <-accepted
+	require.False(t, ok)
+}
+
+func TestMaxTLSHandshakes(t *testing.T) {
+	require.Equal(t, runtime.NumCPU(), (&Server{}).maxTLSHandshakes())
+	require.Equal(t, 3, (&Server{MaxTLSHandshakes: 3}).maxTLSHandshakes())
+	require.Negative(t, (&Server{MaxTLSHandshakes: -1}).maxTLSHandshakes())
+}
@@ -0,0 +1,153 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// The trailers sent by the handler with StatsTrailers.
+const (
+	// ReadTimeTrailer is the time spent in the routes serving the request,
+	// reading and decoding the data from the stores, in seconds.
+	ReadTimeTrailer = "X-Inspect-Read-Time"
+	// ResultCountTrailer is the number of items returned by the routes: the
+	// length of the list returned by the routes returning a list, and 1 for
+	// the others.
+	ResultCountTrailer = "X-Inspect-Result-Count"
+	// BytesTrailer is the number of bytes of the response body.
+	BytesTrailer = "X-Inspect-Bytes"
+)
+
+// StatsTrailers sends the cost of serving a request in the ReadTimeTrailer,
+// ResultCountTrailer and BytesTrailer HTTP trailers, after the response body,
+// so that computing them does not delay the response. They are only sent to
+// the clients sending a "TE: trailers" request header, as some clients do
+// not support trailers. Websocket connections are not accounted for.
+func StatsTrailers() HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.statsTrailers = true
+	}
+}
+
+// requestStats accumulates the cost of serving a request.
+type requestStats struct {
+	readTime atomic.Int64
+	results  atomic.Int64
+}
+
+type requestStatsKey struct{}
+
+// statsMiddleware records the time spent in the routes and the number of
+// items they return in the stats of the request, if any.
+func statsMiddleware(_ string, next routeHandler) routeHandler {
+	return func(ctx *rpctypes.Context, args []reflect.Value) (interface{}, error) {
+		var stats *requestStats
+		if ctx != nil && ctx.HTTPReq != nil {
+			stats, _ = ctx.HTTPReq.Context().Value(requestStatsKey{}).(*requestStats)
+		}
+		if stats == nil {
+			return next(ctx, args)
+		}
+		start := time.Now()
+		result, err := next(ctx, args)
+		stats.readTime.Add(int64(time.Since(start)))
+		if err == nil {
+			stats.results.Add(int64(resultCount(result)))
+		}
+		return result, err
+	}
+}
+
+var byteType = reflect.TypeOf(byte(0))
+
+// resultCount returns the number of items in the result of a route: the
+// length of its only list field, or 1 if it has none or several.
+func resultCount(result interface{}) int {
+	v := reflect.Indirect(reflect.ValueOf(result))
+	if v.Kind() != reflect.Struct {
+		return 1
+	}
+	count := -1
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !v.Type().Field(i).IsExported() || field.Kind() != reflect.Slice || field.Type().Elem() == byteType {
+			continue
+		}
+		if count >= 0 {
+			return 1
+		}
+		count = field.Len()
+	}
+	if count < 0 {
+		return 1
+	}
+	return count
+}
+
+// statsTrailerHandler sends the stats of the requests to h in trailers to the
+// clients accepting them.
+func statsTrailerHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" || !acceptsTrailers(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		stats := &requestStats{}
+		r = r.WithContext(context.WithValue(r.Context(), requestStatsKey{}, stats))
+		// Declaring the trailers makes the response use chunked transfer
+		// encoding, which is required to send them over HTTP/1.1.
+		w.Header().Set("Trailer", strings.Join([]string{ReadTimeTrailer, ResultCountTrailer, BytesTrailer}, ", "))
+		cw := &countingResponseWriter{ResponseWriter: w}
+		h.ServeHTTP(cw, r)
+
+		readTime := time.Duration(stats.readTime.Load())
+		w.Header().Set(ReadTimeTrailer, strconv.FormatFloat(readTime.Seconds(), 'f', 6, 64))
+		w.Header().Set(ResultCountTrailer, strconv.FormatInt(stats.results.Load(), 10))
+		w.Header().Set(BytesTrailer, strconv.FormatInt(cw.written, 10))
+	})
+}
+
+// acceptsTrailers returns whether the TE header of r accepts trailers.
+func acceptsTrailers(r *http.Request) bool {
+	for _, te := range r.Header.Values("TE") {
+		for _, coding := range strings.Split(te, ",") {
+			coding, _, _ = strings.Cut(coding, ";")
+			if strings.EqualFold(strings.TrimSpace(coding), "trailers") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// countingResponseWriter counts the bytes written to the response body.
+type countingResponseWriter struct {
+	http.ResponseWriter
+	written int64
+}
+
+func (w *countingResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher.
+func (w *countingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by
+// http.ResponseController.
+func (w *countingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,73 @@
+package rpc
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	indexermocks "github.com/cometbft/cometbft/state/indexer/mocks"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestStatsTrailers(t *testing.T) {
+	txs := make(types.Txs, 5)
+	for i := range txs {
+		txs[i] = types.Tx(fmt.Sprintf("tx%d", i))
+	}
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(1))
+	blockStoreMock.On("LoadBlock", int64(1)).Return(&types.Block{Data: types.Data{Txs: txs}})
+	cfg := config.TestRPCConfig()
+	logger := log.NewNopLogger()
+	routes := Routes(*cfg, &statemocks.Store{}, blockStoreMock,
+		&txindexmocks.TxIndexer{}, &indexermocks.BlockIndexer{}, logger)
+	srv := httptest.NewServer(Handler(cfg, routes, logger, StatsTrailers()))
+	defer srv.Close()
+
+	get := func(te string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/block_txs", nil)
+		require.NoError(t, err)
+		if te != "" {
+			req.Header.Set("TE", te)
+		}
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return res
+	}
+
+	res := get("trailers")
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "5", res.Trailer.Get(ResultCountTrailer))
+	require.Equal(t, strconv.Itoa(len(body)), res.Trailer.Get(BytesTrailer))
+	readTime, err := strconv.ParseFloat(res.Trailer.Get(ReadTimeTrailer), 64)
+	require.NoError(t, err)
+	require.Positive(t, readTime)
+
+	// Trailers are only sent to the clients accepting them.
+	res = get("")
+	_, err = io.ReadAll(res.Body)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Empty(t, res.Trailer)
+}
+
+func TestResultCount(t *testing.T) {
+	require.Equal(t, 3, resultCount(&ResultBlockTxs{Txs: make([]BlockTx, 3), TotalCount: 10}))
+	require.Equal(t, 0, resultCount(&ResultEvidence{}))
+	require.Equal(t, 1, resultCount(&BlockSize{}))
+	require.Equal(t, 1, resultCount(struct{ A, B []int }{}))
+	require.Equal(t, 1, resultCount("result"))
+}
@@ -0,0 +1,149 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/state/txindex/null"
+	"github.com/cometbft/cometbft/types"
+)
+
+// defaultMaxTxsLookup is the default maximum number of hashes accepted by the
+// txs route.
+const defaultMaxTxsLookup = 100
+
+// MaxTxsLookup sets the maximum number of hashes accepted by a single call to
+// the txs route.
+func MaxTxsLookup(count int) RoutesOption {
+	return func(env *environment) {
+		env.maxTxsLookup = count
+	}
+}
+
+// TxLookup is the result of looking up a single hash in the txs route.
+type TxLookup struct {
+	// Found is false if the transaction is not in the index, in which case
+	// Tx is nil.
+	Found bool             `json:"found"`
+	Tx    *ctypes.ResultTx `json:"tx,omitempty"`
+}
+
+// ResultTxs is the result of the txs route.
+type ResultTxs struct {
+	Txs []TxLookup `json:"txs"`
+}
+
+// Txs looks up the transactions with the given hashes. The results are in the
+// same order as the hashes, with transactions missing from the index marked
+// as not found rather than failing the whole request. If prove is true, each
+// found transaction includes its proof of inclusion in the block.
+func (env *environment) Txs(_ *rpctypes.Context, hashes [][]byte, prove bool) (*ResultTxs, error) {
+	if _, ok := env.TxIndexer.(*null.TxIndex); ok {
+		return nil, errors.New("transaction indexing is disabled")
+	}
+	if len(hashes) > env.maxTxsLookup {
+		return nil, fmt.Errorf("too many hashes: got %d, maximum is %d", len(hashes), env.maxTxsLookup)
+	}
+
+	// Transactions of the same block share the block loaded for their proofs.
+	blocks := make(map[int64]*types.Block)
+	txs := make([]TxLookup, len(hashes))
+	for i, hash := range hashes {
+		r, err := env.TxIndexer.Get(hash)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			continue
+		}
+
+		var proof types.TxProof
+		if prove {
+			block, ok := blocks[r.Height]
+			if !ok {
+				block = env.BlockStore.LoadBlock(r.Height)
+				blocks[r.Height] = block
+			}
+			if block == nil {
+				return nil, fmt.Errorf("block at height %d of tx (%X) not found", r.Height, hash)
+			}
+			proof = block.Data.Txs.Proof(int(r.Index))
+		}
+
+		txs[i] = TxLookup{
+			Found: true,
+			Tx: &ctypes.ResultTx{
+				Hash:     hash,
+				Height:   r.Height,
+				Index:    r.Index,
+				TxResult: r.Result,
+				Tx:       r.Tx,
+				Proof:    proof,
+			},
+		}
+	}
+
+	return &ResultTxs{Txs: txs}, nil
+}
+
+// ResultTxLocation is the result of the tx_locate route.
+type ResultTxLocation struct {
+	// Found is false if the transaction is not in the index, in which case
+	// Height and Index are zero.
+	Found  bool   `json:"found"`
+	Height int64  `json:"height"`
+	Index  uint32 `json:"index"`
+}
+
+// TxLocate returns the height of the block containing the transaction with
+// the given hash, and the index of the transaction in the block. Unlike the
+// tx route, the block store is never read and neither the transaction nor its
+// result are returned.
+func (env *environment) TxLocate(_ *rpctypes.Context, hash []byte) (*ResultTxLocation, error) {
+	if _, ok := env.TxIndexer.(*null.TxIndex); ok {
+		return nil, errors.New("transaction indexing is disabled")
+	}
+
+	r, err := env.TxIndexer.Get(hash)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return &ResultTxLocation{}, nil
+	}
+	return &ResultTxLocation{Found: true, Height: r.Height, Index: r.Index}, nil
+}
+
+// ResultTxProofFull is the result of the tx_proof_full route: a transaction
+// with its proof of inclusion in the block, along with the header and commit
+// of the block. Clients verify the proof against the data hash of Header,
+// whose hash is signed by Commit.
+type ResultTxProofFull struct {
+	Tx     *ctypes.ResultTx `json:"tx"`
+	Header *types.Header    `json:"header"`
+	// Commit is the commit for the block, which is null if the block is the
+	// latest one and its commit is not stored.
+	Commit *types.Commit `json:"commit"`
+	// Canonical is false if Commit is the commit seen by the node for the
+	// latest block, rather than the one included in the next block.
+	Canonical bool `json:"canonical"`
+}
+
+// TxProofFull returns the transaction with the given hash, with its proof of
+// inclusion as returned by the tx route, along with the header and commit of
+// its block, as returned by the block_with_commit route.
+func (env *environment) TxProofFull(ctx *rpctypes.Context, hash []byte) (*ResultTxProofFull, error) {
+	resultTx, err := env.Tx(ctx, hash, true)
+	if err != nil {
+		return nil, err
+	}
+	blockMeta := env.BlockStore.LoadBlockMeta(resultTx.Height)
+	if blockMeta == nil {
+		return nil, fmt.Errorf("block at height %d not found", resultTx.Height)
+	}
+	res := &ResultTxProofFull{Tx: resultTx, Header: &blockMeta.Header}
+	res.Commit, res.Canonical = env.loadCommit(resultTx.Height)
+	return res, nil
+}
@@ -0,0 +1,112 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/libs/log"
+	indexermocks "github.com/cometbft/cometbft/state/indexer/mocks"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	txindexmocks "github.com/cometbft/cometbft/state/txindex/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestTxs(t *testing.T) {
+	txs := types.Txs{types.Tx("tx0"), types.Tx("tx1")}
+	block := &types.Block{Data: types.Data{Txs: txs}}
+	missing := []byte("missing")
+
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	for i, tx := range txs {
+		txIndexerMock.On("Get", []byte(tx.Hash())).Return(&abcitypes.TxResult{
+			Height: 1,
+			Index:  uint32(i),
+			Tx:     tx,
+		}, nil)
+	}
+	txIndexerMock.On("Get", missing).Return(nil, nil)
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("LoadBlock", int64(1)).Return(block)
+
+	env := newEnvironment(*config.TestRPCConfig(), &statemocks.Store{}, blockStoreMock, txIndexerMock,
+		&indexermocks.BlockIndexer{}, log.NewNopLogger(), MaxTxsLookup(3))
+
+	res, err := env.Txs(nil, [][]byte{txs[1].Hash(), missing, txs[0].Hash()}, true)
+	require.NoError(t, err)
+	require.Len(t, res.Txs, 3)
+	require.True(t, res.Txs[0].Found)
+	require.Equal(t, txs[1], res.Txs[0].Tx.Tx)
+	require.Equal(t, block.Data.Txs.Proof(1), res.Txs[0].Tx.Proof)
+	require.False(t, res.Txs[1].Found)
+	require.Nil(t, res.Txs[1].Tx)
+	require.True(t, res.Txs[2].Found)
+	require.Equal(t, txs[0], res.Txs[2].Tx.Tx)
+	blockStoreMock.AssertNumberOfCalls(t, "LoadBlock", 1)
+
+	_, err = env.Txs(nil, [][]byte{missing, missing, missing, missing}, false)
+	require.Error(t, err)
+}
+
+func TestTxLocate(t *testing.T) {
+	tx := types.Tx("tx")
+	missing := []byte("missing")
+
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	txIndexerMock.On("Get", []byte(tx.Hash())).Return(&abcitypes.TxResult{Height: 4, Index: 2, Tx: tx}, nil)
+	txIndexerMock.On("Get", missing).Return(nil, nil)
+	blockStoreMock := &statemocks.BlockStore{}
+
+	env := newEnvironment(*config.TestRPCConfig(), &statemocks.Store{}, blockStoreMock, txIndexerMock,
+		&indexermocks.BlockIndexer{}, log.NewNopLogger())
+
+	res, err := env.TxLocate(nil, tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, &ResultTxLocation{Found: true, Height: 4, Index: 2}, res)
+
+	res, err = env.TxLocate(nil, missing)
+	require.NoError(t, err)
+	require.False(t, res.Found)
+	blockStoreMock.AssertNotCalled(t, "LoadBlock", int64(4))
+}
+
+func TestTxProofFull(t *testing.T) {
+	txs := types.Txs{types.Tx("tx0"), types.Tx("tx1")}
+	txIndexerMock := &txindexmocks.TxIndexer{}
+	for i, tx := range txs {
+		txIndexerMock.On("Get", []byte(tx.Hash())).Return(&abcitypes.TxResult{
+			Height: int64(i + 1),
+			Index:  0,
+			Tx:     tx,
+		}, nil)
+	}
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Height").Return(int64(2))
+	for i, tx := range txs {
+		height := int64(i + 1)
+		block := &types.Block{Header: types.Header{Height: height}, Data: types.Data{Txs: types.Txs{tx}}}
+		blockStoreMock.On("LoadBlock", height).Return(block)
+		blockStoreMock.On("LoadBlockMeta", height).Return(&types.BlockMeta{Header: block.Header})
+	}
+	blockStoreMock.On("LoadBlockCommit", int64(1)).Return(&types.Commit{Height: 1})
+	blockStoreMock.On("LoadSeenCommit", int64(2)).Return(nil)
+
+	env := newEnvironment(*config.TestRPCConfig(), &statemocks.Store{}, blockStoreMock, txIndexerMock,
+		&indexermocks.BlockIndexer{}, log.NewNopLogger())
+
+	res, err := env.TxProofFull(nil, txs[0].Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.Txs{txs[0]}.Proof(0), res.Tx.Proof)
+	require.Equal(t, int64(1), res.Header.Height)
+	require.Equal(t, int64(1), res.Commit.Height)
+	require.True(t, res.Canonical)
+
+	// The commit for the latest block is not stored yet.
+	res, err = env.TxProofFull(nil, txs[1].Hash())
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res.Header.Height)
+	require.Nil(t, res.Commit)
+	require.False(t, res.Canonical)
+}
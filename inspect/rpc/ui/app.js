@@ -0,0 +1,90 @@
+// The UI is served under /ui/ and calls the JSON-RPC routes served at the
+// root of the same server, using the JSON-RPC over HTTP POST interface.
+const rpcURL = new URL("..", window.location.href);
+
+let requestID = 0;
+let lowestHeight = 0;
+
+async function call(method, params) {
+  const res = await fetch(rpcURL, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ jsonrpc: "2.0", id: ++requestID, method, params }),
+  });
+  const body = await res.json();
+  if (body.error) {
+    throw new Error(body.error.message + (body.error.data ? ": " + body.error.data : ""));
+  }
+  return body.result;
+}
+
+function show(value) {
+  const result = document.getElementById("result");
+  result.classList.remove("error");
+  result.textContent = JSON.stringify(value, null, 2);
+}
+
+function showError(err) {
+  const result = document.getElementById("result");
+  result.classList.add("error");
+  result.textContent = err.message;
+}
+
+async function showBlock(height) {
+  try {
+    show(await call("block", { height: String(height) }));
+  } catch (err) {
+    showError(err);
+  }
+}
+
+async function loadBlocks(maxHeight) {
+  const params = maxHeight > 0 ? { minHeight: "0", maxHeight: String(maxHeight) } : {};
+  const result = await call("blockchain", params);
+  const tbody = document.querySelector("#blocks tbody");
+  for (const meta of result.block_metas) {
+    const row = tbody.insertRow();
+    const height = meta.header.height;
+    row.insertCell().textContent = height;
+    const hash = row.insertCell();
+    hash.className = "hash";
+    hash.textContent = meta.block_id.hash;
+    row.insertCell().textContent = meta.header.time;
+    row.insertCell().textContent = meta.num_txs;
+    row.addEventListener("click", () => showBlock(height));
+    lowestHeight = Number(height);
+  }
+}
+
+async function loadStatus() {
+  const status = await call("status", {});
+  const info = status.sync_info;
+  document.getElementById("status").textContent =
+    `Blocks ${info.earliest_block_height} to ${info.latest_block_height}` +
+    (status.stale ? " (stale)" : "");
+}
+
+document.getElementById("block-form").addEventListener("submit", (e) => {
+  e.preventDefault();
+  showBlock(document.getElementById("block-height").value);
+});
+
+document.getElementById("search-form").addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const method = document.getElementById("search-kind").value;
+  const query = document.getElementById("search-query").value;
+  try {
+    show(await call(method, { query }));
+  } catch (err) {
+    showError(err);
+  }
+});
+
+document.getElementById("older").addEventListener("click", () => {
+  if (lowestHeight > 1) {
+    loadBlocks(lowestHeight - 1).catch(showError);
+  }
+});
+
+loadStatus().catch(showError);
+loadBlocks(0).catch(showError);
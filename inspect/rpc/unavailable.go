@@ -0,0 +1,101 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"syscall"
+	"time"
+
+	dbm "github.com/cometbft/cometbft-db"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// codeServiceUnavailable is the JSON-RPC error code returned when a data store
+// is temporarily unavailable.
+const codeServiceUnavailable = -32001
+
+// StoreUnavailableFunc reports whether err, returned or raised while reading
+// from a data store, means that the store is temporarily unavailable, for
+// example because it is locked or being compacted.
+type StoreUnavailableFunc func(err error) bool
+
+// StoreUnavailable makes the routes report errors for which isUnavailable
+// returns true as a JSON-RPC "service temporarily unavailable" error (code
+// -32001), instead of an internal error. The HTTP response carries a
+// Retry-After header set to retryAfter, so that clients can back off and retry.
+func StoreUnavailable(isUnavailable StoreUnavailableFunc, retryAfter time.Duration) RoutesOption {
+	return func(env *environment) {
+		env.isStoreUnavailable = isUnavailable
+		env.retryAfter = retryAfter
+	}
+}
+
+// StoreUnavailableFuncForBackend returns the StoreUnavailableFunc detecting the
+// transient errors of the given database backend. Errors from the operating
+// system signaling that a resource is temporarily busy are detected for all
+// backends.
+func StoreUnavailableFuncForBackend(backend dbm.BackendType) StoreUnavailableFunc {
+	switch backend {
+	case dbm.GoLevelDBBackend:
+		return func(err error) bool {
+			return errors.Is(err, leveldb.ErrClosed) || isResourceBusy(err)
+		}
+	default:
+		return isResourceBusy
+	}
+}
+
+func isResourceBusy(err error) bool {
+	return errors.Is(err, syscall.EAGAIN) ||
+		errors.Is(err, syscall.EWOULDBLOCK) ||
+		errors.Is(err, syscall.EBUSY)
+}
+
+// storeUnavailableMiddleware maps the errors returned by a route, as well as
+// the errors the block store panics with, to a service unavailable error.
+func storeUnavailableMiddleware(isUnavailable StoreUnavailableFunc, retryAfter time.Duration) routeMiddleware {
+	return func(_ string, next routeHandler) routeHandler {
+		return func(ctx *rpctypes.Context, args []reflect.Value) (result interface{}, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					rErr, ok := r.(error)
+					if !ok || !isUnavailable(rErr) {
+						panic(r)
+					}
+					result, err = nil, serviceUnavailableError(ctx, rErr, retryAfter)
+				}
+			}()
+
+			result, err = next(ctx, args)
+			if err != nil && isUnavailable(err) {
+				return nil, serviceUnavailableError(ctx, err, retryAfter)
+			}
+			return result, err
+		}
+	}
+}
+
+func serviceUnavailableError(ctx *rpctypes.Context, err error, retryAfter time.Duration) error {
+	if state := stateFromContext(ctx); state != nil {
+		state.retryAfter = retryAfter
+	}
+	return &rpctypes.RPCError{
+		Code:    codeServiceUnavailable,
+		Message: "Service temporarily unavailable",
+		Data:    fmt.Sprintf("%v; retry after %s seconds", err, retryAfterSeconds(retryAfter)),
+	}
+}
+
+// retryAfterSeconds formats d as the number of seconds of a Retry-After header,
+// rounded up to at least one second.
+func retryAfterSeconds(d time.Duration) string {
+	seconds := int64((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+)
+
+func TestStoreUnavailable(t *testing.T) {
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Height").Return(int64(1))
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("LoadBlockMeta", int64(1)).Run(func(mock.Arguments) {
+		panic(fmt.Errorf("reading block meta: %w", syscall.EAGAIN))
+	})
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("LoadFinalizeBlockResponse", int64(1)).
+		Return((*abcitypes.ResponseFinalizeBlock)(nil), fmt.Errorf("reading results: %w", syscall.EBUSY))
+	h := newTestHandler(blockStoreMock, stateStoreMock,
+		StoreUnavailable(StoreUnavailableFuncForBackend("goleveldb"), 2*time.Second))
+
+	t.Run("uri", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/header?height=1", nil))
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		require.Equal(t, "2", rec.Header().Get("Retry-After"))
+
+		var res rpctypes.RPCResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		require.Equal(t, codeServiceUnavailable, res.Error.Code)
+	})
+
+	t.Run("jsonrpc", func(t *testing.T) {
+		res := callJSONRPC(t, h, "block_results", `{"height":"1"}`)
+		require.NotNil(t, res.Error)
+		require.Equal(t, codeServiceUnavailable, res.Error.Code)
+		require.Contains(t, res.Error.Data, "retry after 2 seconds")
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(
+			`[{"jsonrpc":"2.0","id":1,"method":"header","params":{"height":"1"}},`+
+				`{"jsonrpc":"2.0","id":2,"method":"block_results","params":{"height":"1"}}]`)))
+		var res []rpctypes.RPCResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		require.Len(t, res, 2)
+		for _, r := range res {
+			require.Equal(t, codeServiceUnavailable, r.Error.Code)
+			require.Equal(t, "Service temporarily unavailable", r.Error.Message)
+		}
+	})
+
+	t.Run("websocket", func(t *testing.T) {
+		srv := httptest.NewServer(h)
+		defer srv.Close()
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/websocket", nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage,
+			[]byte(`{"jsonrpc":"2.0","id":1,"method":"header","params":{"height":"1"}}`)))
+		var res rpctypes.RPCResponse
+		require.NoError(t, conn.ReadJSON(&res))
+		require.Equal(t, codeServiceUnavailable, res.Error.Code)
+		require.Contains(t, res.Error.Data, "retry after 2 seconds")
+	})
+}
+
+func TestStoreUnavailableFuncForBackend(t *testing.T) {
+	isUnavailable := StoreUnavailableFuncForBackend("goleveldb")
+	require.True(t, isUnavailable(fmt.Errorf("get: %w", syscall.EAGAIN)))
+	require.True(t, isUnavailable(leveldb.ErrClosed))
+	require.False(t, isUnavailable(fmt.Errorf("corrupted block meta")))
+}
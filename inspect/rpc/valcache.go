@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	"time"
+
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+)
+
+// ValidatorSetCacheSize caches up to size validator sets loaded from the state
+// store, evicting the least recently used ones. The cache serves the
+// validators, commit_signers and validator_updates_range routes. Only the
+// sets below the latest height are cached, as they no longer change. By
+// default, validator sets are not cached.
+func ValidatorSetCacheSize(size int) RoutesOption {
+	return func(env *environment) {
+		env.validatorSetCacheSize = size
+	}
+}
+
+// CacheTTL expires the entries of the caches of the routes, such as the
+// validator set cache and the route caches without a TTL of their own, once
+// they are older than ttl, even if they were not evicted. This bounds how
+// stale the responses may be when the stores are modified while being served,
+// for instance when re-indexing. By default, entries do not expire, as the
+// cached data is immutable.
+func CacheTTL(ttl time.Duration) RoutesOption {
+	return func(env *environment) {
+		env.cacheTTL = ttl
+	}
+}
+
+// cachedValidatorsStore is a state store caching the validator sets it loads.
+type cachedValidatorsStore struct {
+	state.Store
+	blockStore state.BlockStore
+	cache      *validatorSetCache
+}
+
+// LoadValidators returns a copy of the cached validator set at height, which
+// is loaded and cached if missing. Errors are not cached.
+func (s cachedValidatorsStore) LoadValidators(height int64) (*types.ValidatorSet, error) {
+	if height >= s.blockStore.Height() {
+		return s.Store.LoadValidators(height)
+	}
+	if vals, ok := s.cache.get(height); ok {
+		return vals.Copy(), nil
+	}
+	vals, err := s.Store.LoadValidators(height)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.add(height, vals.Copy())
+	return vals, nil
+}
+
+// validatorSetCache is an LRU cache of validator sets by height.
+type validatorSetCache = lruCache[int64, *types.ValidatorSet]
+
+func newValidatorSetCache(size int, ttl time.Duration) *validatorSetCache {
+	return newLRUCache[int64, *types.ValidatorSet](size, ttl)
+}
@@ -0,0 +1,80 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/state"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestValidatorSetCache(t *testing.T) {
+	vals, _ := types.RandValidatorSet(3, 10)
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(5))
+	stateStoreMock := &statemocks.Store{}
+	for height := int64(1); height <= 5; height++ {
+		stateStoreMock.On("LoadValidators", height).Return(vals, nil)
+	}
+	stateStoreMock.On("LoadValidators", int64(6)).Return(nil, state.ErrNoValSetForHeight{Height: 6})
+	env := newTestEnvironment(blockStoreMock, stateStoreMock, ValidatorSetCacheSize(2))
+	load := func(height int64) *types.ValidatorSet {
+		res, err := env.StateStore.LoadValidators(height)
+		require.NoError(t, err)
+		return res
+	}
+
+	load(2)
+	load(3)
+	load(2)
+	load(3)
+	stateStoreMock.AssertNumberOfCalls(t, "LoadValidators", 2)
+
+	// Loading a third set evicts the least recently used one.
+	load(4)
+	load(3)
+	stateStoreMock.AssertNumberOfCalls(t, "LoadValidators", 3)
+	load(2)
+	stateStoreMock.AssertNumberOfCalls(t, "LoadValidators", 4)
+
+	// The latest height and errors are never cached.
+	load(5)
+	load(5)
+	_, err := env.StateStore.LoadValidators(6)
+	require.Error(t, err)
+	_, err = env.StateStore.LoadValidators(6)
+	require.Error(t, err)
+	stateStoreMock.AssertNumberOfCalls(t, "LoadValidators", 8)
+
+	// The cached sets are not shared with the callers.
+	res := load(2)
+	res.Validators[0].VotingPower++
+	require.Equal(t, vals.Validators[0].VotingPower, load(2).Validators[0].VotingPower)
+}
+
+func TestValidatorSetCacheTTL(t *testing.T) {
+	vals, _ := types.RandValidatorSet(1, 10)
+	now := time.Now()
+	cache := newValidatorSetCache(10, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.add(1, vals)
+	_, ok := cache.get(1)
+	require.True(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = cache.get(1)
+	require.False(t, ok)
+	require.Zero(t, cache.list.Len())
+
+	// Entries do not expire without a TTL.
+	cache = newValidatorSetCache(10, 0)
+	cache.add(1, vals)
+	cache.now = func() time.Time { return now.Add(24 * time.Hour) }
+	_, ok = cache.get(1)
+	require.True(t, ok)
+}
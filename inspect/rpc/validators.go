@@ -0,0 +1,295 @@
+package rpc
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/cometbft/cometbft/crypto"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+)
+
+// ValidatorChange is a change of the voting power of a validator. Validators
+// joining the set have a previous voting power of 0, and validators leaving
+// it have a voting power of 0.
+type ValidatorChange struct {
+	Address             types.Address `json:"address"`
+	PubKey              crypto.PubKey `json:"pub_key"`
+	VotingPower         int64         `json:"voting_power"`
+	PreviousVotingPower int64         `json:"previous_voting_power"`
+}
+
+// ValidatorUpdates are the changes of the validator set at a height, compared
+// to the set at the previous height.
+type ValidatorUpdates struct {
+	Height  int64             `json:"height"`
+	Changes []ValidatorChange `json:"changes"`
+}
+
+// ResultValidatorUpdatesRange is the result of the validator_updates_range
+// route.
+type ResultValidatorUpdatesRange struct {
+	LastHeight int64              `json:"last_height"`
+	Updates    []ValidatorUpdates `json:"updates"`
+}
+
+// ValidatorUpdatesRange returns the changes of the validator set at each
+// height of minHeight <= height <= maxHeight at which the set changed, in
+// ascending order. The range is resolved as in the blockchain route.
+//
+// The changes are computed from the validator sets in the state store. The
+// heights at which either the set or the previous set is missing, for
+// instance because they were pruned, are skipped. The whole set at height 1
+// is reported as added.
+func (env *environment) ValidatorUpdatesRange(
+	_ *rpctypes.Context,
+	minHeight, maxHeight int64,
+) (*ResultValidatorUpdatesRange, error) {
+	minHeight, maxHeight, err := env.heightRange(minHeight, maxHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	prev, err := env.loadValidators(minHeight - 1)
+	if err != nil {
+		return nil, err
+	}
+	updates := make([]ValidatorUpdates, 0)
+	for height := minHeight; height <= maxHeight; height++ {
+		vals, err := env.loadValidators(height)
+		if err != nil {
+			return nil, err
+		}
+		if vals != nil && prev != nil {
+			if changes := validatorChanges(prev, vals); len(changes) > 0 {
+				updates = append(updates, ValidatorUpdates{Height: height, Changes: changes})
+			}
+		}
+		prev = vals
+	}
+
+	return &ResultValidatorUpdatesRange{
+		LastHeight: env.BlockStore.Height(),
+		Updates:    updates,
+	}, nil
+}
+
+// ResultValidatorSetDiff is the result of the validator_set_diff route. The
+// changes are those of ValidatorUpdates, split by kind.
+type ResultValidatorSetDiff struct {
+	FromHeight int64 `json:"from_height"`
+	ToHeight   int64 `json:"to_height"`
+	// Available is false if the validator set at either height is missing
+	// from the state store, for instance because it was pruned, in which
+	// case no changes are reported.
+	Available    bool              `json:"available"`
+	Added        []ValidatorChange `json:"added"`
+	Removed      []ValidatorChange `json:"removed"`
+	PowerChanged []ValidatorChange `json:"power_changed"`
+}
+
+// ValidatorSetDiff returns the changes between the validator sets at
+// fromHeight and toHeight, which need not be consecutive: the validators in
+// the set at toHeight but not at fromHeight, those in the set at fromHeight
+// but not at toHeight, and those whose voting power differs. Either height may
+// be the height after the latest block, as in the validators route.
+func (env *environment) ValidatorSetDiff(
+	_ *rpctypes.Context,
+	fromHeight, toHeight int64,
+) (*ResultValidatorSetDiff, error) {
+	latestHeight := env.BlockStore.Height() + 1
+	for _, height := range []int64{fromHeight, toHeight} {
+		if _, err := env.getHeight(latestHeight, &height); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &ResultValidatorSetDiff{
+		FromHeight:   fromHeight,
+		ToHeight:     toHeight,
+		Added:        []ValidatorChange{},
+		Removed:      []ValidatorChange{},
+		PowerChanged: []ValidatorChange{},
+	}
+	from, err := env.loadValidators(fromHeight)
+	if err != nil {
+		return nil, err
+	}
+	to, err := env.loadValidators(toHeight)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil || to == nil {
+		return res, nil
+	}
+
+	res.Available = true
+	for _, change := range validatorChanges(from, to) {
+		switch {
+		case change.PreviousVotingPower == 0:
+			res.Added = append(res.Added, change)
+		case change.VotingPower == 0:
+			res.Removed = append(res.Removed, change)
+		default:
+			res.PowerChanged = append(res.PowerChanged, change)
+		}
+	}
+	return res, nil
+}
+
+// VotingPower is the total voting power and number of validators of the
+// validator set at a height.
+type VotingPower struct {
+	Height           int64 `json:"height"`
+	TotalVotingPower int64 `json:"total_voting_power"`
+	Count            int   `json:"count"`
+}
+
+// VotingPower returns the total voting power and number of validators of the
+// validator set at the given height, or at the height after the latest block
+// if no height is given, as the validators route would, without the set.
+func (env *environment) VotingPower(_ *rpctypes.Context, heightPtr *int64) (*VotingPower, error) {
+	height, err := env.getHeight(env.BlockStore.Height()+1, heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	vals, err := env.StateStore.LoadValidators(height)
+	if err != nil {
+		return nil, err
+	}
+	return &VotingPower{Height: height, TotalVotingPower: vals.TotalVotingPower(), Count: vals.Size()}, nil
+}
+
+// ResultValidatorsWithPriority is the result of the validators_with_priority
+// route.
+type ResultValidatorsWithPriority struct {
+	BlockHeight int64 `json:"block_height"`
+	// Proposer is the validator proposing the first round of the height.
+	Proposer *types.Validator `json:"proposer"`
+	// Validators are ordered by decreasing proposer priority, then by
+	// address.
+	Validators []*types.Validator `json:"validators"`
+}
+
+// ValidatorsWithPriority returns the validator set at the given height, or at
+// the height after the latest block if no height is given, as the validators
+// route would, ordered by the proposer priorities of the validators and
+// without pagination. The priorities are those of the set as stored for the
+// height, once the proposer of its first round was selected, which lowered
+// the priority of the proposer by the total voting power.
+//
+// The state store keeps the priorities of the sets it stores at the heights
+// at which they change, and recomputes those of the heights in between, so
+// the priorities are available for every height whose set is.
+func (env *environment) ValidatorsWithPriority(
+	_ *rpctypes.Context,
+	heightPtr *int64,
+) (*ResultValidatorsWithPriority, error) {
+	height, err := env.getHeight(env.BlockStore.Height()+1, heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	vals, err := env.StateStore.LoadValidators(height)
+	if err != nil {
+		return nil, err
+	}
+	// The set may be shared with the validator set cache, so it is copied
+	// before being sorted.
+	vals = vals.Copy()
+	sort.SliceStable(vals.Validators, func(i, j int) bool {
+		a, b := vals.Validators[i], vals.Validators[j]
+		if a.ProposerPriority != b.ProposerPriority {
+			return a.ProposerPriority > b.ProposerPriority
+		}
+		return bytes.Compare(a.Address, b.Address) < 0
+	})
+	return &ResultValidatorsWithPriority{
+		BlockHeight: height,
+		Proposer:    vals.GetProposer(),
+		Validators:  vals.Validators,
+	}, nil
+}
+
+// ResultVotingPowerRange is the result of the voting_power_range route.
+type ResultVotingPowerRange struct {
+	LastHeight   int64         `json:"last_height"`
+	VotingPowers []VotingPower `json:"voting_powers"`
+}
+
+// VotingPowerRange returns the total voting power and number of validators of
+// the validator sets for minHeight <= height <= maxHeight, in ascending order.
+// The range is resolved as in the blockchain route. The heights whose
+// validator set is missing from the state store are skipped.
+func (env *environment) VotingPowerRange(
+	_ *rpctypes.Context,
+	minHeight, maxHeight int64,
+) (*ResultVotingPowerRange, error) {
+	minHeight, maxHeight, err := env.heightRange(minHeight, maxHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	powers := make([]VotingPower, 0, maxHeight-minHeight+1)
+	for height := minHeight; height <= maxHeight; height++ {
+		vals, err := env.loadValidators(height)
+		if err != nil {
+			return nil, err
+		}
+		if vals == nil {
+			continue
+		}
+		powers = append(powers, VotingPower{Height: height, TotalVotingPower: vals.TotalVotingPower(), Count: vals.Size()})
+	}
+
+	return &ResultVotingPowerRange{
+		LastHeight:   env.BlockStore.Height(),
+		VotingPowers: powers,
+	}, nil
+}
+
+// loadValidators loads the validator set at height, which is empty at height
+// 0 and nil if missing from the state store.
+func (env *environment) loadValidators(height int64) (*types.ValidatorSet, error) {
+	if height == 0 {
+		return types.NewValidatorSet(nil), nil
+	}
+	vals, err := env.StateStore.LoadValidators(height)
+	var errNoValSet state.ErrNoValSetForHeight
+	if errors.As(err, &errNoValSet) {
+		return nil, nil
+	}
+	return vals, err
+}
+
+// validatorChanges returns the changes from the validator set prev to vals, in
+// the order of vals followed by the removed validators in the order of prev.
+func validatorChanges(prev, vals *types.ValidatorSet) []ValidatorChange {
+	var changes []ValidatorChange
+	for _, val := range vals.Validators {
+		_, prevVal := prev.GetByAddress(val.Address)
+		var prevPower int64
+		if prevVal != nil {
+			prevPower = prevVal.VotingPower
+		}
+		if prevPower != val.VotingPower {
+			changes = append(changes, ValidatorChange{
+				Address:             val.Address,
+				PubKey:              val.PubKey,
+				VotingPower:         val.VotingPower,
+				PreviousVotingPower: prevPower,
+			})
+		}
+	}
+	for _, prevVal := range prev.Validators {
+		if !vals.HasAddress(prevVal.Address) {
+			changes = append(changes, ValidatorChange{
+				Address:             prevVal.Address,
+				PubKey:              prevVal.PubKey,
+				PreviousVotingPower: prevVal.VotingPower,
+			})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,142 @@
+package rpc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/state"
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/types"
+)
+
+func TestValidatorUpdatesRange(t *testing.T) {
+	vals, _ := types.RandValidatorSet(3, 10)
+	v0, v1, v2 := vals.Validators[0], vals.Validators[1], vals.Validators[2]
+	changed := types.NewValidator(v1.PubKey, 20)
+
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(5))
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("LoadValidators", int64(1)).Return(types.NewValidatorSet([]*types.Validator{v0, v1}), nil)
+	stateStoreMock.On("LoadValidators", int64(2)).Return(types.NewValidatorSet([]*types.Validator{v0, v1}), nil)
+	stateStoreMock.On("LoadValidators", int64(3)).Return(types.NewValidatorSet([]*types.Validator{v0, changed, v2}), nil)
+	stateStoreMock.On("LoadValidators", int64(4)).Return(types.NewValidatorSet([]*types.Validator{changed, v2}), nil)
+	stateStoreMock.On("LoadValidators", int64(5)).Return(nil, state.ErrNoValSetForHeight{Height: 5})
+	env := newTestEnvironment(blockStoreMock, stateStoreMock)
+
+	res, err := env.ValidatorUpdatesRange(nil, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), res.LastHeight)
+
+	type change struct {
+		address     types.Address
+		power, prev int64
+	}
+	updates := make(map[int64][]change)
+	for _, u := range res.Updates {
+		for _, c := range u.Changes {
+			updates[u.Height] = append(updates[u.Height], change{c.Address, c.VotingPower, c.PreviousVotingPower})
+		}
+	}
+	require.ElementsMatch(t, []change{{v0.Address, 10, 0}, {v1.Address, 10, 0}}, updates[1])
+	require.ElementsMatch(t, []change{{v1.Address, 20, 10}, {v2.Address, 10, 0}}, updates[3])
+	require.ElementsMatch(t, []change{{v0.Address, 0, 10}}, updates[4])
+	require.Len(t, updates, 3)
+}
+
+func TestVotingPower(t *testing.T) {
+	vals, _ := types.RandValidatorSet(3, 10)
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(3))
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("LoadValidators", int64(1)).Return(nil, state.ErrNoValSetForHeight{Height: 1})
+	for _, height := range []int64{2, 3, 4} {
+		stateStoreMock.On("LoadValidators", height).Return(vals, nil)
+	}
+	env := newTestEnvironment(blockStoreMock, stateStoreMock)
+
+	res, err := env.VotingPower(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, &VotingPower{Height: 4, TotalVotingPower: 30, Count: 3}, res)
+
+	height := int64(1)
+	_, err = env.VotingPower(nil, &height)
+	require.Error(t, err)
+
+	resRange, err := env.VotingPowerRange(nil, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, []VotingPower{
+		{Height: 2, TotalVotingPower: 30, Count: 3},
+		{Height: 3, TotalVotingPower: 30, Count: 3},
+	}, resRange.VotingPowers)
+}
+
+func TestValidatorSetDiff(t *testing.T) {
+	vals, _ := types.RandValidatorSet(3, 10)
+	v0, v1, v2 := vals.Validators[0], vals.Validators[1], vals.Validators[2]
+	changed := types.NewValidator(v1.PubKey, 20)
+
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(5))
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("LoadValidators", int64(1)).Return(nil, state.ErrNoValSetForHeight{Height: 1})
+	stateStoreMock.On("LoadValidators", int64(2)).Return(types.NewValidatorSet([]*types.Validator{v0, v1}), nil)
+	stateStoreMock.On("LoadValidators", int64(6)).Return(types.NewValidatorSet([]*types.Validator{changed, v2}), nil)
+	env := newTestEnvironment(blockStoreMock, stateStoreMock)
+
+	res, err := env.ValidatorSetDiff(nil, 2, 6)
+	require.NoError(t, err)
+	require.True(t, res.Available)
+	require.Len(t, res.Added, 1)
+	require.Equal(t, v2.Address, res.Added[0].Address)
+	require.Len(t, res.Removed, 1)
+	require.Equal(t, v0.Address, res.Removed[0].Address)
+	require.Equal(t, []ValidatorChange{{
+		Address: v1.Address, PubKey: v1.PubKey, VotingPower: 20, PreviousVotingPower: v1.VotingPower,
+	}}, res.PowerChanged)
+
+	// The heights may be in any order.
+	res, err = env.ValidatorSetDiff(nil, 6, 2)
+	require.NoError(t, err)
+	require.Equal(t, v0.Address, res.Added[0].Address)
+
+	// Missing sets are reported without changes.
+	res, err = env.ValidatorSetDiff(nil, 1, 2)
+	require.NoError(t, err)
+	require.False(t, res.Available)
+	require.Empty(t, res.Added)
+
+	_, err = env.ValidatorSetDiff(nil, 2, 7)
+	require.Error(t, err)
+}
+
+func TestValidatorsWithPriority(t *testing.T) {
+	vals, _ := types.RandValidatorSet(4, 10)
+	vals.IncrementProposerPriority(3)
+	blockStoreMock := &statemocks.BlockStore{}
+	blockStoreMock.On("Base").Return(int64(1))
+	blockStoreMock.On("Height").Return(int64(2))
+	stateStoreMock := &statemocks.Store{}
+	stateStoreMock.On("LoadValidators", int64(3)).Return(vals, nil)
+	env := newTestEnvironment(blockStoreMock, stateStoreMock)
+
+	res, err := env.ValidatorsWithPriority(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), res.BlockHeight)
+	require.Equal(t, vals.GetProposer().Address, res.Proposer.Address)
+	require.Len(t, res.Validators, 4)
+	for i := 1; i < len(res.Validators); i++ {
+		prev, val := res.Validators[i-1], res.Validators[i]
+		require.True(t, prev.ProposerPriority > val.ProposerPriority ||
+			(prev.ProposerPriority == val.ProposerPriority && bytes.Compare(prev.Address, val.Address) < 0))
+	}
+	// The stored set is left in its order.
+	for i := 1; i < vals.Size(); i++ {
+		require.Negative(t, bytes.Compare(vals.Validators[i-1].Address, vals.Validators[i].Address))
+	}
+}
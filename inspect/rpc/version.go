@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sort"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/version"
+)
+
+// ResultVersion is the result of the version route.
+type ResultVersion struct {
+	Version       string `json:"version"`
+	ABCIVersion   string `json:"abci_version"`
+	BlockProtocol uint64 `json:"block_protocol"`
+	// GitCommit is the commit the server was built from, if known.
+	GitCommit string `json:"git_commit,omitempty"`
+	GoVersion string `json:"go_version"`
+	// Features lists the optional features of the routes enabled on the
+	// server, sorted.
+	Features []string `json:"features"`
+}
+
+// Version returns the version of the server, the commit and Go version it was
+// built with, and the optional features of its routes that are enabled.
+func (env *environment) Version(*rpctypes.Context) (*ResultVersion, error) {
+	return &ResultVersion{
+		Version:       version.TMCoreSemVer,
+		ABCIVersion:   version.ABCISemVer,
+		BlockProtocol: version.BlockProtocol,
+		GitCommit:     gitCommit(),
+		GoVersion:     runtime.Version(),
+		Features:      env.features(),
+	}, nil
+}
+
+// gitCommit returns the commit hash set at build time with the linker flags
+// of the Makefile, or else the revision recorded by the Go toolchain.
+func gitCommit() string {
+	if version.TMGitCommitHash != "" {
+		return version.TMGitCommitHash
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+	return ""
+}
+
+// features returns the names of the optional features enabled on env.
+func (env *environment) features() []string {
+	enabled := map[string]bool{
+		"tls":                      env.Config.IsTLSEnabled(),
+		"metrics":                  env.metricsEnabled,
+		"indexer_timeout":          env.indexerTimeout > 0,
+		"indexer_circuit_breaker":  env.breakerMaxFailures > 0,
+		"response_budget":          env.maxInFlightBytes > 0,
+		"store_unavailable":        env.isStoreUnavailable != nil,
+		"store_read_retry":         env.isTransient != nil && env.readRetries > 0,
+		"search_limit":             env.maxConcurrentSearches > 0,
+		"search_page_limit":        env.maxSearchPage > 0,
+		"query_operator_allowlist": len(env.Config.AllowedQueryOperators) > 0,
+		"query_complexity_limit":   env.maxQueryComplexity > 0,
+		"event_sink_fallback":      len(env.eventSinks) > 1,
+		"validator_set_cache":      env.validatorSetCacheSize > 0,
+		"route_cache":              routeCachesEnabled(env.Config.RouteCaches),
+		"request_coalescing":       env.coalesceRequests,
+		"commit_signature_limit":   env.maxCommitSignatures != nil,
+		"block_events_limit":       env.maxBlockEvents != nil,
+		"max_block_age":            env.maxBlockAge > 0,
+	}
+	features := make([]string, 0, len(enabled))
+	for feature, ok := range enabled {
+		if ok {
+			features = append(features, feature)
+		}
+	}
+	sort.Strings(features)
+	return features
+}
@@ -0,0 +1,27 @@
+package rpc
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	statemocks "github.com/cometbft/cometbft/state/mocks"
+	"github.com/cometbft/cometbft/version"
+)
+
+func TestVersion(t *testing.T) {
+	env := newTestEnvironment(&statemocks.BlockStore{}, &statemocks.Store{}, MaxConcurrentSearches(0))
+	res, err := env.Version(nil)
+	require.NoError(t, err)
+	require.Equal(t, version.TMCoreSemVer, res.Version)
+	require.Equal(t, runtime.Version(), res.GoVersion)
+	require.Empty(t, res.Features)
+
+	env = newTestEnvironment(&statemocks.BlockStore{}, &statemocks.Store{},
+		WithMetrics(NopMetrics()), IndexerTimeout(time.Second))
+	res, err = env.Version(nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"indexer_timeout", "metrics", "search_limit"}, res.Features)
+}
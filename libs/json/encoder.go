@@ -21,7 +21,7 @@ var (
 // 64-bit numbers, and type wrappers for registered types).
 func Marshal(v interface{}) ([]byte, error) {
 	buf := new(bytes.Buffer)
-	err := encode(buf, v)
+	err := NewEncoder(buf).Encode(v)
 	if err != nil {
 		return nil, err
 	}
@@ -42,10 +42,31 @@ func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-func encode(w io.Writer, v interface{}) error {
+// Encoder writes the JSON encoding of values to a writer, as Marshal encodes them. The values
+// are written as they are encoded, rather than encoded at once, so that large values are not held
+// in memory.
+type Encoder struct {
+	w           io.Writer
+	marshalTime func(time.Time) ([]byte, error)
+}
+
+// NewEncoder returns an encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// SetTimeMarshaler makes the encoder encode time.Time values, converted to UTC, with f rather
+// than as RFC3339 strings. The times encoded by a json.Marshaler are not affected.
+func (e *Encoder) SetTimeMarshaler(f func(time.Time) ([]byte, error)) {
+	e.marshalTime = f
+}
+
+// Encode writes the JSON encoding of v. Once writing started, an error leaves the output
+// truncated.
+func (e *Encoder) Encode(v interface{}) error {
 	// Bare nil values can't be reflected, so we must handle them here.
 	if v == nil {
-		return writeStr(w, "null")
+		return writeStr(e.w, "null")
 	}
 	rv := reflect.ValueOf(v)
 
@@ -54,13 +75,13 @@ func encode(w io.Writer, v interface{}) error {
 	// behavior in structs where an interface field will get the type wrapper while a bare value
 	// field will not.
 	if typeRegistry.name(rv.Type()) != "" {
-		return encodeReflectInterface(w, rv)
+		return e.encodeReflectInterface(rv)
 	}
 
-	return encodeReflect(w, rv)
+	return e.encodeReflect(rv)
 }
 
-func encodeReflect(w io.Writer, rv reflect.Value) error {
+func (e *Encoder) encodeReflect(rv reflect.Value) error {
 	if !rv.IsValid() {
 		return errors.New("invalid reflect value")
 	}
@@ -68,57 +89,66 @@ func encodeReflect(w io.Writer, rv reflect.Value) error {
 	// Recursively dereference if pointer.
 	for rv.Kind() == reflect.Ptr {
 		if rv.IsNil() {
-			return writeStr(w, "null")
+			return writeStr(e.w, "null")
 		}
 		rv = rv.Elem()
 	}
 
 	// Convert times to UTC.
 	if rv.Type() == timeType {
-		rv = reflect.ValueOf(rv.Interface().(time.Time).Round(0).UTC())
+		t := rv.Interface().(time.Time).Round(0).UTC()
+		if e.marshalTime != nil {
+			bz, err := e.marshalTime(t)
+			if err != nil {
+				return err
+			}
+			_, err = e.w.Write(bz)
+			return err
+		}
+		rv = reflect.ValueOf(t)
 	}
 
 	// If the value implements json.Marshaler, defer to stdlib directly. Since we've already
 	// dereferenced, we try implementations with both value receiver and pointer receiver. We must
 	// do this after the time normalization above, and thus after dereferencing.
 	if rv.Type().Implements(jsonMarshalerType) {
-		return encodeStdlib(w, rv.Interface())
+		return encodeStdlib(e.w, rv.Interface())
 	} else if rv.CanAddr() && rv.Addr().Type().Implements(jsonMarshalerType) {
-		return encodeStdlib(w, rv.Addr().Interface())
+		return encodeStdlib(e.w, rv.Addr().Interface())
 	}
 
 	switch rv.Type().Kind() {
 	// Complex types must be recursively encoded.
 	case reflect.Interface:
-		return encodeReflectInterface(w, rv)
+		return e.encodeReflectInterface(rv)
 
 	case reflect.Array, reflect.Slice:
-		return encodeReflectList(w, rv)
+		return e.encodeReflectList(rv)
 
 	case reflect.Map:
-		return encodeReflectMap(w, rv)
+		return e.encodeReflectMap(rv)
 
 	case reflect.Struct:
-		return encodeReflectStruct(w, rv)
+		return e.encodeReflectStruct(rv)
 
 	// 64-bit integers are emitted as strings, to avoid precision problems with e.g.
 	// Javascript which uses 64-bit floats (having 53-bit precision).
 	case reflect.Int64, reflect.Int:
-		return writeStr(w, `"`+strconv.FormatInt(rv.Int(), 10)+`"`)
+		return writeStr(e.w, `"`+strconv.FormatInt(rv.Int(), 10)+`"`)
 
 	case reflect.Uint64, reflect.Uint:
-		return writeStr(w, `"`+strconv.FormatUint(rv.Uint(), 10)+`"`)
+		return writeStr(e.w, `"`+strconv.FormatUint(rv.Uint(), 10)+`"`)
 
 	// For everything else, defer to the stdlib encoding/json encoder
 	default:
-		return encodeStdlib(w, rv.Interface())
+		return encodeStdlib(e.w, rv.Interface())
 	}
 }
 
-func encodeReflectList(w io.Writer, rv reflect.Value) error {
+func (e *Encoder) encodeReflectList(rv reflect.Value) error {
 	// Emit nil slices as null.
 	if rv.Kind() == reflect.Slice && rv.IsNil() {
-		return writeStr(w, "null")
+		return writeStr(e.w, "null")
 	}
 
 	// Encode byte slices as base64 with the stdlib encoder.
@@ -129,61 +159,61 @@ func encodeReflectList(w io.Writer, rv reflect.Value) error {
 			reflect.Copy(slice, rv)
 			rv = slice
 		}
-		return encodeStdlib(w, rv.Interface())
+		return encodeStdlib(e.w, rv.Interface())
 	}
 
 	// Anything else we recursively encode ourselves.
 	length := rv.Len()
-	if err := writeStr(w, "["); err != nil {
+	if err := writeStr(e.w, "["); err != nil {
 		return err
 	}
 	for i := 0; i < length; i++ {
-		if err := encodeReflect(w, rv.Index(i)); err != nil {
+		if err := e.encodeReflect(rv.Index(i)); err != nil {
 			return err
 		}
 		if i < length-1 {
-			if err := writeStr(w, ","); err != nil {
+			if err := writeStr(e.w, ","); err != nil {
 				return err
 			}
 		}
 	}
-	return writeStr(w, "]")
+	return writeStr(e.w, "]")
 }
 
-func encodeReflectMap(w io.Writer, rv reflect.Value) error {
+func (e *Encoder) encodeReflectMap(rv reflect.Value) error {
 	if rv.Type().Key().Kind() != reflect.String {
 		return errors.New("map key must be string")
 	}
 
 	// nil maps are not emitted as nil, to retain Amino compatibility.
 
-	if err := writeStr(w, "{"); err != nil {
+	if err := writeStr(e.w, "{"); err != nil {
 		return err
 	}
 	writeComma := false
 	for _, keyrv := range rv.MapKeys() {
 		if writeComma {
-			if err := writeStr(w, ","); err != nil {
+			if err := writeStr(e.w, ","); err != nil {
 				return err
 			}
 		}
-		if err := encodeStdlib(w, keyrv.Interface()); err != nil {
+		if err := encodeStdlib(e.w, keyrv.Interface()); err != nil {
 			return err
 		}
-		if err := writeStr(w, ":"); err != nil {
+		if err := writeStr(e.w, ":"); err != nil {
 			return err
 		}
-		if err := encodeReflect(w, rv.MapIndex(keyrv)); err != nil {
+		if err := e.encodeReflect(rv.MapIndex(keyrv)); err != nil {
 			return err
 		}
 		writeComma = true
 	}
-	return writeStr(w, "}")
+	return writeStr(e.w, "}")
 }
 
-func encodeReflectStruct(w io.Writer, rv reflect.Value) error {
+func (e *Encoder) encodeReflectStruct(rv reflect.Value) error {
 	sInfo := makeStructInfo(rv.Type())
-	if err := writeStr(w, "{"); err != nil {
+	if err := writeStr(e.w, "{"); err != nil {
 		return err
 	}
 	writeComma := false
@@ -194,29 +224,29 @@ func encodeReflectStruct(w io.Writer, rv reflect.Value) error {
 		}
 
 		if writeComma {
-			if err := writeStr(w, ","); err != nil {
+			if err := writeStr(e.w, ","); err != nil {
 				return err
 			}
 		}
-		if err := encodeStdlib(w, fInfo.jsonName); err != nil {
+		if err := encodeStdlib(e.w, fInfo.jsonName); err != nil {
 			return err
 		}
-		if err := writeStr(w, ":"); err != nil {
+		if err := writeStr(e.w, ":"); err != nil {
 			return err
 		}
-		if err := encodeReflect(w, frv); err != nil {
+		if err := e.encodeReflect(frv); err != nil {
 			return err
 		}
 		writeComma = true
 	}
-	return writeStr(w, "}")
+	return writeStr(e.w, "}")
 }
 
-func encodeReflectInterface(w io.Writer, rv reflect.Value) error {
+func (e *Encoder) encodeReflectInterface(rv reflect.Value) error {
 	// Get concrete value and dereference pointers.
 	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
 		if rv.IsNil() {
-			return writeStr(w, "null")
+			return writeStr(e.w, "null")
 		}
 		rv = rv.Elem()
 	}
@@ -228,13 +258,13 @@ func encodeReflectInterface(w io.Writer, rv reflect.Value) error {
 	}
 
 	// Write value wrapped in interface envelope
-	if err := writeStr(w, fmt.Sprintf(`{"type":%q,"value":`, name)); err != nil {
+	if err := writeStr(e.w, fmt.Sprintf(`{"type":%q,"value":`, name)); err != nil {
 		return err
 	}
-	if err := encodeReflect(w, rv); err != nil {
+	if err := e.encodeReflect(rv); err != nil {
 		return err
 	}
-	return writeStr(w, "}")
+	return writeStr(e.w, "}")
 }
 
 func encodeStdlib(w io.Writer, v interface{}) error {
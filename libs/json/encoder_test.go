@@ -1,6 +1,8 @@
 package json_test
 
 import (
+	"bytes"
+	"strconv"
 	"testing"
 	"time"
 
@@ -102,3 +104,27 @@ func TestMarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestEncoderTimeMarshaler(t *testing.T) {
+	ti := time.Date(2020, 6, 2, 18, 5, 13, 4346374, time.FixedZone("UTC+2", 2*60*60))
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetTimeMarshaler(func(t time.Time) ([]byte, error) {
+		return []byte(strconv.FormatInt(t.Unix(), 10) + "." + t.Format("15")), nil
+	})
+	err := enc.Encode(&Struct{Time: ti, Vehicles: []Vehicle{&Car{Wheels: 4}}, Child: &Struct{}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"Bool":false, "Float64":0, "Int32":0, "Int64":"0", "Int64Ptr":null,
+		"String":"", "StringPtrPtr":null, "Bytes":null,
+		"Time":1591113913.16,
+		"Car":null, "Boat":{"Sail":false},
+		"Vehicles":[{"type":"vehicle/car","value":{"Wheels":4}}],
+		"Child":{
+			"Bool":false, "Float64":0, "Int32":0, "Int64":"0", "Int64Ptr":null,
+			"String":"", "StringPtrPtr":null, "Bytes":null,
+			"Time":-62135596800.00,
+			"Car":null, "Boat":{"Sail":false}, "Vehicles":null, "Child":null
+		}
+	}`, buf.String())
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -20,6 +21,9 @@ func testMux() *http.ServeMux {
 	funcMap := map[string]*RPCFunc{
 		"c":     NewRPCFunc(func(ctx *types.Context, s string, i int) (string, error) { return "foo", nil }, "s,i"),
 		"block": NewRPCFunc(func(ctx *types.Context, h int) (string, error) { return "block", nil }, "height", Cacheable("height")),
+		"unavailable": NewRPCFunc(func(ctx *types.Context) (string, error) {
+			return "", fmt.Errorf("reading block: %w", &types.RPCError{Code: -32001, Message: "Unavailable", Data: "busy"})
+		}, ""),
 	}
 	mux := http.NewServeMux()
 	buf := new(bytes.Buffer)
@@ -276,3 +280,19 @@ func TestRPCResponseCache(t *testing.T) {
 	res.Body.Close()
 	require.Nil(t, err, "reading from the body should not give back an error")
 }
+
+func TestRPCErrorCode(t *testing.T) {
+	mux := testMux()
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"jsonrpc":"2.0","method":"unavailable","id":0}`)),
+		httptest.NewRequest(http.MethodGet, "/unavailable", nil),
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		// Errors wrapping an *RPCError are reported with its code.
+		var res types.RPCResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		require.Equal(t, &types.RPCError{Code: -32001, Message: "Unavailable", Data: "busy"}, res.Error)
+	}
+}
@@ -143,6 +143,9 @@ func funcReturnTypes(f interface{}) []reflect.Type {
 func unreflectResult(returns []reflect.Value) (interface{}, error) {
 	errV := returns[1]
 	if errV.Interface() != nil {
+		if err, ok := errV.Interface().(error); ok {
+			return nil, err
+		}
 		return nil, fmt.Errorf("%v", errV.Interface())
 	}
 	rv := returns[0]
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"reflect"
 	"runtime/debug"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -24,8 +27,16 @@ const (
 	defaultWSWriteWait         = 10 * time.Second
 	defaultWSReadWait          = 30 * time.Second
 	defaultWSPingPeriod        = (defaultWSReadWait * 9) / 10
+
+	// maxCloseReasonBytes is the maximum length of the reason of a close
+	// message, whose payload is limited to 125 bytes including the status.
+	maxCloseReasonBytes = 123
 )
 
+// errMessageTooBig is returned by the readers of messages exceeding the read
+// limit of a connection.
+var errMessageTooBig = errors.New("message exceeds the read limit")
+
 // WebsocketManager provides a WS handler for incoming connections and passes a
 // map of functions along with any additional params to new connections.
 // NOTE: The websocket path is defined externally, e.g. in node/node.go
@@ -132,14 +143,34 @@ type wsConnection struct {
 
 	// Maximum message size.
 	readLimit int64
+	// Reason of the close message sent to clients whose messages exceed
+	// readLimit. If empty, oversized messages are refused by the websocket
+	// library, which sends a close message without a reason.
+	readLimitReason string
+
+	// Connection is closed if no application data was sent or received in
+	// this long. Zero disables the timeout.
+	idleTimeout time.Duration
+	// lastActivity is the time, in Unix nanoseconds, at which application
+	// data was last sent or received.
+	lastActivity atomic.Int64
 
 	// callback which is called upon disconnect
 	onDisconnect func(remoteAddr string)
 
+	// If set, the connection is closed when writeChan is full, rather than
+	// blocking the writers, and onSlowClient is called.
+	onSlowClient    func(remoteAddr string)
+	closeSlowClient sync.Once
+
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
+// WSConnectionOption sets an optional parameter on the websocket connections,
+// such as ReadLimit, for use by the callers building a list of options.
+type WSConnectionOption = func(*wsConnection)
+
 // NewWSConnection wraps websocket.Conn.
 //
 // See the commentary on the func(*wsConnection) functions for a detailed
@@ -164,7 +195,9 @@ func newWSConnection(
 	for _, option := range options {
 		option(wsc)
 	}
-	wsc.baseConn.SetReadLimit(wsc.readLimit)
+	if wsc.readLimitReason == "" {
+		wsc.baseConn.SetReadLimit(wsc.readLimit)
+	}
 	wsc.BaseService = *service.NewBaseService(nil, "wsConnection", wsc)
 	return wsc
 }
@@ -177,6 +210,18 @@ func OnDisconnect(onDisconnect func(remoteAddr string)) func(*wsConnection) {
 	}
 }
 
+// CloseSlowClients closes the connections whose write channel is full, with a
+// close message with status 1011 (internal error), instead of blocking the
+// writers until the client reads the responses. onClose is called with the
+// address of the closed connections. The capacity of the write channel is set
+// with WriteChanCapacity.
+// It should only be used in the constructor - not Goroutine-safe.
+func CloseSlowClients(onClose func(remoteAddr string)) func(*wsConnection) {
+	return func(wsc *wsConnection) {
+		wsc.onSlowClient = onClose
+	}
+}
+
 // WriteWait sets the amount of time to wait before a websocket write times out.
 // It should only be used in the constructor - not Goroutine-safe.
 func WriteWait(writeWait time.Duration) func(*wsConnection) {
@@ -217,10 +262,35 @@ func ReadLimit(readLimit int64) func(*wsConnection) {
 	}
 }
 
+// ReadLimitReason sets the reason of the close message, with status 1009
+// (message too big), sent to clients whose messages exceed the read limit.
+// Reasons longer than the 123 bytes a close message can hold are truncated.
+// It should only be used in the constructor - not Goroutine-safe.
+func ReadLimitReason(reason string) func(*wsConnection) {
+	return func(wsc *wsConnection) {
+		if len(reason) > maxCloseReasonBytes {
+			reason = reason[:maxCloseReasonBytes]
+		}
+		wsc.readLimitReason = reason
+	}
+}
+
+// IdleTimeout sets the amount of time after which a connection which has not
+// sent or received application data is closed. Ping and pong messages do not
+// count as application data: they keep alive connections from reaching the
+// read timeout, but not from reaching the idle timeout. Disabled by default.
+// It should only be used in the constructor - not Goroutine-safe.
+func IdleTimeout(idleTimeout time.Duration) func(*wsConnection) {
+	return func(wsc *wsConnection) {
+		wsc.idleTimeout = idleTimeout
+	}
+}
+
 // OnStart implements service.Service by starting the read and write routines. It
 // blocks until there's some error.
 func (wsc *wsConnection) OnStart() error {
 	wsc.writeChan = make(chan types.RPCResponse, wsc.writeChanCapacity)
+	wsc.touch()
 
 	// Read subscriptions/unsubscriptions to events
 	go wsc.readRoutine()
@@ -252,6 +322,17 @@ func (wsc *wsConnection) GetRemoteAddr() string {
 // accepted.
 // It implements WSRPCConnection. It is Goroutine-safe.
 func (wsc *wsConnection) WriteRPCResponse(ctx context.Context, resp types.RPCResponse) error {
+	if wsc.onSlowClient != nil {
+		select {
+		case <-wsc.Quit():
+			return errors.New("connection was stopped")
+		case wsc.writeChan <- resp:
+			return nil
+		default:
+			wsc.closeSlowClient.Do(wsc.closeSlow)
+			return errors.New("write channel is full, connection was closed")
+		}
+	}
 	select {
 	case <-wsc.Quit():
 		return errors.New("connection was stopped")
@@ -333,9 +414,19 @@ func (wsc *wsConnection) readRoutine() {
 				return
 			}
 
+			wsc.touch()
+
+			if wsc.readLimitReason != "" && wsc.readLimit > 0 {
+				r = &limitedMessageReader{r: r, remaining: wsc.readLimit}
+			}
 			dec := json.NewDecoder(r)
 			var request types.RPCRequest
 			err = dec.Decode(&request)
+			if errors.Is(err, errMessageTooBig) {
+				wsc.closeMessageTooBig()
+				close(wsc.readRoutineQuit)
+				return
+			}
 			if err != nil {
 				if err := wsc.WriteRPCResponse(writeCtx,
 					types.RPCParseError(fmt.Errorf("error unmarshaling request: %w", err))); err != nil {
@@ -385,7 +476,7 @@ func (wsc *wsConnection) readRoutine() {
 
 			result, err := unreflectResult(returns)
 			if err != nil {
-				if err := wsc.WriteRPCResponse(writeCtx, types.RPCInternalError(request.ID, err)); err != nil {
+				if err := wsc.WriteRPCResponse(writeCtx, types.RPCErrorResponseFromError(request.ID, err)); err != nil {
 					wsc.Logger.Error("Error writing RPC response", "err", err)
 				}
 				continue
@@ -398,11 +489,72 @@ func (wsc *wsConnection) readRoutine() {
 	}
 }
 
+// closeMessageTooBig sends a close message with status 1009 (message too big)
+// and the configured reason to the client, and stops the connection.
+func (wsc *wsConnection) closeMessageTooBig() {
+	wsc.Logger.Info("Closing connection: message exceeds the read limit", "limit", wsc.readLimit)
+	msg := websocket.FormatCloseMessage(websocket.CloseMessageTooBig, wsc.readLimitReason)
+	if err := wsc.baseConn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsc.writeWait)); err != nil {
+		wsc.Logger.Info("Failed to write close message", "err", err)
+	}
+	if err := wsc.Stop(); err != nil {
+		wsc.Logger.Error("Error closing websocket connection", "err", err)
+	}
+}
+
+// closeSlow sends a close message with status 1011 (internal error) to
+// a client which does not read its responses fast enough, and stops the
+// connection.
+func (wsc *wsConnection) closeSlow() {
+	wsc.Logger.Info("Closing connection: write channel is full", "capacity", wsc.writeChanCapacity)
+	msg := websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "responses are not read fast enough")
+	if err := wsc.baseConn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsc.writeWait)); err != nil {
+		wsc.Logger.Info("Failed to write close message", "err", err)
+	}
+	if err := wsc.Stop(); err != nil {
+		wsc.Logger.Error("Error closing websocket connection", "err", err)
+		return
+	}
+	wsc.onSlowClient(wsc.remoteAddr)
+}
+
+// limitedMessageReader reads a message, returning errMessageTooBig once more
+// than remaining bytes were read.
+type limitedMessageReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedMessageReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, errMessageTooBig
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return 0, errMessageTooBig
+	}
+	return n, err
+}
+
 // receives on a write channel and writes out on the socket
 func (wsc *wsConnection) writeRoutine() {
 	pingTicker := time.NewTicker(wsc.pingPeriod)
 	defer pingTicker.Stop()
 
+	var (
+		idleTimer   *time.Timer
+		idleTimeout <-chan time.Time // nil if the idle timeout is disabled
+	)
+	if wsc.idleTimeout > 0 {
+		idleTimer = time.NewTimer(wsc.idleTimeout)
+		defer idleTimer.Stop()
+		idleTimeout = idleTimer.C
+	}
+
 	// https://github.com/gorilla/websocket/issues/97
 	pongs := make(chan string, 1)
 	wsc.baseConn.SetPingHandler(func(m string) error {
@@ -430,6 +582,19 @@ func (wsc *wsConnection) writeRoutine() {
 				wsc.Logger.Error("Failed to write ping", "err", err)
 				return
 			}
+		case <-idleTimeout:
+			idle := time.Since(time.Unix(0, wsc.lastActivity.Load()))
+			if idle < wsc.idleTimeout {
+				idleTimer.Reset(wsc.idleTimeout - idle)
+				continue
+			}
+			wsc.Logger.Info("Closing idle connection", "idle", idle)
+			msg := websocket.FormatCloseMessage(websocket.CloseGoingAway,
+				fmt.Sprintf("no application data for %s", wsc.idleTimeout))
+			if err := wsc.writeMessageWithDeadline(websocket.CloseMessage, msg); err != nil {
+				wsc.Logger.Info("Failed to write close message", "err", err)
+			}
+			return
 		case msg := <-wsc.writeChan:
 			// Use json.MarshalIndent instead of Marshal for pretty output.
 			// Pretty output not necessary, since most consumers of WS events are
@@ -443,10 +608,16 @@ func (wsc *wsConnection) writeRoutine() {
 				wsc.Logger.Error("Failed to write response", "err", err, "msg", msg)
 				return
 			}
+			wsc.touch()
 		}
 	}
 }
 
+// touch records that application data was sent or received.
+func (wsc *wsConnection) touch() {
+	wsc.lastActivity.Store(time.Now().UnixNano())
+}
+
 // All writes to the websocket must (re)set the write deadline.
 // If some writes don't set it while others do, they may timeout incorrectly
 // (https://github.com/tendermint/tendermint/issues/553)
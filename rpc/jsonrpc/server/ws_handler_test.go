@@ -1,9 +1,12 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/require"
@@ -42,11 +45,128 @@ func TestWebsocketManagerHandler(t *testing.T) {
 	dialResp.Body.Close()
 }
 
-func newWSServer() *httptest.Server {
+func TestWebsocketRPCError(t *testing.T) {
+	s := newWSServer()
+	defer s.Close()
+
+	c, dialResp, err := websocket.DefaultDialer.Dial("ws://"+s.Listener.Addr().String()+"/websocket", nil)
+	require.NoError(t, err)
+	defer dialResp.Body.Close()
+	defer c.Close()
+
+	// Errors wrapping an *RPCError are reported with its code.
+	require.NoError(t, c.WriteJSON(types.RPCRequest{JSONRPC: "2.0", ID: types.JSONRPCIntID(1), Method: "unavailable"}))
+	var resp types.RPCResponse
+	require.NoError(t, c.ReadJSON(&resp))
+	require.Equal(t, &types.RPCError{Code: -32001, Message: "Unavailable", Data: "busy"}, resp.Error)
+}
+
+func TestWebsocketIdleTimeout(t *testing.T) {
+	s := newWSServer(IdleTimeout(200 * time.Millisecond))
+	defer s.Close()
+
+	c, dialResp, err := websocket.DefaultDialer.Dial("ws://"+s.Listener.Addr().String()+"/websocket", nil)
+	require.NoError(t, err)
+	defer dialResp.Body.Close()
+	defer c.Close()
+
+	// Requests keep the connection open past the idle timeout.
+	req, err := types.MapToRequest(types.JSONRPCStringID("idle"), "c", map[string]interface{}{"s": "a", "i": 10})
+	require.NoError(t, err)
+	start := time.Now()
+	for time.Since(start) < 400*time.Millisecond {
+		require.NoError(t, c.WriteJSON(req))
+		var resp types.RPCResponse
+		require.NoError(t, c.ReadJSON(&resp))
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// Pings do not.
+	require.NoError(t, c.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)))
+	require.NoError(t, c.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err = c.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+}
+
+func TestWebsocketReadLimitReason(t *testing.T) {
+	s := newWSServer(ReadLimit(128), ReadLimitReason("message exceeds the limit of 128 bytes"))
+	defer s.Close()
+
+	c, dialResp, err := websocket.DefaultDialer.Dial("ws://"+s.Listener.Addr().String()+"/websocket", nil)
+	require.NoError(t, err)
+	defer dialResp.Body.Close()
+	defer c.Close()
+
+	// Messages within the limit are served.
+	req, err := types.MapToRequest(types.JSONRPCStringID("small"), "c", map[string]interface{}{"s": "a", "i": 10})
+	require.NoError(t, err)
+	require.NoError(t, c.WriteJSON(req))
+	var resp types.RPCResponse
+	require.NoError(t, c.ReadJSON(&resp))
+	require.Nil(t, resp.Error)
+
+	req, err = types.MapToRequest(types.JSONRPCStringID("big"), "c",
+		map[string]interface{}{"s": strings.Repeat("a", 200), "i": 10})
+	require.NoError(t, err)
+	require.NoError(t, c.WriteJSON(req))
+	require.NoError(t, c.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err = c.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	require.Equal(t, websocket.CloseMessageTooBig, closeErr.Code)
+	require.Equal(t, "message exceeds the limit of 128 bytes", closeErr.Text)
+}
+
+func TestWebsocketCloseSlowClients(t *testing.T) {
+	closed := make(chan string, 1)
+	funcMap := map[string]*RPCFunc{
+		"big": NewWSRPCFunc(func(ctx *types.Context) (string, error) { return strings.Repeat("a", 1<<20), nil }, ""),
+	}
+	wm := NewWebsocketManager(funcMap, WriteChanCapacity(1), CloseSlowClients(func(remoteAddr string) {
+		closed <- remoteAddr
+	}))
+	wm.SetLogger(log.TestingLogger())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/websocket", wm.WebsocketHandler)
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	c, dialResp, err := websocket.DefaultDialer.Dial("ws://"+s.Listener.Addr().String()+"/websocket", nil)
+	require.NoError(t, err)
+	defer dialResp.Body.Close()
+	defer c.Close()
+
+	// Send requests without reading the responses, until the server gives
+	// up on the client.
+	req, err := types.MapToRequest(types.JSONRPCStringID("big"), "big", map[string]interface{}{})
+	require.NoError(t, err)
+	for i := 0; i < 64; i++ {
+		require.NoError(t, c.WriteJSON(req))
+	}
+
+	require.NoError(t, c.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		_, _, err = c.ReadMessage()
+		if err != nil {
+			break
+		}
+	}
+	require.True(t, websocket.IsCloseError(err, websocket.CloseInternalServerErr), "unexpected error: %v", err)
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow client was not reported")
+	}
+}
+
+func newWSServer(options ...func(*wsConnection)) *httptest.Server {
 	funcMap := map[string]*RPCFunc{
 		"c": NewWSRPCFunc(func(ctx *types.Context, s string, i int) (string, error) { return "foo", nil }, "s,i"),
+		"unavailable": NewWSRPCFunc(func(ctx *types.Context) (string, error) {
+			return "", fmt.Errorf("reading block: %w", &types.RPCError{Code: -32001, Message: "Unavailable", Data: "busy"})
+		}, ""),
 	}
-	wm := NewWebsocketManager(funcMap)
+	wm := NewWebsocketManager(funcMap, options...)
 	wm.SetLogger(log.TestingLogger())
 
 	mux := http.NewServeMux()
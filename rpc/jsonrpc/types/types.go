@@ -3,6 +3,7 @@ package types
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -246,6 +247,17 @@ func RPCServerError(id jsonrpcid, err error) RPCResponse {
 	return NewRPCErrorResponse(id, -32000, "Server error", err.Error())
 }
 
+// RPCErrorResponseFromError returns the response to a request whose handler
+// failed with err. If err is, or wraps, an *RPCError, its code, message and
+// data are used; any other error is reported as an internal error.
+func RPCErrorResponseFromError(id jsonrpcid, err error) RPCResponse {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return NewRPCErrorResponse(id, rpcErr.Code, rpcErr.Message, rpcErr.Data)
+	}
+	return RPCInternalError(id, err)
+}
+
 //----------------------------------------
 
 // WSRPCConnection represents a websocket connection.
@@ -81,3 +81,17 @@ func TestRPCError(t *testing.T) {
 			Message: "Badness",
 		}))
 }
+
+func TestRPCErrorResponseFromError(t *testing.T) {
+	id := JSONRPCIntID(1)
+
+	res := RPCErrorResponseFromError(id, fmt.Errorf("wrapped: %w", &RPCError{
+		Code:    -32001,
+		Message: "Service unavailable",
+		Data:    "store is locked",
+	}))
+	assert.Equal(t, &RPCError{Code: -32001, Message: "Service unavailable", Data: "store is locked"}, res.Error)
+
+	res = RPCErrorResponseFromError(id, errors.New("failure"))
+	assert.Equal(t, &RPCError{Code: -32603, Message: "Internal error", Data: "failure"}, res.Error)
+}
@@ -376,6 +376,13 @@ func TxResultsHash(txResults []*abci.ExecTxResult) []byte {
 	return types.NewResults(txResults).Hash()
 }
 
+// PersistsFinalizeBlockResponses reports whether the store persists the
+// FinalizeBlock responses of every height, as loaded by
+// LoadFinalizeBlockResponse, rather than only the latest one.
+func (store dbStore) PersistsFinalizeBlockResponses() bool {
+	return !store.DiscardABCIResponses
+}
+
 // LoadFinalizeBlockResponse loads the DiscardABCIResponses for the given height from the
 // database. If the node has D set to true, ErrABCIResponsesNotPersisted
 // is persisted. If not found, ErrNoABCIResponsesForHeight is returned.
@@ -612,19 +619,37 @@ func (store dbStore) saveValidatorsInfo(height, lastHeightChanged int64, valSet
 
 // LoadConsensusParams loads the ConsensusParams for a given height.
 func (store dbStore) LoadConsensusParams(height int64) (types.ConsensusParams, error) {
+	params, _, err := store.loadConsensusParams(height)
+	return params, err
+}
+
+// LoadConsensusParamsAndChangeHeight loads the ConsensusParams for a given
+// height along with the height at which they were last changed. Unlike
+// LoadConsensusParams, it returns ErrNoConsensusParamsForHeight if nothing is
+// stored for the height, such as when it was pruned.
+func (store dbStore) LoadConsensusParamsAndChangeHeight(height int64) (types.ConsensusParams, int64, error) {
+	params, changed, err := store.loadConsensusParams(height)
+	var errEmpty errEmptyConsensusParamsInfo
+	if errors.As(err, &errEmpty) && errEmpty.height == height {
+		return params, 0, ErrNoConsensusParamsForHeight{Height: height}
+	}
+	return params, changed, err
+}
+
+func (store dbStore) loadConsensusParams(height int64) (types.ConsensusParams, int64, error) {
 	var (
 		empty   = types.ConsensusParams{}
 		emptypb = cmtproto.ConsensusParams{}
 	)
 	paramsInfo, err := store.loadConsensusParamsInfo(height)
 	if err != nil {
-		return empty, fmt.Errorf("could not find consensus params for height #%d: %w", height, err)
+		return empty, 0, fmt.Errorf("could not find consensus params for height #%d: %w", height, err)
 	}
 
 	if paramsInfo.ConsensusParams.Equal(&emptypb) {
 		paramsInfo2, err := store.loadConsensusParamsInfo(paramsInfo.LastHeightChanged)
 		if err != nil {
-			return empty, fmt.Errorf(
+			return empty, 0, fmt.Errorf(
 				"couldn't find consensus params at height %d as last changed from height %d: %w",
 				paramsInfo.LastHeightChanged,
 				height,
@@ -635,7 +660,17 @@ func (store dbStore) LoadConsensusParams(height int64) (types.ConsensusParams, e
 		paramsInfo = paramsInfo2
 	}
 
-	return types.ConsensusParamsFromProto(paramsInfo.ConsensusParams), nil
+	return types.ConsensusParamsFromProto(paramsInfo.ConsensusParams), paramsInfo.LastHeightChanged, nil
+}
+
+// errEmptyConsensusParamsInfo is returned by loadConsensusParamsInfo if
+// nothing is stored for the height.
+type errEmptyConsensusParamsInfo struct {
+	height int64
+}
+
+func (errEmptyConsensusParamsInfo) Error() string {
+	return "value retrieved from db is empty"
 }
 
 func (store dbStore) loadConsensusParamsInfo(height int64) (*cmtstate.ConsensusParamsInfo, error) {
@@ -644,7 +679,7 @@ func (store dbStore) loadConsensusParamsInfo(height int64) (*cmtstate.ConsensusP
 		return nil, err
 	}
 	if len(buf) == 0 {
-		return nil, errors.New("value retrieved from db is empty")
+		return nil, errEmptyConsensusParamsInfo{height: height}
 	}
 
 	paramsInfo := new(cmtstate.ConsensusParamsInfo)
@@ -200,7 +200,16 @@ func TestPruneStates(t *testing.T) {
 					require.NotEmpty(t, params)
 				} else {
 					require.Error(t, err, "params height %v", h)
+					require.EqualError(t, err, fmt.Sprintf(
+						"could not find consensus params for height #%d: value retrieved from db is empty", h))
 					require.Empty(t, params)
+
+					// LoadConsensusParamsAndChangeHeight reports the
+					// missing params with their height.
+					_, _, err = stateStore.(interface {
+						LoadConsensusParamsAndChangeHeight(int64) (types.ConsensusParams, int64, error)
+					}).LoadConsensusParamsAndChangeHeight(h)
+					require.Equal(t, sm.ErrNoConsensusParamsForHeight{Height: h}, err)
 				}
 
 				abci, err := stateStore.LoadFinalizeBlockResponse(h)
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -215,9 +216,6 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*abci.TxResul
 	default:
 	}
 
-	var hashesInitialized bool
-	filteredHashes := make(map[string][]byte)
-
 	// get a list of conditions (like "tx.height > 5")
 	conditions := q.Syntax()
 
@@ -237,6 +235,39 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*abci.TxResul
 		}
 	}
 
+	filteredHashes := txi.matchHashes(ctx, conditions)
+
+	results := make([]*abci.TxResult, 0, len(filteredHashes))
+	resultMap := make(map[string]struct{})
+RESULTS_LOOP:
+	for _, h := range filteredHashes {
+
+		res, err := txi.Get(h)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Tx{%X}: %w", h, err)
+		}
+		hashString := string(h)
+		if _, ok := resultMap[hashString]; !ok {
+			resultMap[hashString] = struct{}{}
+			results = append(results, res)
+		}
+		// Potentially exit early.
+		select {
+		case <-ctx.Done():
+			break RESULTS_LOOP
+		default:
+		}
+	}
+
+	return results, nil
+}
+
+// matchHashes returns the hashes of the transactions matching the conditions,
+// which do not include a hash condition, keyed by hash.
+func (txi *TxIndex) matchHashes(ctx context.Context, conditions []syntax.Condition) map[string][]byte {
+	var hashesInitialized bool
+	filteredHashes := make(map[string][]byte)
+
 	// conditions to skip because they're handled before "everything else"
 	skipIndexes := make([]int, 0)
 	var heightInfo HeightInfo
@@ -303,29 +334,120 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*abci.TxResul
 		}
 	}
 
-	results := make([]*abci.TxResult, 0, len(filteredHashes))
-	resultMap := make(map[string]struct{})
-RESULTS_LOOP:
-	for _, h := range filteredHashes {
+	return filteredHashes
+}
 
+// SearchIterator returns an iterator over the transactions matching q, as
+// Search, ordered by height and index, in descending order if desc is set.
+//
+// Unlike Search, which loads all the matching transactions, the iterator
+// only holds their hashes, heights and indexes, and loads the transactions
+// one at a time as it advances. The transactions are read twice: once to be
+// ordered, and once when the iterator reaches them. As the keys of the index
+// do not order the transactions by height, the whole index is searched
+// before the iterator is returned.
+//
+// The search stops early when ctx is done. Unlike Search, which returns the
+// transactions found so far, SearchIterator then returns the error of ctx, so
+// that a search cut short is not taken for a complete one.
+func (txi *TxIndex) SearchIterator(ctx context.Context, q *query.Query, desc bool) (*TxIterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	conditions := q.Syntax()
+	var filteredHashes map[string][]byte
+	hash, ok, err := lookForHash(conditions)
+	if err != nil {
+		return nil, fmt.Errorf("error during searching for a hash in the query: %w", err)
+	} else if ok {
+		filteredHashes = map[string][]byte{string(hash): hash}
+	} else {
+		filteredHashes = txi.matchHashes(ctx, conditions)
+	}
+
+	it := &TxIterator{txi: txi, positions: make([]txPosition, 0, len(filteredHashes))}
+	for _, h := range filteredHashes {
+		// Potentially exit early.
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		res, err := txi.Get(h)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get Tx{%X}: %w", h, err)
 		}
-		hashString := string(h)
-		if _, ok := resultMap[hashString]; !ok {
-			resultMap[hashString] = struct{}{}
-			results = append(results, res)
+		if res != nil {
+			it.positions = append(it.positions, txPosition{hash: h, height: res.Height, index: res.Index})
 		}
-		// Potentially exit early.
-		select {
-		case <-ctx.Done():
-			break RESULTS_LOOP
-		default:
+	}
+	// The matching of the hashes stops early as well.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(it.positions, func(i, j int) bool {
+		if desc {
+			i, j = j, i
+		}
+		a, b := it.positions[i], it.positions[j]
+		if a.height == b.height {
+			return a.index < b.index
+		}
+		return a.height < b.height
+	})
+	return it, nil
+}
+
+// txPosition is the position of a transaction in the blockchain.
+type txPosition struct {
+	hash   []byte
+	height int64
+	index  uint32
+}
+
+// TxIterator iterates over the results of a search, loading them one at a
+// time. See TxIndex.SearchIterator.
+type TxIterator struct {
+	txi       *TxIndex
+	positions []txPosition
+	next      int
+	result    *abci.TxResult
+	err       error
+}
+
+// Len returns the number of transactions matching the search.
+func (it *TxIterator) Len() int {
+	return len(it.positions)
+}
+
+// Next loads the next transaction, and returns false once all of them were
+// loaded, or if loading one failed.
+func (it *TxIterator) Next() bool {
+	it.result = nil
+	for it.err == nil && it.next < len(it.positions) {
+		pos := it.positions[it.next]
+		it.next++
+		res, err := it.txi.Get(pos.hash)
+		if err != nil {
+			it.err = fmt.Errorf("failed to get Tx{%X}: %w", pos.hash, err)
+			return false
+		}
+		// Skip the transactions removed since the search.
+		if res != nil {
+			it.result = res
+			return true
 		}
 	}
+	return false
+}
 
-	return results, nil
+// Result returns the transaction loaded by the last call to Next.
+func (it *TxIterator) Result() *abci.TxResult {
+	return it.result
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *TxIterator) Err() error {
+	return it.err
 }
 
 func lookForHash(conditions []syntax.Condition) (hash []byte, ok bool, err error) {
@@ -579,6 +579,68 @@ func TestTxSearchMultipleTxs(t *testing.T) {
 	require.Len(t, results, 3)
 }
 
+func TestTxSearchIterator(t *testing.T) {
+	indexer := NewTxIndex(db.NewMemDB())
+	for i, pos := range []struct {
+		height int64
+		index  uint32
+	}{{2, 0}, {1, 1}, {1, 0}, {3, 0}} {
+		txResult := txResultWithEvents([]abci.Event{
+			{Type: "account", Attributes: []abci.EventAttribute{{Key: "number", Value: "1", Index: true}}},
+		})
+		txResult.Tx = types.Tx(fmt.Sprintf("tx %d", i))
+		txResult.Height = pos.height
+		txResult.Index = pos.index
+		require.NoError(t, indexer.Index(txResult))
+	}
+
+	search := func(q string, desc bool) []string {
+		it, err := indexer.SearchIterator(context.Background(), query.MustCompile(q), desc)
+		require.NoError(t, err)
+		var txs []string
+		for it.Next() {
+			txs = append(txs, string(it.Result().Tx))
+		}
+		require.NoError(t, it.Err())
+		require.Equal(t, len(txs), it.Len())
+		return txs
+	}
+
+	// The transactions are ordered by height and index.
+	require.Equal(t, []string{"tx 2", "tx 1", "tx 0", "tx 3"}, search("account.number = 1", false))
+	require.Equal(t, []string{"tx 3", "tx 0", "tx 1", "tx 2"}, search("account.number = 1", true))
+	require.Equal(t, []string{"tx 0", "tx 3"}, search("account.number = 1 AND tx.height > 1", false))
+	require.Empty(t, search("account.number = 2", false))
+
+	hash := types.Tx("tx 1").Hash()
+	require.Equal(t, []string{"tx 1"}, search(fmt.Sprintf("tx.hash = '%X'", hash), false))
+
+	// The iterator matches the results of Search.
+	results, err := indexer.Search(context.Background(), query.MustCompile("account.number = 1 AND tx.height > 1"))
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+}
+
+func TestTxSearchIteratorCanceled(t *testing.T) {
+	indexer := NewTxIndex(db.NewMemDB())
+	txResult := txResultWithEvents([]abci.Event{
+		{Type: "account", Attributes: []abci.EventAttribute{{Key: "number", Value: "1", Index: true}}},
+	})
+	require.NoError(t, indexer.Index(txResult))
+
+	// A search cut short fails, rather than returning the results found so
+	// far.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := indexer.SearchIterator(ctx, query.MustCompile("account.number = 1"), false)
+	require.ErrorIs(t, err, context.Canceled)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	_, err = indexer.SearchIterator(ctx, query.MustCompile("account.number = 1"), false)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
 func txResultWithEvents(events []abci.Event) *abci.TxResult {
 	tx := types.Tx("HELLO WORLD")
 	return &abci.TxResult{
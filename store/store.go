@@ -242,6 +242,20 @@ func (bs *BlockStore) LoadBlockCommit(height int64) *types.Commit {
 	return commit
 }
 
+// LoadBlockCommitBytes returns the Protobuf encoding of the Commit for the
+// given height, as stored, without decoding it.
+// If no commit is found for the given height, it returns nil.
+func (bs *BlockStore) LoadBlockCommitBytes(height int64) []byte {
+	bz, err := bs.db.Get(calcBlockCommitKey(height))
+	if err != nil {
+		panic(err)
+	}
+	if len(bz) == 0 {
+		return nil
+	}
+	return bz
+}
+
 // LoadExtendedCommit returns the ExtendedCommit for the given height.
 // The extended commit is not guaranteed to contain the same +2/3 precommits data
 // as the commit in the block.
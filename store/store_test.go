@@ -665,6 +665,18 @@ func TestLoadBlockMeta(t *testing.T) {
 	}
 }
 
+func TestLoadBlockCommitBytes(t *testing.T) {
+	bs, db := newInMemoryBlockStore()
+	height := int64(10)
+	require.Nil(t, bs.LoadBlockCommitBytes(height))
+
+	commit := &types.Commit{Height: height, Round: 1}
+	bz := mustEncode(commit.ToProto())
+	err := db.Set(calcBlockCommitKey(height), bz)
+	require.NoError(t, err)
+	require.Equal(t, bz, bs.LoadBlockCommitBytes(height))
+}
+
 func TestLoadBlockMetaByHash(t *testing.T) {
 	config := test.ResetTestRoot("blockchain_reactor_test")
 	defer os.RemoveAll(config.RootDir)